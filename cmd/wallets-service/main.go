@@ -9,6 +9,7 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/AlexZav1327/service/internal/jwks"
 	"github.com/AlexZav1327/service/internal/messages"
 	"github.com/AlexZav1327/service/internal/notifications"
 	"github.com/AlexZav1327/service/internal/postgres"
@@ -16,7 +17,9 @@ import (
 	walletserver "github.com/AlexZav1327/service/internal/wallet-server"
 	walletservice "github.com/AlexZav1327/service/internal/wallet-service"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/mitchellh/mapstructure"
 	migrate "github.com/rubenv/sql-migrate"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -45,10 +48,32 @@ func main() {
 		pgDSN           = viper.GetString("database.dsn")
 		host            = viper.GetString("server.host")
 		port            = viper.GetInt("server.port")
+		keysRetention   = viper.GetDuration("idempotency.retention")
+		spreadBps       = viper.GetInt64("exchange.spreadBps")
+		quoteTTL        = viper.GetDuration("exchange.quoteTTL")
+		feeWalletID     = viper.GetString("fees.wallet")
+		holdTTL         = viper.GetDuration("holds.ttl")
+		restoreGrace    = viper.GetDuration("wallets.restoreGrace")
+		issuer          = viper.GetString("auth.issuer")
+		audience        = viper.GetString("auth.audience")
+		serviceIssuer   = viper.GetString("auth.serviceIssuer")
+		staticFallback  = viper.GetBool("auth.staticKeyFallback")
+		jwksURL         = viper.GetString("auth.jwksURL")
+		jwksFile        = viper.GetString("auth.jwksFile")
+		jwksRefresh     = viper.GetDuration("auth.jwksRefresh")
+		accessTTL       = viper.GetDuration("auth.accessTTL")
+		refreshTTL      = viper.GetDuration("auth.refreshTTL")
 		signingKey      = getEnv("PRIVATE_SIGNING_KEY", embedSigningKey)
 		verificationKey = getEnv("PUBLIC_VERIFICATION_KEY", embedVerificationKey)
 	)
 
+	var feeRules []walletservice.FeeRule
+
+	err := viper.UnmarshalKey("fees.rules", &feeRules, viper.DecodeHook(mapstructure.TextUnmarshallerHookFunc()))
+	if err != nil {
+		logrus.Panicf("viper.UnmarshalKey: %s", err)
+	}
+
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 	defer cancel()
 
@@ -66,14 +91,50 @@ func main() {
 	exchangeRates := rates.New(logger)
 	message := messages.New(logger)
 	notification := notifications.New(logger)
-	walletsService := walletservice.New(pg, exchangeRates, message, notification, logger)
+	walletsService := walletservice.New(pg, exchangeRates, message, notification, logger, walletservice.Config{
+		SpreadBps:    spreadBps,
+		FeeWalletID:  mustParseUUID(feeWalletID),
+		FeeRules:     feeRules,
+		HoldTTL:      holdTTL,
+		RestoreGrace: restoreGrace,
+		QuoteTTL:     quoteTTL,
+		RefreshTTL:   refreshTTL,
+	})
+	keys := jwks.New(logger, jwks.Config{
+		Issuer:         issuer,
+		URL:            jwksURL,
+		File:           jwksFile,
+		Refresh:        jwksRefresh,
+		StaticKey:      mustGetPublicKey(verificationKey),
+		StaticFallback: staticFallback,
+	})
+
+	if issuer != "" && issuer == serviceIssuer {
+		logger.Panic("auth.serviceIssuer must differ from auth.issuer")
+	}
+
+	if keys.Configured() {
+		if signingKey == embedSigningKey {
+			logger.Panic("PRIVATE_SIGNING_KEY must be set when a JWKS source is configured")
+		}
+
+		if staticFallback && verificationKey == embedVerificationKey {
+			logger.Panic("PUBLIC_VERIFICATION_KEY must be set when auth.staticKeyFallback is enabled")
+		}
+	}
+
+	if err = keys.Load(ctx); err != nil {
+		logger.Warningf("keys.Load: %s", err)
+	}
+
 	server := walletserver.New(
 		host,
 		port,
 		walletsService,
 		logger,
 		mustGetPrivateKey(signingKey),
-		mustGetPublicKey(verificationKey),
+		keys,
+		walletserver.Config{Issuer: issuer, ServiceIssuer: serviceIssuer, Audience: audience, AccessTTL: accessTTL},
 	)
 
 	eg, ctx := errgroup.WithContext(ctx)
@@ -85,6 +146,26 @@ func main() {
 		return walletsService.TrackerRun(ctx)
 	})
 
+	eg.Go(func() error {
+		return keys.RefreshRun(ctx)
+	})
+
+	eg.Go(func() error {
+		return walletsService.StandingOrdersRun(ctx)
+	})
+
+	eg.Go(func() error {
+		return walletsService.IdempotencyCleanupRun(ctx, keysRetention)
+	})
+
+	eg.Go(func() error {
+		return walletsService.HoldsExpiryRun(ctx)
+	})
+
+	eg.Go(func() error {
+		return walletsService.PurgeRun(ctx)
+	})
+
 	if err = eg.Wait(); err != nil {
 		logrus.Panicf("eg.Wait: %s", err)
 	}
@@ -99,6 +180,19 @@ func getEnv(env, defaultValue string) string {
 	return value
 }
 
+func mustParseUUID(id string) uuid.UUID {
+	if id == "" {
+		return uuid.Nil
+	}
+
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		logrus.Panicf("uuid.Parse: %s", err)
+	}
+
+	return parsed
+}
+
 func mustGetPrivateKey(key string) *rsa.PrivateKey {
 	if len(key) == 0 {
 		logrus.Panic("File public.pem is missing or invalid")
@@ -5,17 +5,36 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/AlexZav1327/service/internal/postgres"
 	xrserver "github.com/AlexZav1327/service/internal/xr-server"
 	xrservice "github.com/AlexZav1327/service/internal/xr-service"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 )
 
 func main() {
+	viper.SetConfigName("config")
+	viper.AddConfigPath("./config")
+
+	if err := viper.BindEnv("database.dsn", "PG_DSN"); err != nil {
+		logrus.Warningf("viper.BindEnv: %s", err)
+	}
+
+	if err := viper.ReadInConfig(); err != nil {
+		logrus.Panicf("viper.ReadInConfig: %s", err)
+	}
+
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 	defer cancel()
 
 	logger := logrus.StandardLogger()
-	rateService := xrservice.New(logger)
+
+	pg, err := postgres.ConnectDB(ctx, logger, viper.GetString("database.dsn"))
+	if err != nil {
+		logger.Panicf("postgres.ConnectDB: %s", err)
+	}
+
+	rateService := xrservice.New(pg, logger)
 	server := xrserver.New("", 8091, rateService, logger)
 
 	if err := server.Run(ctx); err != nil {
@@ -0,0 +1,301 @@
+package jwks
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultRefresh     = time.Hour
+	minRefreshInterval = 30 * time.Second
+	discoveryPath      = "/.well-known/openid-configuration"
+)
+
+var (
+	ErrKeyNotFound      = errors.New("signing key not found")
+	ErrKeysUnavailable  = errors.New("signing keys are unavailable")
+	errUnexpectedStatus = errors.New("unexpected response status")
+	errMissingJWKSURI   = errors.New("jwks_uri is missing from the OpenID configuration")
+	errInvalidKey       = errors.New("modulus or exponent is not valid")
+)
+
+// Config selects where the keys come from. URL and File take a JWKS document; when both are empty
+// and Issuer is set, the document is found through the issuer's OpenID configuration. StaticKey
+// verifies every token when no JWKS source is configured. With a source, tokens must carry a kid,
+// and StaticKey verifies tokens without one only when StaticFallback is set.
+type Config struct {
+	Issuer         string
+	URL            string
+	File           string
+	Refresh        time.Duration
+	StaticKey      *rsa.PublicKey
+	StaticFallback bool
+}
+
+type KeySet struct {
+	log         *logrus.Entry
+	client      *http.Client
+	cfg         Config
+	mu          sync.RWMutex
+	keys        map[string]*rsa.PublicKey
+	jwksURL     string
+	refreshedAt time.Time
+}
+
+type document struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func New(log *logrus.Logger, cfg Config) *KeySet {
+	if cfg.Refresh <= 0 {
+		cfg.Refresh = defaultRefresh
+	}
+
+	return &KeySet{
+		log:     log.WithField("module", "jwks"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+		cfg:     cfg,
+		keys:    map[string]*rsa.PublicKey{},
+		jwksURL: cfg.URL,
+	}
+}
+
+// Configured reports whether keys come from a JWKS source rather than the static key alone.
+func (k *KeySet) Configured() bool {
+	return k.cfg.URL != "" || k.cfg.File != "" || k.cfg.Issuer != ""
+}
+
+// Key returns the public key a token with the given kid was signed with. A kid missing from the
+// cache triggers a refresh, at most once per minRefreshInterval, so rotated keys are picked up
+// before the next scheduled refresh.
+func (k *KeySet) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	if !k.Configured() || (kid == "" && k.cfg.StaticFallback) {
+		if k.cfg.StaticKey == nil {
+			return nil, ErrKeyNotFound
+		}
+
+		return k.cfg.StaticKey, nil
+	}
+
+	if kid == "" {
+		return nil, ErrKeyNotFound
+	}
+
+	key, refreshedAt, ok := k.cached(kid)
+	if ok {
+		return key, nil
+	}
+
+	if time.Since(refreshedAt) < minRefreshInterval {
+		return nil, ErrKeyNotFound
+	}
+
+	err := k.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Load: %w", err)
+	}
+
+	key, _, ok = k.cached(kid)
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	return key, nil
+}
+
+// cached looks kid up in the last loaded document.
+func (k *KeySet) cached(kid string) (*rsa.PublicKey, time.Time, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok := k.keys[kid]
+
+	return key, k.refreshedAt, ok
+}
+
+// Load replaces the cached keys with the current JWKS document. The cached keys are kept when the
+// document cannot be read.
+func (k *KeySet) Load(ctx context.Context) error {
+	if !k.Configured() {
+		return nil
+	}
+
+	k.mu.Lock()
+	k.refreshedAt = time.Now()
+	k.mu.Unlock()
+
+	data, err := k.read(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrKeysUnavailable, err)
+	}
+
+	var doc document
+
+	err = json.Unmarshal(data, &doc)
+	if err != nil {
+		return fmt.Errorf("%w: json.Unmarshal: %w", ErrKeysUnavailable, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(doc.Keys))
+
+	for _, jwk := range doc.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			k.log.Warningf("skipping key %q: %s", jwk.Kid, err)
+
+			continue
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.mu.Unlock()
+
+	k.log.Infof("loaded %d signing keys", len(keys))
+
+	return nil
+}
+
+func (k *KeySet) read(ctx context.Context) ([]byte, error) {
+	if k.cfg.File != "" {
+		data, err := os.ReadFile(k.cfg.File)
+		if err != nil {
+			return nil, fmt.Errorf("os.ReadFile: %w", err)
+		}
+
+		return data, nil
+	}
+
+	k.mu.RLock()
+	jwksURL := k.jwksURL
+	k.mu.RUnlock()
+
+	if jwksURL == "" {
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+
+		err := k.fetch(ctx, strings.TrimSuffix(k.cfg.Issuer, "/")+discoveryPath, &discovery)
+		if err != nil {
+			return nil, fmt.Errorf("fetch: %w", err)
+		}
+
+		if discovery.JWKSURI == "" {
+			return nil, errMissingJWKSURI
+		}
+
+		jwksURL = discovery.JWKSURI
+
+		k.mu.Lock()
+		k.jwksURL = jwksURL
+		k.mu.Unlock()
+	}
+
+	var data json.RawMessage
+
+	err := k.fetch(ctx, jwksURL, &data)
+	if err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+
+	return data, nil
+}
+
+func (k *KeySet) fetch(ctx context.Context, endpoint string, dest interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+
+	response, err := k.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("client.Do: %w", err)
+	}
+
+	defer func() {
+		err = response.Body.Close()
+		if err != nil {
+			k.log.Warningf("resp.Body.Close: %s", err)
+		}
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %d from %s", errUnexpectedStatus, response.StatusCode, endpoint)
+	}
+
+	err = json.NewDecoder(response.Body).Decode(dest)
+	if err != nil {
+		return fmt.Errorf("json.NewDecoder.Decode: %w", err)
+	}
+
+	return nil
+}
+
+// RefreshRun reloads the keys every Refresh interval. Failures are logged and the cached keys stay in
+// use until a later refresh succeeds.
+func (k *KeySet) RefreshRun(ctx context.Context) error {
+	if !k.Configured() {
+		return nil
+	}
+
+	refreshTicker := time.NewTicker(k.cfg.Refresh)
+	defer refreshTicker.Stop()
+
+	for {
+		select {
+		case <-refreshTicker.C:
+			err := k.Load(ctx)
+			if err != nil {
+				k.log.Warningf("Load: %s", err)
+			}
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (jwk jsonWebKey) publicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("base64.DecodeString: %w", err)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("base64.DecodeString: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errInvalidKey
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
@@ -32,3 +32,37 @@ func (n *Message) CreateMessage(wallet models.ResponseWalletInstance) ([]byte, e
 
 	return bytes, nil
 }
+
+func (n *Message) CreateOverdraftMessage(wallet models.ResponseWalletInstance) ([]byte, error) {
+	message := models.MessageTemplate{
+		Receiver: wallet.Email,
+		Message: fmt.Sprintf("The balance of wallet %s is negative: %s %s. Available credit: %s %s.",
+			wallet.WalletID, wallet.Balance, wallet.Currency, wallet.AvailableCredit, wallet.Currency),
+		Attachments: nil,
+	}
+
+	bytes, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	return bytes, nil
+}
+
+func (n *Message) CreateStandingOrderMessage(wallet models.ResponseWalletInstance, order models.StandingOrder,
+	execution models.StandingOrderExecution,
+) ([]byte, error) {
+	message := models.MessageTemplate{
+		Receiver: wallet.Email,
+		Message: fmt.Sprintf("Standing order %s could not transfer %s %s from wallet %s to wallet %s: %s.",
+			order.OrderID, order.Amount, order.Currency, order.SrcWalletID, order.DstWalletID, execution.Error),
+		Attachments: nil,
+	}
+
+	bytes, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	return bytes, nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ScopeRead     = "read"
+	ScopeDeposit  = "deposit"
+	ScopeTransfer = "transfer"
+)
+
+type RequestAPIKey struct {
+	Name      string      `json:"name"`
+	Scopes    []string    `json:"scopes"`
+	WalletIDs []uuid.UUID `json:"walletIds"`
+	ExpiresAt *time.Time  `json:"expiresAt"`
+}
+
+// APIKey lets a service client call the wallet API without a JWT. Its scopes limit the operations it
+// may use and, when WalletIDs is not empty, the wallets it may use them on. Only a hash of the key is
+// stored.
+type APIKey struct {
+	KeyID      uuid.UUID   `json:"keyId"`
+	Name       string      `json:"name"`
+	Scopes     []string    `json:"scopes"`
+	WalletIDs  []uuid.UUID `json:"walletIds"`
+	ExpiresAt  *time.Time  `json:"expiresAt"`
+	LastUsedAt *time.Time  `json:"lastUsedAt"`
+	RevokedAt  *time.Time  `json:"revokedAt"`
+	CreatedBy  string      `json:"createdBy"`
+	Created    time.Time   `json:"created"`
+}
+
+// ResponseAPIKey is returned once, when the key is created; Key cannot be read back later.
+type ResponseAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
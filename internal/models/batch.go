@@ -0,0 +1,63 @@
+package models
+
+import (
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+const (
+	BatchCompleted = "COMPLETED"
+	BatchPartial   = "PARTIAL"
+	BatchFailed    = "FAILED"
+
+	BatchItemSucceeded = "SUCCEEDED"
+	BatchItemFailed    = "FAILED"
+	BatchItemSkipped   = "SKIPPED"
+)
+
+// RequestBatchTransfer pays every item from one wallet. The items are booked as separate transfers that
+// share TransactionKey. A best-effort batch books what it can; otherwise it is all or nothing.
+type RequestBatchTransfer struct {
+	TransactionKey uuid.UUID           `json:"transactionKey"`
+	FromCurrency   string              `json:"fromCurrency,omitempty"`
+	BestEffort     bool                `json:"bestEffort"`
+	Items          []BatchTransferItem `json:"items"`
+}
+
+type BatchTransferItem struct {
+	DstWalletID uuid.UUID    `json:"dstWalletId"`
+	Amount      money.Amount `json:"amount"`
+	Currency    string       `json:"currency"`
+}
+
+// BatchTransferResult reports one item. Items of a failed all-or-nothing batch that were not at fault
+// are SKIPPED.
+type BatchTransferResult struct {
+	DstWalletID    uuid.UUID     `json:"dstWalletId"`
+	Amount         money.Amount  `json:"amount"`
+	Currency       string        `json:"currency"`
+	Status         string        `json:"status"`
+	TransactionID  uuid.NullUUID `json:"transactionId"`
+	DebitedAmount  money.Amount  `json:"debitedAmount"`
+	CreditedAmount money.Amount  `json:"creditedAmount"`
+	Fee            *Fee          `json:"fee,omitempty"`
+	Error          string        `json:"error,omitempty"`
+}
+
+// BatchTransferEntry pairs an item with the transaction that pays it. Txn is nil for an item rejected
+// before posting.
+type BatchTransferEntry struct {
+	Result BatchTransferResult
+	Txn    *LedgerTransaction
+}
+
+// ResponseBatchTransfer reports the batch. Total is what left the source wallet, fees included, in
+// Currency.
+type ResponseBatchTransfer struct {
+	TransactionKey uuid.UUID              `json:"transactionKey"`
+	Status         string                 `json:"status"`
+	Total          money.Amount           `json:"total"`
+	Currency       string                 `json:"currency"`
+	SrcWallet      ResponseWalletInstance `json:"srcWallet"`
+	Items          []BatchTransferResult  `json:"items"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/AlexZav1327/service/internal/money"
+)
+
+// Currency is an entry of the currency registry. ReferenceRate is the number of currency units
+// per one US dollar; the exchange rate stub derives its cross rates from it.
+type Currency struct {
+	Code          string     `json:"code"`
+	MinorUnits    int        `json:"minorUnits"`
+	Enabled       bool       `json:"enabled"`
+	ReferenceRate money.Rate `json:"referenceRate"`
+	Created       time.Time  `json:"created"`
+	Updated       time.Time  `json:"updated"`
+}
+
+type RequestCurrency struct {
+	Code          string     `json:"code"`
+	MinorUnits    *int       `json:"minorUnits"`
+	Enabled       *bool      `json:"enabled"`
+	ReferenceRate money.Rate `json:"referenceRate"`
+}
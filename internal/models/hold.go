@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+const (
+	HoldAuthorized = "AUTHORIZED"
+	HoldCaptured   = "CAPTURED"
+	HoldVoided     = "VOIDED"
+	HoldExpired    = "EXPIRED"
+)
+
+// Hold reserves HeldAmount of the wallet's balance until it is captured, voided or expires. Amount and
+// Currency are as authorized; HeldAmount is in the wallet's currency, converted at AppliedRate, and
+// includes the fee the capture will be charged.
+type Hold struct {
+	HoldID         uuid.UUID     `json:"holdId"`
+	WalletID       uuid.UUID     `json:"walletId"`
+	Amount         money.Amount  `json:"amount"`
+	Currency       string        `json:"currency"`
+	HeldAmount     money.Amount  `json:"heldAmount"`
+	HeldCurrency   string        `json:"heldCurrency"`
+	AppliedRate    *AppliedRate  `json:"appliedRate,omitempty"`
+	CapturedAmount money.Amount  `json:"capturedAmount"`
+	TransactionID  uuid.NullUUID `json:"transactionId"`
+	TransactionKey uuid.NullUUID `json:"transactionKey"`
+	Status         string        `json:"status"`
+	ExpiresAt      time.Time     `json:"expiresAt"`
+	Created        time.Time     `json:"created"`
+	Updated        time.Time     `json:"updated"`
+}
+
+type RequestAuthorize struct {
+	TransactionKey uuid.UUID    `json:"transactionKey"`
+	Currency       string       `json:"currency"`
+	Amount         money.Amount `json:"amount"`
+	TTLSeconds     int64        `json:"ttlSeconds"`
+}
+
+type RequestCapture struct {
+	TransactionKey uuid.UUID    `json:"transactionKey"`
+	Amount         money.Amount `json:"amount"`
+}
+
+type ResponseHold struct {
+	Hold
+	Wallet ResponseWalletInstance `json:"wallet"`
+	Fee    *Fee                   `json:"fee,omitempty"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+const (
+	Debit  = "DEBIT"
+	Credit = "CREDIT"
+)
+
+type LedgerEntry struct {
+	Account   string
+	WalletID  uuid.NullUUID
+	Direction string
+	Amount    money.Amount
+	Currency  string
+}
+
+// LedgerTransaction is a transaction together with its ledger entries. QuoteID is the quote whose rate
+// it was booked at, if any. Spending, when set, is checked against the source leg as it is booked.
+// ReversedBefore is, for a reversal, how much of the original had been reversed when its entries were built.
+type LedgerTransaction struct {
+	Transaction
+	Entries        []LedgerEntry
+	QuoteID        uuid.NullUUID
+	Spending       *SpendingCheck
+	ReversedBefore money.Amount
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+const (
+	PeriodDaily   = "DAILY"
+	PeriodWeekly  = "WEEKLY"
+	PeriodMonthly = "MONTHLY"
+)
+
+// SpendingLimits caps the amount debited by withdrawals, outgoing transfers and captured holds within a
+// rolling window, in the wallet's currency. A nil limit is not enforced, or is inherited from the
+// currency default when set on a wallet.
+type SpendingLimits struct {
+	Daily   *money.Amount `json:"daily"`
+	Weekly  *money.Amount `json:"weekly"`
+	Monthly *money.Amount `json:"monthly"`
+}
+
+type RemainingLimit struct {
+	Period    string        `json:"period"`
+	Limit     *money.Amount `json:"limit"`
+	Spent     money.Amount  `json:"spent"`
+	Remaining *money.Amount `json:"remaining"`
+}
+
+type ResponseRemainingLimits struct {
+	WalletID uuid.UUID        `json:"walletId"`
+	Currency string           `json:"currency"`
+	Limits   []RemainingLimit `json:"limits"`
+}
+
+// SpendingCheck asks for a debit to be checked against the spending limits of its source wallet once
+// the wallet is locked. Spending is what Operations debited from the wallet, in the currency of the
+// debit, since the start of each cap's window.
+type SpendingCheck struct {
+	Operations []string
+	Caps       []SpendingCap
+}
+
+type SpendingCap struct {
+	Period string
+	Limit  money.Amount
+	Since  time.Time
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+// RequestQuote asks for the rate at which Amount in Currency converts into TargetCurrency. Side is BID
+// for funds coming into a wallet in TargetCurrency and ASK for funds leaving it.
+type RequestQuote struct {
+	Currency       string       `json:"currency"`
+	Amount         money.Amount `json:"amount"`
+	TargetCurrency string       `json:"targetCurrency"`
+	Side           string       `json:"side"`
+}
+
+// Quote locks a rate until ExpiresAt. It can be used by one operation on exactly its amount and
+// currencies; TransactionID is set once it has been.
+type Quote struct {
+	QuoteID         uuid.UUID     `json:"quoteId"`
+	Currency        string        `json:"currency"`
+	Amount          money.Amount  `json:"amount"`
+	TargetCurrency  string        `json:"targetCurrency"`
+	ConvertedAmount money.Amount  `json:"convertedAmount"`
+	TransactionID   uuid.NullUUID `json:"transactionId"`
+	ExpiresAt       time.Time     `json:"expiresAt"`
+	Created         time.Time     `json:"created"`
+	AppliedRate
+}
@@ -1,10 +1,14 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/AlexZav1327/service/internal/money"
+)
 
 type ExchangeRate struct {
-	Timestamp  time.Time `json:"timestamp"`
-	Currencies string    `json:"currencies"`
-	Bid        float32   `json:"bid"`
-	Ask        float32   `json:"ask"`
+	Timestamp  time.Time  `json:"timestamp"`
+	Currencies string     `json:"currencies"`
+	Bid        money.Rate `json:"bid"`
+	Ask        money.Rate `json:"ask"`
 }
@@ -0,0 +1,28 @@
+package models
+
+const (
+	RoleUser    = "user"
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+	RoleAuditor = "auditor"
+)
+
+// Permissions granted by roles. PermissionSelfService lets a caller create and use its own wallets;
+// the others apply to every wallet in the system.
+const (
+	PermissionSelfService      = "wallets:self"
+	PermissionReadWallets      = "wallets:read"
+	PermissionOperateWallets   = "wallets:operate"
+	PermissionManageStatus     = "wallets:status"
+	PermissionAdjustBalances   = "wallets:adjust"
+	PermissionManageCurrencies = "currencies:manage"
+	PermissionManageAPIKeys    = "apikeys:manage"
+)
+
+// Permissions granted by API key scopes. They are only accepted on routes tied to a wallet, and apply
+// to every wallet or to the wallets the key is restricted to.
+const (
+	PermissionKeyRead     = "apikey:read"
+	PermissionKeyDeposit  = "apikey:deposit"
+	PermissionKeyTransfer = "apikey:transfer"
+)
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is a login that hands out access tokens. Each refresh rotates its refresh token and replaces
+// AccessTokenID, the only access token of the session that is still accepted.
+type Session struct {
+	SessionID       uuid.UUID
+	Subject         string
+	Email           string
+	Roles           []string
+	Permissions     []string
+	AccessTokenID   uuid.UUID
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time
+	RevokedAt       *time.Time
+	Created         time.Time
+}
+
+type AccessToken struct {
+	TokenID   uuid.UUID
+	ExpiresAt time.Time
+}
+
+type RequestRefresh struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type ResponseTokens struct {
+	AccessToken      string    `json:"accessToken"`
+	RefreshToken     string    `json:"refreshToken"`
+	TokenType        string    `json:"tokenType"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+}
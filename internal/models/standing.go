@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+const (
+	FrequencyOnce    = "ONCE"
+	FrequencyWeekly  = "WEEKLY"
+	FrequencyMonthly = "MONTHLY"
+
+	StandingOrderActive    = "ACTIVE"
+	StandingOrderCompleted = "COMPLETED"
+	StandingOrderCancelled = "CANCELLED"
+
+	ExecutionSucceeded = "SUCCEEDED"
+	ExecutionFailed    = "FAILED"
+)
+
+// StandingOrder transfers Amount from the source to the destination wallet on StartAt and then every
+// week or month, unless its frequency is ONCE. Runs counts the executions so far, failed ones included.
+type StandingOrder struct {
+	OrderID     uuid.UUID    `json:"orderId"`
+	SrcWalletID uuid.UUID    `json:"srcWalletId"`
+	DstWalletID uuid.UUID    `json:"dstWalletId"`
+	Amount      money.Amount `json:"amount"`
+	Currency    string       `json:"currency"`
+	Frequency   string       `json:"frequency"`
+	StartAt     time.Time    `json:"startAt"`
+	NextRunAt   time.Time    `json:"nextRunAt"`
+	Runs        int          `json:"runs"`
+	Status      string       `json:"status"`
+	Created     time.Time    `json:"created"`
+	Updated     time.Time    `json:"updated"`
+}
+
+type RequestStandingOrder struct {
+	DstWalletID uuid.UUID    `json:"dstWalletId"`
+	Amount      money.Amount `json:"amount"`
+	Currency    string       `json:"currency"`
+	Frequency   string       `json:"frequency"`
+	StartAt     time.Time    `json:"startAt"`
+}
+
+// StandingOrderExecution is the result of one scheduled run. TransactionKey is derived from the order
+// and ScheduledAt, so a run retried after a crash is not executed twice.
+type StandingOrderExecution struct {
+	OrderID        uuid.UUID     `json:"orderId"`
+	ScheduledAt    time.Time     `json:"scheduledAt"`
+	TransactionKey uuid.UUID     `json:"transactionKey"`
+	TransactionID  uuid.NullUUID `json:"transactionId"`
+	Status         string        `json:"status"`
+	Error          string        `json:"error,omitempty"`
+	Created        time.Time     `json:"created"`
+}
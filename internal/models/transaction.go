@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+const (
+	TransactionCompleted         = "COMPLETED"
+	TransactionPartiallyReversed = "PARTIALLY_REVERSED"
+	TransactionReversed          = "REVERSED"
+	SideBid                      = "BID"
+	SideAsk                      = "ASK"
+)
+
+// Transaction is a booked operation. A reversal points to the transaction it undoes with ReversalOf,
+// and ReversedAmount is how much of a transaction its reversals have undone so far. Reason is set on
+// manual adjustments.
+type Transaction struct {
+	TransactionID  uuid.UUID       `json:"transactionId"`
+	OperationType  string          `json:"type"`
+	Amount         money.Amount    `json:"amount"`
+	Currency       string          `json:"currency"`
+	Source         *TransactionLeg `json:"source,omitempty"`
+	Destination    *TransactionLeg `json:"destination,omitempty"`
+	Fee            *Fee            `json:"fee,omitempty"`
+	TransactionKey uuid.NullUUID   `json:"transactionKey"`
+	Status         string          `json:"status"`
+	ReversalOf     uuid.NullUUID   `json:"reversalOf"`
+	ReversedAmount money.Amount    `json:"reversedAmount"`
+	Reason         *string         `json:"reason,omitempty"`
+	Created        time.Time       `json:"created"`
+}
+
+// TransactionLeg is the side of a transaction booked on a wallet, in the wallet's currency.
+// AppliedRate is set only when the amount was converted from the transaction currency.
+type TransactionLeg struct {
+	WalletID uuid.UUID    `json:"walletId"`
+	Amount   money.Amount `json:"amount"`
+	Currency string       `json:"currency"`
+	*AppliedRate
+}
+
+// AppliedRate is the rate a conversion was made at: the provider side taken and the house spread,
+// in basis points, already included in Rate.
+type AppliedRate struct {
+	Rate      money.Rate `json:"rate"`
+	Side      string     `json:"side"`
+	SpreadBps int64      `json:"spreadBps"`
+}
+
+// Fee is charged to the paying wallet in its own currency and credited to the fee wallet.
+type Fee struct {
+	Amount      money.Amount `json:"amount"`
+	Currency    string       `json:"currency"`
+	FeeWalletID uuid.UUID    `json:"-"`
+}
+
+type RequestTransactionsList struct {
+	OperationType string
+	Currency      string
+	PeriodStart   time.Time
+	PeriodEnd     time.Time
+	ListingQueryParams
+}
+
+type ResponseFundsOperation struct {
+	ResponseWalletInstance
+	TransactionID uuid.UUID `json:"transactionId"`
+	Fee           *Fee      `json:"fee,omitempty"`
+}
+
+type ResponseTransfer struct {
+	TransactionID  uuid.UUID              `json:"transactionId"`
+	Amount         money.Amount           `json:"amount"`
+	Currency       string                 `json:"currency"`
+	SrcWallet      ResponseWalletInstance `json:"srcWallet"`
+	DstWallet      ResponseWalletInstance `json:"dstWallet"`
+	DebitedAmount  money.Amount           `json:"debitedAmount"`
+	CreditedAmount money.Amount           `json:"creditedAmount"`
+	SrcRate        *AppliedRate           `json:"srcRate,omitempty"`
+	DstRate        *AppliedRate           `json:"dstRate,omitempty"`
+	Fee            *Fee                   `json:"fee,omitempty"`
+}
+
+// RequestAdjustment corrects a wallet balance by hand: a CREDIT adds Amount to the sub-balance in
+// Currency and a DEBIT takes it away.
+type RequestAdjustment struct {
+	TransactionKey uuid.UUID    `json:"transactionKey"`
+	Direction      string       `json:"direction"`
+	Amount         money.Amount `json:"amount"`
+	Currency       string       `json:"currency"`
+	Reason         string       `json:"reason"`
+}
+
+// RequestReversal undoes Amount of a transaction, or all that is left of it when Amount is zero.
+type RequestReversal struct {
+	TransactionKey uuid.UUID    `json:"transactionKey"`
+	Amount         money.Amount `json:"amount"`
+}
+
+type ResponseReversal struct {
+	Reversal Transaction `json:"reversal"`
+	Original Transaction `json:"original"`
+}
@@ -3,32 +3,60 @@ package models
 import (
 	"time"
 
+	"github.com/AlexZav1327/service/internal/money"
 	"github.com/google/uuid"
 )
 
+const (
+	WalletActive = "ACTIVE"
+	WalletFrozen = "FROZEN"
+	WalletClosed = "CLOSED"
+)
+
 type RequestWalletInstance struct {
-	TransactionKey uuid.UUID `json:"transactionKey"`
-	WalletID       uuid.UUID `json:"walletId"`
-	Email          string    `json:"email"`
-	Owner          string    `json:"owner"`
-	Currency       string    `json:"currency"`
-	Balance        float32   `json:"balance"`
+	TransactionKey uuid.UUID    `json:"transactionKey"`
+	WalletID       uuid.UUID    `json:"walletId"`
+	Email          string       `json:"email"`
+	Owner          string       `json:"owner"`
+	Currency       string       `json:"currency"`
+	Balance        money.Amount `json:"balance"`
+	OwnerID        string       `json:"-"`
 }
 
 type ResponseWalletInstance struct {
-	WalletID uuid.UUID `json:"walletId"`
-	Email    string    `json:"email"`
-	Owner    string    `json:"owner"`
-	Currency string    `json:"currency"`
-	Balance  float32   `json:"balance"`
-	Created  time.Time `json:"created"`
-	Updated  time.Time `json:"updated"`
+	WalletID         uuid.UUID    `json:"walletId"`
+	Email            string       `json:"email"`
+	Owner            string       `json:"owner"`
+	Currency         string       `json:"currency"`
+	Status           string       `json:"status"`
+	Balance          money.Amount `json:"balance"`
+	AvailableBalance money.Amount `json:"availableBalance"`
+	CreditLimit      money.Amount `json:"creditLimit"`
+	UsedCredit       money.Amount `json:"usedCredit"`
+	AvailableCredit  money.Amount `json:"availableCredit"`
+	SubBalances      []SubBalance `json:"subBalances"`
+	Created          time.Time    `json:"created"`
+	Updated          time.Time    `json:"updated"`
+}
+
+type SubBalance struct {
+	Currency string       `json:"currency"`
+	Balance  money.Amount `json:"balance"`
 }
 
 type FundsOperations struct {
-	TransactionKey uuid.UUID `json:"transactionKey"`
-	Currency       string    `json:"currency"`
-	Amount         float32   `json:"amount"`
+	TransactionKey uuid.UUID     `json:"transactionKey"`
+	Currency       string        `json:"currency"`
+	Amount         money.Amount  `json:"amount"`
+	FromCurrency   string        `json:"fromCurrency,omitempty"`
+	QuoteID        uuid.NullUUID `json:"quoteId"`
+}
+
+type RequestExchange struct {
+	TransactionKey uuid.UUID    `json:"transactionKey"`
+	FromCurrency   string       `json:"fromCurrency"`
+	ToCurrency     string       `json:"toCurrency"`
+	Amount         money.Amount `json:"amount"`
 }
 
 type RequestWalletHistory struct {
@@ -38,6 +66,9 @@ type RequestWalletHistory struct {
 }
 
 type ListingQueryParams struct {
+	OwnerID      string
+	OwnerEmail   string
+	Status       string
 	TextFilter   string
 	ItemsPerPage int
 	Offset       int
@@ -46,16 +77,44 @@ type ListingQueryParams struct {
 }
 
 type ResponseWalletHistory struct {
-	WalletID  uuid.UUID `json:"walletId"`
-	Email     string    `json:"email"`
-	Owner     string    `json:"owner"`
-	Currency  string    `json:"currency"`
-	Balance   float32   `json:"balance"`
-	Created   time.Time `json:"created"`
-	Operation string    `json:"operation"`
+	WalletID  uuid.UUID    `json:"walletId"`
+	Email     string       `json:"email"`
+	Owner     string       `json:"owner"`
+	Currency  string       `json:"currency"`
+	Balance   money.Amount `json:"balance"`
+	Created   time.Time    `json:"created"`
+	Operation string       `json:"operation"`
+	Status    string       `json:"status"`
+	Reason    *string      `json:"reason,omitempty"`
 }
 
 type SessionInfo struct {
-	UUID  string
-	Email string
+	UUID        string
+	Email       string
+	Roles       []string
+	Permissions []string
+	SessionID   string
+	TokenID     string
+	Issuer      string
+	ExpiresAt   time.Time
+	Wallets     []string
+}
+
+type IdempotencyKey struct {
+	Key         uuid.UUID
+	Fingerprint string
+}
+
+type RequestWalletStatus struct {
+	Status  string        `json:"status"`
+	Reason  string        `json:"reason"`
+	SweepTo uuid.NullUUID `json:"sweepTo"`
+}
+
+type RequestWalletRestore struct {
+	Reason string `json:"reason"`
+}
+
+type RequestCreditLimit struct {
+	CreditLimit money.Amount `json:"creditLimit"`
 }
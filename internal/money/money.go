@@ -0,0 +1,299 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+const (
+	Scale           = 3
+	maxUnits        = 9_999_999_999_999
+	percentPerWhole = 100
+)
+
+var (
+	ErrOverflow      = errors.New("amount is out of range")
+	ErrPrecision     = errors.New("amount has too many decimal places")
+	ErrInvalidAmount = errors.New("amount is not a valid decimal number")
+)
+
+var (
+	unitsPerMajor  = new(big.Int).Exp(big.NewInt(10), big.NewInt(Scale), nil)
+	decimalPattern = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?$`)
+)
+
+// Amount is an exact monetary value kept as an integer number of thousandths, enough for any
+// currency with up to three minor units. Its range matches the NUMERIC(13, 3) columns it is stored in.
+type Amount struct {
+	units int64
+}
+
+func FromUnits(units int64) (Amount, error) {
+	if units > maxUnits || units < -maxUnits {
+		return Amount{}, ErrOverflow
+	}
+
+	return Amount{units: units}, nil
+}
+
+// Parse reads a plain decimal number: an optional sign, digits and an optional fraction.
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if !decimalPattern.MatchString(s) {
+		return Amount{}, ErrInvalidAmount
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Amount{}, ErrInvalidAmount
+	}
+
+	units := r.Mul(r, new(big.Rat).SetInt(unitsPerMajor))
+	if !units.IsInt() {
+		return Amount{}, ErrPrecision
+	}
+
+	if !units.Num().IsInt64() {
+		return Amount{}, ErrOverflow
+	}
+
+	return FromUnits(units.Num().Int64())
+}
+
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(fmt.Sprintf("money.MustParse(%q): %s", s, err))
+	}
+
+	return a
+}
+
+func (a Amount) Units() int64 {
+	return a.units
+}
+
+func (a Amount) Sign() int {
+	switch {
+	case a.units > 0:
+		return 1
+	case a.units < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func (a Amount) IsZero() bool {
+	return a.units == 0
+}
+
+func (a Amount) Cmp(b Amount) int {
+	return Amount{units: a.units - b.units}.Sign()
+}
+
+func (a Amount) Neg() Amount {
+	return Amount{units: -a.units}
+}
+
+func (a Amount) Add(b Amount) (Amount, error) {
+	return FromUnits(a.units + b.units)
+}
+
+func (a Amount) Sub(b Amount) (Amount, error) {
+	return FromUnits(a.units - b.units)
+}
+
+// HasMinorUnits reports whether the amount can be expressed with the given number of decimal places.
+func (a Amount) HasMinorUnits(minorUnits int) bool {
+	return a.units%minorUnitStep(minorUnits) == 0
+}
+
+// Round rounds the amount half away from zero to the given number of decimal places.
+func (a Amount) Round(minorUnits int) (Amount, error) {
+	step := big.NewInt(minorUnitStep(minorUnits))
+	rounded := roundQuo(big.NewInt(a.units), step)
+
+	return FromUnits(rounded.Mul(rounded, step).Int64())
+}
+
+// Convert multiplies the amount by the rate, rounding half away from zero to the given number of decimal places.
+func (a Amount) Convert(rate Rate, minorUnits int) (Amount, error) {
+	product := new(big.Int).Mul(big.NewInt(a.units), big.NewInt(rate.units))
+	step := big.NewInt(minorUnitStep(minorUnits))
+
+	converted := roundQuo(product, new(big.Int).Mul(big.NewInt(rateUnitsPerWhole), step))
+	converted.Mul(converted, step)
+
+	if !converted.IsInt64() {
+		return Amount{}, ErrOverflow
+	}
+
+	return FromUnits(converted.Int64())
+}
+
+// Share returns part/whole of the amount, rounding half away from zero to the given number of decimal places.
+func (a Amount) Share(part, whole Amount, minorUnits int) (Amount, error) {
+	if whole.units == 0 {
+		return Amount{}, ErrInvalidAmount
+	}
+
+	product := new(big.Int).Mul(big.NewInt(a.units), big.NewInt(part.units))
+	step := big.NewInt(minorUnitStep(minorUnits))
+
+	share := roundQuo(product, new(big.Int).Mul(big.NewInt(whole.units), step))
+	share.Mul(share, step)
+
+	if !share.IsInt64() {
+		return Amount{}, ErrOverflow
+	}
+
+	return FromUnits(share.Int64())
+}
+
+// Percent returns the given percentage of the amount, rounding half away from zero to the given number
+// of decimal places.
+func (a Amount) Percent(percent Rate, minorUnits int) (Amount, error) {
+	product := new(big.Int).Mul(big.NewInt(a.units), big.NewInt(percent.units))
+	step := big.NewInt(minorUnitStep(minorUnits))
+	divisor := new(big.Int).Mul(big.NewInt(rateUnitsPerWhole*percentPerWhole), step)
+
+	share := roundQuo(product, divisor)
+	share.Mul(share, step)
+
+	if !share.IsInt64() {
+		return Amount{}, ErrOverflow
+	}
+
+	return FromUnits(share.Int64())
+}
+
+func (a Amount) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(big.NewInt(a.units), unitsPerMajor).Float64()
+
+	return f
+}
+
+// String formats the amount with only the decimal places it needs. Amounts are kept in the minor units of
+// their currency, so a currency without minor units is formatted as a whole number.
+func (a Amount) String() string {
+	return strings.TrimSuffix(strings.TrimRight(formatUnits(a.units, Scale), "0"), ".")
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding one. A null leaves the amount unchanged.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s, ok, err := jsonDecimal(data)
+	if !ok || err != nil {
+		return err
+	}
+
+	return a.UnmarshalText([]byte(s))
+}
+
+func (a *Amount) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return fmt.Errorf("%q: %w", text, err)
+	}
+
+	*a = parsed
+
+	return nil
+}
+
+func (a *Amount) Scan(src any) error {
+	var s string
+
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case int64:
+		s = fmt.Sprint(v)
+	case nil:
+		*a = Amount{}
+
+		return nil
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidAmount, src)
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		return fmt.Errorf("Parse: %w", err)
+	}
+
+	*a = parsed
+
+	return nil
+}
+
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+func minorUnitStep(minorUnits int) int64 {
+	step := int64(1)
+
+	for i := minorUnits; i < Scale; i++ {
+		step *= 10
+	}
+
+	return step
+}
+
+func roundQuo(x, y *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(x, y, new(big.Int))
+
+	if new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(y) >= 0 {
+		if x.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	return q
+}
+
+// jsonDecimal returns the text of a JSON number or string; ok is false for null.
+func jsonDecimal(data []byte) (string, bool, error) {
+	if string(data) == "null" {
+		return "", false, nil
+	}
+
+	if len(data) == 0 || data[0] != '"' {
+		return string(data), true, nil
+	}
+
+	var s string
+
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return "", false, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	return s, true, nil
+}
+
+func formatUnits(units int64, scale int) string {
+	sign := ""
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+
+	digits := fmt.Sprintf("%0*d", scale+1, units)
+
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
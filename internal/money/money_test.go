@@ -0,0 +1,267 @@
+package money_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		units int64
+		err   error
+	}{
+		{name: "whole", input: "100", units: 100_000},
+		{name: "cents", input: "12.34", units: 12_340},
+		{name: "thousandths", input: "0.005", units: 5},
+		{name: "negative", input: "-1.5", units: -1_500},
+		{name: "spaces", input: " 7 ", units: 7_000},
+		{name: "plus sign", input: "+2", units: 2_000},
+		{name: "maximum", input: "9999999999.999", units: 9_999_999_999_999},
+		{name: "too precise", input: "0.0001", err: money.ErrPrecision},
+		{name: "too large", input: "10000000000", err: money.ErrOverflow},
+		{name: "empty", input: "", err: money.ErrInvalidAmount},
+		{name: "fraction", input: "1/3", err: money.ErrInvalidAmount},
+		{name: "not a number", input: "ten", err: money.ErrInvalidAmount},
+		{name: "exponent", input: "1e2", err: money.ErrInvalidAmount},
+		{name: "hexadecimal", input: "0x10", err: money.ErrInvalidAmount},
+		{name: "binary", input: "0b11", err: money.ErrInvalidAmount},
+		{name: "octal", input: "0o17", err: money.ErrInvalidAmount},
+		{name: "underscores", input: "1_000", err: money.ErrInvalidAmount},
+		{name: "no integer part", input: ".5", err: money.ErrInvalidAmount},
+		{name: "no fraction digits", input: "5.", err: money.ErrInvalidAmount},
+		{name: "inner space", input: "1 000", err: money.ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, err := money.Parse(tt.input)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.units, amount.Units())
+		})
+	}
+}
+
+func TestAmountString(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "0", want: "0"},
+		{input: "100", want: "100"},
+		{input: "100.5", want: "100.5"},
+		{input: "12.34", want: "12.34"},
+		{input: "1.234", want: "1.234"},
+		{input: "0.05", want: "0.05"},
+		{input: "-0.5", want: "-0.5"},
+		{input: "-20", want: "-20"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			require.Equal(t, tt.want, money.MustParse(tt.input).String())
+		})
+	}
+}
+
+func TestAmountRound(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		minorUnits int
+		want       string
+		err        error
+	}{
+		{name: "half up", input: "1.005", minorUnits: 2, want: "1.01"},
+		{name: "below half", input: "1.004", minorUnits: 2, want: "1"},
+		{name: "negative half", input: "-1.005", minorUnits: 2, want: "-1.01"},
+		{name: "whole", input: "2.5", minorUnits: 0, want: "3"},
+		{name: "negative whole", input: "-2.5", minorUnits: 0, want: "-3"},
+		{name: "already exact", input: "1.234", minorUnits: 3, want: "1.234"},
+		{name: "overflow", input: "9999999999.999", minorUnits: 2, err: money.ErrOverflow},
+		{name: "negative overflow", input: "-9999999999.5", minorUnits: 0, err: money.ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rounded, err := money.MustParse(tt.input).Round(tt.minorUnits)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, money.MustParse(tt.want), rounded)
+		})
+	}
+}
+
+func TestAmountHasMinorUnits(t *testing.T) {
+	tests := []struct {
+		input      string
+		minorUnits int
+		want       bool
+	}{
+		{input: "100", minorUnits: 0, want: true},
+		{input: "100.5", minorUnits: 0, want: false},
+		{input: "100.5", minorUnits: 2, want: true},
+		{input: "0.001", minorUnits: 2, want: false},
+		{input: "0.001", minorUnits: 3, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			require.Equal(t, tt.want, money.MustParse(tt.input).HasMinorUnits(tt.minorUnits))
+		})
+	}
+}
+
+func TestAmountConvert(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     string
+		rate       string
+		minorUnits int
+		want       string
+		err        error
+	}{
+		{name: "exact", amount: "100", rate: "0.9", minorUnits: 2, want: "90"},
+		{name: "rounded half up", amount: "0.01", rate: "0.5", minorUnits: 2, want: "0.01"},
+		{name: "rounded down", amount: "1", rate: "1.23449999", minorUnits: 2, want: "1.23"},
+		{name: "to whole units", amount: "10", rate: "148.55", minorUnits: 0, want: "1486"},
+		{name: "negative", amount: "-0.01", rate: "0.5", minorUnits: 2, want: "-0.01"},
+		{name: "overflow", amount: "9999999999", rate: "2", minorUnits: 2, err: money.ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, err := money.MustParse(tt.amount).Convert(money.MustParseRate(tt.rate), tt.minorUnits)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, money.MustParse(tt.want), converted)
+		})
+	}
+}
+
+func TestAmountShare(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     string
+		part       string
+		whole      string
+		minorUnits int
+		want       string
+		err        error
+	}{
+		{name: "half", amount: "10", part: "50", whole: "100", minorUnits: 2, want: "5"},
+		{name: "third rounded", amount: "10", part: "1", whole: "3", minorUnits: 2, want: "3.33"},
+		{name: "two thirds rounded", amount: "10", part: "2", whole: "3", minorUnits: 2, want: "6.67"},
+		{name: "whole units", amount: "5", part: "1", whole: "2", minorUnits: 0, want: "3"},
+		{name: "zero whole", amount: "10", part: "1", whole: "0", minorUnits: 2, err: money.ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			share, err := money.MustParse(tt.amount).Share(money.MustParse(tt.part), money.MustParse(tt.whole),
+				tt.minorUnits)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, money.MustParse(tt.want), share)
+		})
+	}
+}
+
+func TestAmountPercent(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     string
+		percent    string
+		minorUnits int
+		want       string
+	}{
+		{name: "half percent", amount: "200", percent: "0.5", minorUnits: 2, want: "1"},
+		{name: "rounded half up", amount: "1", percent: "0.5", minorUnits: 2, want: "0.01"},
+		{name: "rounded down", amount: "0.99", percent: "0.5", minorUnits: 2, want: "0"},
+		{name: "whole units", amount: "150", percent: "1", minorUnits: 0, want: "2"},
+		{name: "three decimals", amount: "12.345", percent: "10", minorUnits: 3, want: "1.235"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fee, err := money.MustParse(tt.amount).Percent(money.MustParseRate(tt.percent), tt.minorUnits)
+
+			require.NoError(t, err)
+			require.Equal(t, money.MustParse(tt.want), fee)
+		})
+	}
+}
+
+func TestAmountUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "number", input: `12.5`, want: "12.5"},
+		{name: "string", input: `"12.5"`, want: "12.5"},
+		{name: "null keeps value", input: `null`, want: "7"},
+		{name: "unterminated string", input: `"12`, wantErr: true},
+		{name: "unopened string", input: `12"`, wantErr: true},
+		{name: "empty string", input: `""`, wantErr: true},
+		{name: "too precise", input: `0.0001`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount := money.MustParse("7")
+
+			err := amount.UnmarshalJSON([]byte(tt.input))
+			if tt.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, money.MustParse(tt.want), amount)
+		})
+	}
+}
+
+func TestAmountJSONRoundTrip(t *testing.T) {
+	type body struct {
+		Amount money.Amount `json:"amount"`
+	}
+
+	data, err := json.Marshal(body{Amount: money.MustParse("100.50")})
+
+	require.NoError(t, err)
+	require.JSONEq(t, `{"amount": 100.5}`, string(data))
+
+	var decoded body
+
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, money.MustParse("100.5"), decoded.Amount)
+}
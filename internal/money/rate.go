@@ -0,0 +1,135 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	rateScale           = 8
+	rateUnitsPerWhole   = 100_000_000
+	basisPointsPerWhole = 10_000
+)
+
+var ErrInvalidRate = errors.New("rate is not a valid positive decimal number")
+
+// Rate is an exchange rate with a fixed precision of eight decimal places.
+type Rate struct {
+	units int64
+}
+
+func ParseRate(s string) (Rate, error) {
+	s = strings.TrimSpace(s)
+	if !decimalPattern.MatchString(s) {
+		return Rate{}, ErrInvalidRate
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || r.Sign() <= 0 {
+		return Rate{}, ErrInvalidRate
+	}
+
+	return rateFromBig(roundQuo(new(big.Int).Mul(r.Num(), big.NewInt(rateUnitsPerWhole)), r.Denom()))
+}
+
+func MustParseRate(s string) Rate {
+	r, err := ParseRate(s)
+	if err != nil {
+		panic(fmt.Sprintf("money.MustParseRate(%q): %s", s, err))
+	}
+
+	return r
+}
+
+func (r Rate) Mul(m Rate) (Rate, error) {
+	product := new(big.Int).Mul(big.NewInt(r.units), big.NewInt(m.units))
+
+	return rateFromBig(roundQuo(product, big.NewInt(rateUnitsPerWhole)))
+}
+
+func (r Rate) Quo(d Rate) (Rate, error) {
+	if d.units == 0 {
+		return Rate{}, ErrInvalidRate
+	}
+
+	dividend := new(big.Int).Mul(big.NewInt(r.units), big.NewInt(rateUnitsPerWhole))
+
+	return rateFromBig(roundQuo(dividend, big.NewInt(d.units)))
+}
+
+// WithSpread moves the rate by the given number of basis points, up for positive and down for negative values.
+func (r Rate) WithSpread(bps int64) (Rate, error) {
+	product := new(big.Int).Mul(big.NewInt(r.units), big.NewInt(basisPointsPerWhole+bps))
+
+	return rateFromBig(roundQuo(product, big.NewInt(basisPointsPerWhole)))
+}
+
+func (r Rate) IsZero() bool {
+	return r.units == 0
+}
+
+func (r Rate) String() string {
+	return formatUnits(r.units, rateScale)
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding one. A null leaves the rate unchanged.
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	s, ok, err := jsonDecimal(data)
+	if !ok || err != nil {
+		return err
+	}
+
+	return r.UnmarshalText([]byte(s))
+}
+
+func (r *Rate) UnmarshalText(text []byte) error {
+	parsed, err := ParseRate(string(text))
+	if err != nil {
+		return fmt.Errorf("%q: %w", text, err)
+	}
+
+	*r = parsed
+
+	return nil
+}
+
+func (r *Rate) Scan(src any) error {
+	var s string
+
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidRate, src)
+	}
+
+	parsed, err := ParseRate(s)
+	if err != nil {
+		return fmt.Errorf("ParseRate: %w", err)
+	}
+
+	*r = parsed
+
+	return nil
+}
+
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+func rateFromBig(units *big.Int) (Rate, error) {
+	if !units.IsInt64() || units.Sign() <= 0 {
+		return Rate{}, ErrInvalidRate
+	}
+
+	return Rate{units: units.Int64()}, nil
+}
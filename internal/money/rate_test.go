@@ -0,0 +1,118 @@
+package money_test
+
+import (
+	"testing"
+
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "whole", input: "2", want: "2.00000000"},
+		{name: "decimal", input: "0.91", want: "0.91000000"},
+		{name: "rounded half up", input: "0.123456785", want: "0.12345679"},
+		{name: "rounded down", input: "0.123456784", want: "0.12345678"},
+		{name: "zero", input: "0", wantErr: true},
+		{name: "rounds to zero", input: "0.000000001", wantErr: true},
+		{name: "negative", input: "-1", wantErr: true},
+		{name: "fraction", input: "1/3", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+		{name: "hexadecimal", input: "0x10", wantErr: true},
+		{name: "underscores", input: "1_000", wantErr: true},
+		{name: "exponent", input: "1e-2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := money.ParseRate(tt.input)
+			if tt.wantErr {
+				require.ErrorIs(t, err, money.ErrInvalidRate)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, rate.String())
+		})
+	}
+}
+
+func TestRateArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		got  func() (money.Rate, error)
+		want string
+	}{
+		{
+			name: "mul",
+			got:  func() (money.Rate, error) { return money.MustParseRate("1.1").Mul(money.MustParseRate("0.9")) },
+			want: "0.99000000",
+		},
+		{
+			name: "quo rounded",
+			got:  func() (money.Rate, error) { return money.MustParseRate("1").Quo(money.MustParseRate("3")) },
+			want: "0.33333333",
+		},
+		{
+			name: "quo rounded half up",
+			got:  func() (money.Rate, error) { return money.MustParseRate("2").Quo(money.MustParseRate("3")) },
+			want: "0.66666667",
+		},
+		{
+			name: "spread up",
+			got:  func() (money.Rate, error) { return money.MustParseRate("100").WithSpread(50) },
+			want: "100.50000000",
+		},
+		{
+			name: "spread down",
+			got:  func() (money.Rate, error) { return money.MustParseRate("100").WithSpread(-50) },
+			want: "99.50000000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := tt.got()
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, rate.String())
+		})
+	}
+}
+
+func TestRateUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "number", input: `148.5`, want: "148.50000000"},
+		{name: "string", input: `"148.5"`, want: "148.50000000"},
+		{name: "null keeps value", input: `null`, want: "1.00000000"},
+		{name: "unterminated string", input: `"148.5`, wantErr: true},
+		{name: "zero", input: `0`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate := money.MustParseRate("1")
+
+			err := rate.UnmarshalJSON([]byte(tt.input))
+			if tt.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, rate.String())
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	walletmodel "github.com/AlexZav1327/service/internal/models"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	insertAPIKeyQuery = `
+	INSERT INTO api_keys (key_id, name, key_hash, scopes, wallet_ids, expires_at, created_by)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING key_id, name, scopes, wallet_ids, expires_at, last_used_at, revoked_at, created_by,
+		created_at;
+	`
+	getAPIKeyQuery = `
+	SELECT key_id, name, scopes, wallet_ids, expires_at, last_used_at, revoked_at, created_by,
+		created_at
+	FROM api_keys
+	WHERE key_id = $1;
+	`
+	getAPIKeysQuery = `
+	SELECT key_id, name, scopes, wallet_ids, expires_at, last_used_at, revoked_at, created_by,
+		created_at
+	FROM api_keys
+	ORDER BY created_at, key_id;
+	`
+	revokeAPIKeyQuery = `
+	UPDATE api_keys
+	SET revoked_at = now()
+	WHERE key_id = $1
+	AND revoked_at IS NULL;
+	`
+	useAPIKeyQuery = `
+	UPDATE api_keys
+	SET last_used_at = now()
+	WHERE key_hash = $1
+	AND revoked_at IS NULL
+	AND (expires_at IS NULL OR expires_at > now())
+	RETURNING key_id, name, scopes, wallet_ids, expires_at, last_used_at, revoked_at, created_by,
+		created_at;
+	`
+)
+
+var (
+	ErrAPIKeyNotFound  = errors.New("no such API key")
+	ErrInvalidAPIKeyID = errors.New("invalid keyID for type uuid")
+	ErrAPIKeyRevoked   = errors.New("API key was already revoked")
+	ErrAPIKeyNotValid  = errors.New("API key is unknown, revoked or expired")
+)
+
+func (p *Postgres) CreateAPIKey(ctx context.Context, apiKey walletmodel.APIKey, keyHash string) (
+	walletmodel.APIKey, error,
+) {
+	createdKey, err := scanAPIKey(p.db.QueryRow(
+		ctx,
+		insertAPIKeyQuery,
+		apiKey.KeyID,
+		apiKey.Name,
+		keyHash,
+		apiKey.Scopes,
+		apiKey.WalletIDs,
+		apiKey.ExpiresAt,
+		apiKey.CreatedBy,
+	))
+	if err != nil {
+		return walletmodel.APIKey{}, fmt.Errorf("scanAPIKey: %w", err)
+	}
+
+	return createdKey, nil
+}
+
+func (p *Postgres) GetAPIKey(ctx context.Context, id string) (walletmodel.APIKey, error) {
+	apiKey, err := scanAPIKey(p.db.QueryRow(ctx, getAPIKeyQuery, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return walletmodel.APIKey{}, ErrAPIKeyNotFound
+		}
+
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) && pgerrcode.InvalidTextRepresentation == pgErr.SQLState() {
+			return walletmodel.APIKey{}, ErrInvalidAPIKeyID
+		}
+
+		return walletmodel.APIKey{}, fmt.Errorf("scanAPIKey: %w", err)
+	}
+
+	return apiKey, nil
+}
+
+func (p *Postgres) GetAPIKeys(ctx context.Context) ([]walletmodel.APIKey, error) {
+	rows, err := p.db.Query(ctx, getAPIKeysQuery)
+	if err != nil {
+		return nil, fmt.Errorf("db.Query: %w", err)
+	}
+
+	defer rows.Close()
+
+	apiKeys := make([]walletmodel.APIKey, 0)
+
+	for rows.Next() {
+		apiKey, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanAPIKey: %w", err)
+		}
+
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	return apiKeys, nil
+}
+
+func (p *Postgres) RevokeAPIKey(ctx context.Context, id string) error {
+	commandTag, err := p.db.Exec(ctx, revokeAPIKeyQuery, id)
+	if err != nil {
+		return fmt.Errorf("db.Exec: %w", err)
+	}
+
+	if commandTag.RowsAffected() != 1 {
+		return ErrAPIKeyRevoked
+	}
+
+	return nil
+}
+
+// UseAPIKey returns the live key with the given hash and records that it was used.
+func (p *Postgres) UseAPIKey(ctx context.Context, keyHash string) (walletmodel.APIKey, error) {
+	apiKey, err := scanAPIKey(p.db.QueryRow(ctx, useAPIKeyQuery, keyHash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return walletmodel.APIKey{}, ErrAPIKeyNotValid
+	}
+
+	if err != nil {
+		return walletmodel.APIKey{}, fmt.Errorf("scanAPIKey: %w", err)
+	}
+
+	return apiKey, nil
+}
+
+func scanAPIKey(row pgx.Row) (walletmodel.APIKey, error) {
+	var apiKey walletmodel.APIKey
+
+	err := row.Scan(
+		&apiKey.KeyID,
+		&apiKey.Name,
+		&apiKey.Scopes,
+		&apiKey.WalletIDs,
+		&apiKey.ExpiresAt,
+		&apiKey.LastUsedAt,
+		&apiKey.RevokedAt,
+		&apiKey.CreatedBy,
+		&apiKey.Created,
+	)
+	if err != nil {
+		return walletmodel.APIKey{}, fmt.Errorf("row.Scan: %w", err)
+	}
+
+	return apiKey, nil
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	walletmodel "github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	openSubBalanceQuery = `
+	INSERT INTO wallet_balances (wallet_id, currency)
+	VALUES ($1, $2)
+	ON CONFLICT (wallet_id, currency) DO NOTHING;
+	`
+	manageSubBalanceQuery = `
+	UPDATE wallet_balances
+	SET balance = balance + $3, updated_at = now()
+	WHERE wallet_id = $1
+	AND currency = $2;
+	`
+	takeSubBalanceQuery = `
+	DELETE FROM wallet_balances
+	WHERE wallet_id = $1
+	AND currency = $2
+	RETURNING balance;
+	`
+)
+
+var ErrPrimaryCurrency = errors.New("currency is the primary currency of the wallet")
+
+// OpenSubBalance adds an empty sub-balance in the currency, unless the wallet already has one.
+func (p *Postgres) OpenSubBalance(ctx context.Context, id, currency string) (
+	walletmodel.ResponseWalletInstance, error,
+) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("db.Begin: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				p.log.Warningf("tx.Rollback: %s", err)
+			}
+		}
+	}()
+
+	walletID, err := uuid.Parse(id)
+	if err != nil {
+		err = ErrInvalidWalletID
+
+		return walletmodel.ResponseWalletInstance{}, err
+	}
+
+	locked, err := p.lockWallets(ctx, tx, []uuid.UUID{walletID})
+	if err != nil {
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("lockWallets: %w", err)
+	}
+
+	current, ok := locked[walletID]
+	if !ok {
+		err = ErrWalletNotFound
+
+		return walletmodel.ResponseWalletInstance{}, err
+	}
+
+	if current.currency == currency {
+		err = ErrPrimaryCurrency
+
+		return walletmodel.ResponseWalletInstance{}, err
+	}
+
+	_, err = tx.Exec(ctx, openSubBalanceQuery, walletID, currency)
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgerrcode.ForeignKeyViolation == pgErr.SQLState() {
+				err = ErrCurrencyNotFound
+
+				return walletmodel.ResponseWalletInstance{}, err
+			}
+		}
+
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("tx.Exec: %w", err)
+	}
+
+	wallet, err := p.queryRowToWallet(ctx, tx, getWalletQuery, walletID)
+	if err != nil {
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("queryRowToWallet: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// manageSubBalance applies a change to a sub-balance that is already open. A missing sub-balance
+// means the wallet changed after the transaction was built.
+func (*Postgres) manageSubBalance(ctx context.Context, tx pgx.Tx, id uuid.UUID, currency string,
+	delta money.Amount,
+) error {
+	commandTag, err := tx.Exec(ctx, manageSubBalanceQuery, id, currency, delta)
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgerrcode.NumericValueOutOfRange == pgErr.SQLState() {
+				return money.ErrOverflow
+			}
+
+			if pgerrcode.CheckViolation == pgErr.SQLState() && pgErr.ConstraintName == subBalanceCheck {
+				return ErrOverdraft
+			}
+		}
+
+		return fmt.Errorf("tx.Exec: %w", err)
+	}
+
+	if commandTag.RowsAffected() != 1 {
+		return ErrConcurrentUpdate
+	}
+
+	return nil
+}
+
+// takeSubBalance closes the sub-balance in the currency and returns what it held, so that it can be
+// added to the primary balance when the wallet switches to that currency.
+func (*Postgres) takeSubBalance(ctx context.Context, tx pgx.Tx, id uuid.UUID, currency string) (money.Amount, error) {
+	var subBalance money.Amount
+
+	err := tx.QueryRow(ctx, takeSubBalanceQuery, id, currency).Scan(&subBalance)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return money.Amount{}, nil
+		}
+
+		return money.Amount{}, fmt.Errorf("row.Scan: %w", err)
+	}
+
+	return subBalance, nil
+}
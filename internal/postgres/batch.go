@@ -0,0 +1,226 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	walletmodel "github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// BatchTransfer books the prepared items of a batch in one database transaction, each under its own
+// savepoint. itemError tells the errors that fail a single item from those that fail the whole request and
+// returns nil for the latter. A best-effort batch leaves out the items that fail; any other batch is rolled
+// back on the first failure. A batch that books nothing is rolled back, so its key can be used again.
+func (p *Postgres) BatchTransfer(ctx context.Context, key walletmodel.IdempotencyKey, idSrc, currency string,
+	entries []walletmodel.BatchTransferEntry, bestEffort bool, itemError func(error) error,
+) (walletmodel.ResponseBatchTransfer, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return walletmodel.ResponseBatchTransfer{}, fmt.Errorf("db.Begin: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				p.log.Warningf("tx.Rollback: %s", err)
+			}
+		}
+	}()
+
+	var response walletmodel.ResponseBatchTransfer
+
+	replayed, err := p.idempotency(ctx, tx, key, &response)
+	if err != nil {
+		return walletmodel.ResponseBatchTransfer{}, fmt.Errorf("idempotency: %w", err)
+	}
+
+	if replayed {
+		err = tx.Commit(ctx)
+		if err != nil {
+			return walletmodel.ResponseBatchTransfer{}, fmt.Errorf("tx.Commit: %w", err)
+		}
+
+		return response, nil
+	}
+
+	err = p.lockBatchWallets(ctx, tx, entries)
+	if err != nil {
+		return walletmodel.ResponseBatchTransfer{}, fmt.Errorf("lockBatchWallets: %w", err)
+	}
+
+	response = walletmodel.ResponseBatchTransfer{
+		TransactionKey: key.Key,
+		Status:         walletmodel.BatchCompleted,
+		Currency:       currency,
+		Items:          make([]walletmodel.BatchTransferResult, len(entries)),
+	}
+
+	succeeded := 0
+
+	for i, entry := range entries {
+		response.Items[i] = entry.Result
+
+		if entry.Txn == nil {
+			continue
+		}
+
+		err = p.postBatchItem(ctx, tx, *entry.Txn)
+		if err != nil && itemError(err) == nil {
+			return walletmodel.ResponseBatchTransfer{}, fmt.Errorf("postBatchItem: %w", err)
+		}
+
+		if err != nil {
+			response.Items[i].Status = walletmodel.BatchItemFailed
+			response.Items[i].Error = itemError(err).Error()
+			err = nil
+
+			if !bestEffort {
+				succeeded = 0
+
+				for j := range response.Items {
+					if j != i {
+						response.Items[j] = entries[j].Result
+					}
+				}
+
+				break
+			}
+
+			continue
+		}
+
+		response.Items[i], response.Total, err = bookedItem(response.Items[i], *entry.Txn, response.Total)
+		if err != nil {
+			return walletmodel.ResponseBatchTransfer{}, fmt.Errorf("bookedItem: %w", err)
+		}
+
+		succeeded++
+	}
+
+	switch succeeded {
+	case 0:
+		return p.rejectBatch(ctx, tx, idSrc, response)
+	case len(entries):
+	default:
+		response.Status = walletmodel.BatchPartial
+	}
+
+	response.SrcWallet, err = p.queryRowToWallet(ctx, tx, getWalletQuery, idSrc)
+	if err != nil {
+		return walletmodel.ResponseBatchTransfer{}, fmt.Errorf("queryRowToWallet: %w", err)
+	}
+
+	err = p.saveIdempotentResponse(ctx, tx, key, response)
+	if err != nil {
+		return walletmodel.ResponseBatchTransfer{}, fmt.Errorf("saveIdempotentResponse: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return walletmodel.ResponseBatchTransfer{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return response, nil
+}
+
+// lockBatchWallets locks every wallet of the batch up front in wallet_id order, as the items would
+// otherwise take their locks in the order of the batch.
+func (p *Postgres) lockBatchWallets(ctx context.Context, tx pgx.Tx, entries []walletmodel.BatchTransferEntry) error {
+	var ids []uuid.UUID
+
+	for _, entry := range entries {
+		if entry.Txn == nil {
+			continue
+		}
+
+		for _, ledgerEntry := range entry.Txn.Entries {
+			if ledgerEntry.WalletID.Valid && !containsWallet(ids, ledgerEntry.WalletID.UUID) {
+				ids = append(ids, ledgerEntry.WalletID.UUID)
+			}
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+
+	_, err := p.lockWallets(ctx, tx, ids)
+	if err != nil {
+		return fmt.Errorf("lockWallets: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) postBatchItem(ctx context.Context, tx pgx.Tx, txn walletmodel.LedgerTransaction) error {
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("tx.Begin: %w", err)
+	}
+
+	_, err = p.postTransaction(ctx, savepoint, txn)
+	if err != nil {
+		rollbackErr := savepoint.Rollback(ctx)
+		if rollbackErr != nil {
+			return fmt.Errorf("savepoint.Rollback: %w", rollbackErr)
+		}
+
+		return fmt.Errorf("postTransaction: %w", err)
+	}
+
+	err = savepoint.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("savepoint.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) rejectBatch(ctx context.Context, tx pgx.Tx, idSrc string,
+	response walletmodel.ResponseBatchTransfer,
+) (walletmodel.ResponseBatchTransfer, error) {
+	err := tx.Rollback(ctx)
+	if err != nil {
+		return walletmodel.ResponseBatchTransfer{}, fmt.Errorf("tx.Rollback: %w", err)
+	}
+
+	response.Status = walletmodel.BatchFailed
+	response.Total = money.Amount{}
+
+	response.SrcWallet, err = p.GetWallet(ctx, idSrc)
+	if err != nil {
+		return walletmodel.ResponseBatchTransfer{}, fmt.Errorf("GetWallet: %w", err)
+	}
+
+	return response, nil
+}
+
+func bookedItem(result walletmodel.BatchTransferResult, txn walletmodel.LedgerTransaction, total money.Amount) (
+	walletmodel.BatchTransferResult, money.Amount, error,
+) {
+	result.Status = walletmodel.BatchItemSucceeded
+	result.TransactionID = uuid.NullUUID{UUID: txn.TransactionID, Valid: true}
+	result.DebitedAmount = txn.Source.Amount
+	result.CreditedAmount = txn.Destination.Amount
+	result.Fee = txn.Fee
+
+	total, err := total.Add(txn.Source.Amount)
+	if err != nil {
+		return walletmodel.BatchTransferResult{}, money.Amount{}, fmt.Errorf("Add: %w", err)
+	}
+
+	if txn.Fee != nil {
+		total, err = total.Add(txn.Fee.Amount)
+		if err != nil {
+			return walletmodel.BatchTransferResult{}, money.Amount{}, fmt.Errorf("Add: %w", err)
+		}
+	}
+
+	return result, total, nil
+}
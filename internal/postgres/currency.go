@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	walletmodel "github.com/AlexZav1327/service/internal/models"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	createCurrencyQuery = `
+	INSERT INTO currencies (code, minor_units, enabled, reference_rate)
+	VALUES ($1, $2, $3, $4)
+	RETURNING code, minor_units, enabled, reference_rate, created_at, updated_at;
+	`
+	getCurrencyQuery = `
+	SELECT code, minor_units, enabled, reference_rate, created_at, updated_at
+	FROM currencies
+	WHERE code = $1;
+	`
+	getCurrenciesQuery = `
+	SELECT code, minor_units, enabled, reference_rate, created_at, updated_at
+	FROM currencies
+	ORDER BY code;
+	`
+	updateCurrencyQuery = `
+	UPDATE currencies
+	SET enabled = $2, reference_rate = $3, updated_at = now()
+	WHERE code = $1
+	RETURNING code, minor_units, enabled, reference_rate, created_at, updated_at;
+	`
+)
+
+var (
+	ErrCurrencyNotFound = errors.New("no such currency")
+	ErrCurrencyExists   = errors.New("currency already exists")
+)
+
+func (p *Postgres) CreateCurrency(ctx context.Context, currency walletmodel.Currency) (walletmodel.Currency, error) {
+	createdCurrency, err := scanCurrency(p.db.QueryRow(
+		ctx,
+		createCurrencyQuery,
+		currency.Code,
+		currency.MinorUnits,
+		currency.Enabled,
+		currency.ReferenceRate,
+	))
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgerrcode.UniqueViolation == pgErr.SQLState() {
+				return walletmodel.Currency{}, ErrCurrencyExists
+			}
+		}
+
+		return walletmodel.Currency{}, fmt.Errorf("scanCurrency: %w", err)
+	}
+
+	return createdCurrency, nil
+}
+
+func (p *Postgres) GetCurrency(ctx context.Context, code string) (walletmodel.Currency, error) {
+	currency, err := scanCurrency(p.db.QueryRow(ctx, getCurrencyQuery, code))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return walletmodel.Currency{}, ErrCurrencyNotFound
+		}
+
+		return walletmodel.Currency{}, fmt.Errorf("scanCurrency: %w", err)
+	}
+
+	return currency, nil
+}
+
+func (p *Postgres) GetCurrencies(ctx context.Context) ([]walletmodel.Currency, error) {
+	rows, err := p.db.Query(ctx, getCurrenciesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("db.Query: %w", err)
+	}
+
+	defer rows.Close()
+
+	currencies := make([]walletmodel.Currency, 0)
+
+	for rows.Next() {
+		currency, err := scanCurrency(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanCurrency: %w", err)
+		}
+
+		currencies = append(currencies, currency)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	return currencies, nil
+}
+
+func (p *Postgres) UpdateCurrency(ctx context.Context, currency walletmodel.Currency) (walletmodel.Currency, error) {
+	updatedCurrency, err := scanCurrency(p.db.QueryRow(
+		ctx,
+		updateCurrencyQuery,
+		currency.Code,
+		currency.Enabled,
+		currency.ReferenceRate,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return walletmodel.Currency{}, ErrCurrencyNotFound
+		}
+
+		return walletmodel.Currency{}, fmt.Errorf("scanCurrency: %w", err)
+	}
+
+	return updatedCurrency, nil
+}
+
+func scanCurrency(row pgx.Row) (walletmodel.Currency, error) {
+	var currency walletmodel.Currency
+
+	err := row.Scan(
+		&currency.Code,
+		&currency.MinorUnits,
+		&currency.Enabled,
+		&currency.ReferenceRate,
+		&currency.Created,
+		&currency.Updated,
+	)
+	if err != nil {
+		return walletmodel.Currency{}, fmt.Errorf("row.Scan: %w", err)
+	}
+
+	return currency, nil
+}
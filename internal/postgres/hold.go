@@ -0,0 +1,419 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	walletmodel "github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	holdWalletQuery = `
+	UPDATE wallet
+	SET held = held + $2
+	WHERE wallet_id = $1
+	AND currency = $3
+	AND deleted = FALSE
+	RETURNING ` + walletColumns + `;
+	`
+	releaseWalletQuery = `
+	UPDATE wallet
+	SET held = held - $2
+	WHERE wallet_id = $1
+	AND deleted = FALSE
+	RETURNING ` + walletColumns + `;
+	`
+	insertHoldQuery = `
+	INSERT INTO holds (hold_id, wallet_id, amount, currency, held_amount, held_currency, rate, side, spread_bps,
+		transaction_key, status, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	RETURNING hold_id, wallet_id, amount, currency, held_amount, held_currency, rate, side, spread_bps,
+		captured_amount, transaction_id, transaction_key, status, expires_at, created_at, updated_at;
+	`
+	getHoldQuery = `
+	SELECT hold_id, wallet_id, amount, currency, held_amount, held_currency, rate, side, spread_bps,
+		captured_amount, transaction_id, transaction_key, status, expires_at, created_at, updated_at
+	FROM holds
+	WHERE hold_id = $1;
+	`
+	lockHoldQuery = `
+	SELECT hold_id, wallet_id, amount, currency, held_amount, held_currency, rate, side, spread_bps,
+		captured_amount, transaction_id, transaction_key, status, expires_at, created_at, updated_at
+	FROM holds
+	WHERE hold_id = $1
+	FOR UPDATE;
+	`
+	closeHoldQuery = `
+	UPDATE holds
+	SET status = $2, captured_amount = $3, transaction_id = $4, updated_at = now()
+	WHERE hold_id = $1
+	RETURNING hold_id, wallet_id, amount, currency, held_amount, held_currency, rate, side, spread_bps,
+		captured_amount, transaction_id, transaction_key, status, expires_at, created_at, updated_at;
+	`
+	expireHoldsQuery = `
+	WITH expired AS (
+		UPDATE holds
+		SET status = 'EXPIRED', updated_at = now()
+		WHERE hold_id IN (
+			SELECT hold_id
+			FROM holds
+			WHERE status = 'AUTHORIZED'
+			AND expires_at <= now()
+			ORDER BY hold_id
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING wallet_id, held_amount
+	), released AS (
+		SELECT wallet_id, SUM(held_amount) AS held_amount
+		FROM expired
+		GROUP BY wallet_id
+	)
+	UPDATE wallet w
+	SET held = w.held - r.held_amount
+	FROM released r
+	WHERE w.wallet_id = r.wallet_id;
+	`
+)
+
+var (
+	ErrHoldNotFound   = errors.New("no such hold")
+	ErrInvalidHoldID  = errors.New("invalid holdID for type uuid")
+	ErrHoldNotActive  = errors.New("hold is already captured, voided or expired")
+	ErrHoldExpired    = errors.New("hold has expired")
+	ErrHoldChanged    = errors.New("hold was changed by a concurrent request")
+	ErrWalletHasHolds = errors.New("wallet has active holds")
+)
+
+type holdRateColumns struct {
+	rate      *money.Rate
+	side      *string
+	spreadBps *int64
+}
+
+func (p *Postgres) AuthorizeHold(ctx context.Context, key walletmodel.IdempotencyKey, hold walletmodel.Hold) (
+	walletmodel.ResponseHold, error,
+) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("db.Begin: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				p.log.Warningf("tx.Rollback: %s", err)
+			}
+		}
+	}()
+
+	var response walletmodel.ResponseHold
+
+	replayed, err := p.idempotency(ctx, tx, key, &response)
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("idempotency: %w", err)
+	}
+
+	if replayed {
+		err = tx.Commit(ctx)
+		if err != nil {
+			return walletmodel.ResponseHold{}, fmt.Errorf("tx.Commit: %w", err)
+		}
+
+		return response, nil
+	}
+
+	response.Wallet, err = p.queryRowToWallet(ctx, tx, holdWalletQuery, hold.WalletID, hold.HeldAmount,
+		hold.HeldCurrency)
+	if err != nil {
+		if errors.Is(err, ErrWalletNotFound) {
+			err = ErrConcurrentUpdate
+		}
+
+		return walletmodel.ResponseHold{}, fmt.Errorf("queryRowToWallet: %w", err)
+	}
+
+	rate := rateColumns(hold.AppliedRate)
+
+	response.Hold, err = scanHold(tx.QueryRow(
+		ctx,
+		insertHoldQuery,
+		hold.HoldID,
+		hold.WalletID,
+		hold.Amount,
+		hold.Currency,
+		hold.HeldAmount,
+		hold.HeldCurrency,
+		rate.rate,
+		rate.side,
+		rate.spreadBps,
+		hold.TransactionKey,
+		walletmodel.HoldAuthorized,
+		hold.ExpiresAt,
+	))
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("scanHold: %w", err)
+	}
+
+	err = p.saveIdempotentResponse(ctx, tx, key, response)
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("saveIdempotentResponse: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return response, nil
+}
+
+func (p *Postgres) GetHold(ctx context.Context, id string) (walletmodel.Hold, error) {
+	hold, err := scanHold(p.db.QueryRow(ctx, getHoldQuery, id))
+	if err != nil {
+		return walletmodel.Hold{}, holdError(err)
+	}
+
+	return hold, nil
+}
+
+// CaptureHold releases the whole hold and posts the captured amount in the same transaction. The hold
+// must still match the one the ledger transaction was built from.
+func (p *Postgres) CaptureHold(ctx context.Context, key walletmodel.IdempotencyKey, hold walletmodel.Hold,
+	txn walletmodel.LedgerTransaction,
+) (walletmodel.ResponseHold, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("db.Begin: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				p.log.Warningf("tx.Rollback: %s", err)
+			}
+		}
+	}()
+
+	var response walletmodel.ResponseHold
+
+	replayed, err := p.idempotency(ctx, tx, key, &response)
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("idempotency: %w", err)
+	}
+
+	if replayed {
+		err = tx.Commit(ctx)
+		if err != nil {
+			return walletmodel.ResponseHold{}, fmt.Errorf("tx.Commit: %w", err)
+		}
+
+		return response, nil
+	}
+
+	err = p.acquireHold(ctx, tx, hold, true)
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("acquireHold: %w", err)
+	}
+
+	deltas, err := walletDeltas(txn)
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("walletDeltas: %w", err)
+	}
+
+	ids := []uuid.UUID{hold.WalletID}
+	for walletKey := range deltas {
+		ids = append(ids, walletKey.walletID)
+	}
+
+	_, err = p.lockWallets(ctx, tx, ids)
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("lockWallets: %w", err)
+	}
+
+	_, err = p.queryRowToWallet(ctx, tx, releaseWalletQuery, hold.WalletID, hold.HeldAmount)
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("queryRowToWallet: %w", err)
+	}
+
+	wallets, err := p.postTransaction(ctx, tx, txn)
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("postTransaction: %w", err)
+	}
+
+	wallet, ok := wallets[hold.WalletID.String()]
+	if !ok {
+		err = ErrWalletNotFound
+
+		return walletmodel.ResponseHold{}, err
+	}
+
+	response.Hold, err = scanHold(tx.QueryRow(ctx, closeHoldQuery, hold.HoldID, walletmodel.HoldCaptured,
+		txn.Amount, txn.TransactionID))
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("scanHold: %w", err)
+	}
+
+	response.Wallet = wallet
+	response.Fee = txn.Fee
+
+	err = p.saveIdempotentResponse(ctx, tx, key, response)
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("saveIdempotentResponse: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return response, nil
+}
+
+func (p *Postgres) VoidHold(ctx context.Context, hold walletmodel.Hold) (walletmodel.ResponseHold, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("db.Begin: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				p.log.Warningf("tx.Rollback: %s", err)
+			}
+		}
+	}()
+
+	err = p.acquireHold(ctx, tx, hold, false)
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("acquireHold: %w", err)
+	}
+
+	var response walletmodel.ResponseHold
+
+	response.Wallet, err = p.queryRowToWallet(ctx, tx, releaseWalletQuery, hold.WalletID, hold.HeldAmount)
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("queryRowToWallet: %w", err)
+	}
+
+	response.Hold, err = scanHold(tx.QueryRow(ctx, closeHoldQuery, hold.HoldID, walletmodel.HoldVoided,
+		money.Amount{}, uuid.NullUUID{}))
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("scanHold: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return walletmodel.ResponseHold{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return response, nil
+}
+
+func (p *Postgres) ExpireHolds(ctx context.Context) (int64, error) {
+	commandTag, err := p.db.Exec(ctx, expireHoldsQuery)
+	if err != nil {
+		return 0, fmt.Errorf("db.Exec: %w", err)
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
+// acquireHold locks the hold and checks it is still authorized with the amount it was read with.
+// Expired holds not yet swept can be voided but not captured.
+func (*Postgres) acquireHold(ctx context.Context, tx pgx.Tx, hold walletmodel.Hold, capture bool) error {
+	current, err := scanHold(tx.QueryRow(ctx, lockHoldQuery, hold.HoldID))
+	if err != nil {
+		return holdError(err)
+	}
+
+	if current.Status != walletmodel.HoldAuthorized {
+		return ErrHoldNotActive
+	}
+
+	if current.HeldAmount != hold.HeldAmount || current.WalletID != hold.WalletID {
+		return ErrHoldChanged
+	}
+
+	if capture && !current.ExpiresAt.After(time.Now()) {
+		return ErrHoldExpired
+	}
+
+	return nil
+}
+
+func holdError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrHoldNotFound
+	}
+
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) {
+		if pgerrcode.InvalidTextRepresentation == pgErr.SQLState() {
+			return ErrInvalidHoldID
+		}
+	}
+
+	return fmt.Errorf("scanHold: %w", err)
+}
+
+func scanHold(row pgx.Row) (walletmodel.Hold, error) {
+	var (
+		hold walletmodel.Hold
+		rate holdRateColumns
+	)
+
+	err := row.Scan(
+		&hold.HoldID,
+		&hold.WalletID,
+		&hold.Amount,
+		&hold.Currency,
+		&hold.HeldAmount,
+		&hold.HeldCurrency,
+		&rate.rate,
+		&rate.side,
+		&rate.spreadBps,
+		&hold.CapturedAmount,
+		&hold.TransactionID,
+		&hold.TransactionKey,
+		&hold.Status,
+		&hold.ExpiresAt,
+		&hold.Created,
+		&hold.Updated,
+	)
+	if err != nil {
+		return walletmodel.Hold{}, fmt.Errorf("row.Scan: %w", err)
+	}
+
+	if rate.rate != nil {
+		hold.AppliedRate = &walletmodel.AppliedRate{Rate: *rate.rate}
+
+		if rate.side != nil {
+			hold.AppliedRate.Side = *rate.side
+		}
+
+		if rate.spreadBps != nil {
+			hold.AppliedRate.SpreadBps = *rate.spreadBps
+		}
+	}
+
+	return hold, nil
+}
+
+func rateColumns(rate *walletmodel.AppliedRate) holdRateColumns {
+	if rate == nil {
+		return holdRateColumns{}
+	}
+
+	return holdRateColumns{rate: &rate.Rate, side: &rate.Side, spreadBps: &rate.SpreadBps}
+}
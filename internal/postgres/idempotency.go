@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	walletmodel "github.com/AlexZav1327/service/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	verifyTransactKeyQuery = `
+	INSERT INTO idempotency (transaction_key, fingerprint)
+	VALUES ($1, $2)
+	ON CONFLICT (transaction_key) DO NOTHING;
+	`
+	getIdempotentResponseQuery = `
+	SELECT fingerprint, response
+	FROM idempotency
+	WHERE transaction_key = $1;
+	`
+	saveIdempotentResponseQuery = `
+	UPDATE idempotency
+	SET response = $2
+	WHERE transaction_key = $1;
+	`
+	deleteExpiredKeysQuery = `
+	DELETE FROM idempotency
+	WHERE created_at < $1;
+	`
+)
+
+var ErrIdempotencyKeyReused = errors.New("transaction key was already used for a different request")
+
+func (p *Postgres) DeleteExpiredIdempotencyKeys(ctx context.Context, retention time.Duration) (int64, error) {
+	commandTag, err := p.db.Exec(ctx, deleteExpiredKeysQuery, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("db.Exec: %w", err)
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
+// idempotency reserves the key for the current transaction. When the key was already used for the same
+// request, the stored response is decoded into dest and replayed is true.
+func (*Postgres) idempotency(ctx context.Context, q querier, key walletmodel.IdempotencyKey, dest any) (
+	bool, error,
+) {
+	commandTag, err := q.Exec(ctx, verifyTransactKeyQuery, key.Key, key.Fingerprint)
+	if err != nil {
+		return false, fmt.Errorf("exec: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 1 {
+		return false, nil
+	}
+
+	var (
+		fingerprint *string
+		response    []byte
+	)
+
+	err = q.QueryRow(ctx, getIdempotentResponseQuery, key.Key).Scan(&fingerprint, &response)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, ErrIdempotencyKeyReused
+		}
+
+		return false, fmt.Errorf("row.Scan: %w", err)
+	}
+
+	if fingerprint == nil || *fingerprint != key.Fingerprint || response == nil {
+		return false, ErrIdempotencyKeyReused
+	}
+
+	err = json.Unmarshal(response, dest)
+	if err != nil {
+		return false, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	return true, nil
+}
+
+func (*Postgres) saveIdempotentResponse(ctx context.Context, q querier, key walletmodel.IdempotencyKey,
+	response any,
+) error {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	_, err = q.Exec(ctx, saveIdempotentResponseQuery, key.Key, data)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,281 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	walletmodel "github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	insertLedgerEntryQuery = `
+	INSERT INTO ledger_entries (transaction_id, account, wallet_id, direction, amount, currency, operation_type)
+	VALUES ($1, $2, $3, $4, $5, $6, $7);
+	`
+	getLedgerBalanceQuery = `
+	SELECT COALESCE(SUM(CASE WHEN l.direction = 'CREDIT' THEN l.amount ELSE -l.amount END), 0)
+	FROM wallet w
+	LEFT JOIN ledger_entries l ON l.wallet_id = w.wallet_id AND l.currency = w.currency
+	WHERE w.wallet_id = $1
+	AND w.deleted = FALSE
+	GROUP BY w.wallet_id;
+	`
+	lockWalletsQuery = `
+	SELECT wallet_id, currency, balance, held, credit_limit
+	FROM wallet
+	WHERE wallet_id = ANY($1)
+	AND deleted = FALSE
+	ORDER BY wallet_id
+	FOR UPDATE;
+	`
+)
+
+var (
+	ErrUnbalancedTransaction = errors.New("ledger transaction is unbalanced")
+	ErrConcurrentUpdate      = errors.New("wallet was changed by a concurrent request")
+)
+
+type lockedWallet struct {
+	currency    string
+	balance     money.Amount
+	held        money.Amount
+	creditLimit money.Amount
+}
+
+type walletBalanceKey struct {
+	walletID uuid.UUID
+	currency string
+}
+
+func (p *Postgres) GetLedgerBalance(ctx context.Context, id string) (money.Amount, error) {
+	var ledgerBalance money.Amount
+
+	err := p.db.QueryRow(ctx, getLedgerBalanceQuery, id).Scan(&ledgerBalance)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return money.Amount{}, ErrWalletNotFound
+		}
+
+		return money.Amount{}, fmt.Errorf("row.Scan: %w", err)
+	}
+
+	return ledgerBalance, nil
+}
+
+func (p *Postgres) postTransaction(ctx context.Context, tx pgx.Tx, txn walletmodel.LedgerTransaction) (
+	map[string]walletmodel.ResponseWalletInstance, error,
+) {
+	deltas, err := walletDeltas(txn)
+	if err != nil {
+		return nil, fmt.Errorf("walletDeltas: %w", err)
+	}
+
+	keys := make([]walletBalanceKey, 0, len(deltas))
+	ids := make([]uuid.UUID, 0, len(deltas))
+
+	for key := range deltas {
+		keys = append(keys, key)
+
+		if !containsWallet(ids, key.walletID) {
+			ids = append(ids, key.walletID)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].walletID != keys[j].walletID {
+			return keys[i].walletID.String() < keys[j].walletID.String()
+		}
+
+		return keys[i].currency < keys[j].currency
+	})
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+
+	locked, err := p.lockWallets(ctx, tx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("lockWallets: %w", err)
+	}
+
+	err = p.checkSpending(ctx, tx, txn)
+	if err != nil {
+		return nil, fmt.Errorf("checkSpending: %w", err)
+	}
+
+	err = p.recordTransaction(ctx, tx, txn)
+	if err != nil {
+		return nil, fmt.Errorf("recordTransaction: %w", err)
+	}
+
+	primaryDeltas := make(map[uuid.UUID]money.Amount, len(ids))
+
+	for _, key := range keys {
+		current, ok := locked[key.walletID]
+		if !ok {
+			return nil, ErrWalletNotFound
+		}
+
+		if current.currency == key.currency {
+			primaryDeltas[key.walletID] = deltas[key]
+
+			continue
+		}
+
+		err = p.manageSubBalance(ctx, tx, key.walletID, key.currency, deltas[key])
+		if err != nil {
+			return nil, fmt.Errorf("manageSubBalance: %w", err)
+		}
+	}
+
+	wallets := make(map[string]walletmodel.ResponseWalletInstance, len(ids))
+
+	for _, id := range ids {
+		wallet, err := p.queryRowToWallet(ctx, tx, manageFundsQuery, id, primaryDeltas[id], locked[id].currency)
+		if err != nil {
+			return nil, fmt.Errorf("queryRowToWallet: %w", err)
+		}
+
+		wallets[wallet.WalletID.String()] = wallet
+	}
+
+	return wallets, nil
+}
+
+func containsWallet(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, walletID := range ids {
+		if walletID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lockWallets takes row locks in wallet_id order, so concurrent transactions touching the same
+// wallets always lock them in the same sequence and cannot deadlock each other.
+func (*Postgres) lockWallets(ctx context.Context, tx pgx.Tx, ids []uuid.UUID) (map[uuid.UUID]lockedWallet, error) {
+	rows, err := tx.Query(ctx, lockWalletsQuery, ids)
+	if err != nil {
+		return nil, fmt.Errorf("tx.Query: %w", err)
+	}
+
+	defer rows.Close()
+
+	locked := make(map[uuid.UUID]lockedWallet, len(ids))
+
+	for rows.Next() {
+		var (
+			id     uuid.UUID
+			wallet lockedWallet
+		)
+
+		err = rows.Scan(&id, &wallet.currency, &wallet.balance, &wallet.held, &wallet.creditLimit)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+
+		locked[id] = wallet
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	return locked, nil
+}
+
+func (*Postgres) insertLedgerEntries(ctx context.Context, q querier, txn walletmodel.LedgerTransaction) error {
+	err := validateLedgerTransaction(txn)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range txn.Entries {
+		_, err = q.Exec(
+			ctx,
+			insertLedgerEntryQuery,
+			txn.TransactionID,
+			entry.Account,
+			entry.WalletID,
+			entry.Direction,
+			entry.Amount,
+			entry.Currency,
+			txn.OperationType,
+		)
+		if err != nil {
+			return fmt.Errorf("exec: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func validateLedgerTransaction(txn walletmodel.LedgerTransaction) error {
+	if len(txn.Entries) < 2 {
+		return ErrUnbalancedTransaction
+	}
+
+	totals := make(map[string]money.Amount)
+
+	for _, entry := range txn.Entries {
+		if entry.Amount.Sign() <= 0 {
+			return ErrUnbalancedTransaction
+		}
+
+		var err error
+
+		switch entry.Direction {
+		case walletmodel.Credit:
+			totals[entry.Currency], err = totals[entry.Currency].Add(entry.Amount)
+		case walletmodel.Debit:
+			totals[entry.Currency], err = totals[entry.Currency].Sub(entry.Amount)
+		default:
+			return ErrUnbalancedTransaction
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrUnbalancedTransaction, err)
+		}
+	}
+
+	for _, total := range totals {
+		if !total.IsZero() {
+			return ErrUnbalancedTransaction
+		}
+	}
+
+	return nil
+}
+
+func walletDeltas(txn walletmodel.LedgerTransaction) (map[walletBalanceKey]money.Amount, error) {
+	deltas := make(map[walletBalanceKey]money.Amount)
+
+	for _, entry := range txn.Entries {
+		if !entry.WalletID.Valid {
+			continue
+		}
+
+		key := walletBalanceKey{walletID: entry.WalletID.UUID, currency: entry.Currency}
+
+		var err error
+
+		if entry.Direction == walletmodel.Credit {
+			deltas[key], err = deltas[key].Add(entry.Amount)
+		} else {
+			deltas[key], err = deltas[key].Sub(entry.Amount)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("delta: %w", err)
+		}
+	}
+
+	return deltas, nil
+}
@@ -0,0 +1,220 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	walletmodel "github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	getCurrencyLimitsQuery = `
+	SELECT daily, weekly, monthly
+	FROM currency_spending_limits
+	WHERE currency = $1;
+	`
+	setCurrencyLimitsQuery = `
+	INSERT INTO currency_spending_limits (currency, daily, weekly, monthly)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (currency) DO UPDATE
+	SET daily = $2, weekly = $3, monthly = $4, updated_at = now()
+	RETURNING daily, weekly, monthly;
+	`
+	getWalletLimitsQuery = `
+	SELECT daily, weekly, monthly
+	FROM wallet_spending_limits
+	WHERE wallet_id = $1;
+	`
+	setWalletLimitsQuery = `
+	INSERT INTO wallet_spending_limits (wallet_id, daily, weekly, monthly)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (wallet_id) DO UPDATE
+	SET daily = $2, weekly = $3, monthly = $4, updated_at = now()
+	RETURNING daily, weekly, monthly;
+	`
+)
+
+// netSpent is the debited amount of a transaction less the share of it that was reversed since.
+const netSpent = `ROUND(src_amount * (amount - reversed_amount) / NULLIF(amount, 0), 3)`
+
+var ErrSpendingLimitExceeded = errors.New("spending limit exceeded")
+
+func (p *Postgres) GetCurrencyLimits(ctx context.Context, code string) (walletmodel.SpendingLimits, error) {
+	limits, err := scanSpendingLimits(p.db.QueryRow(ctx, getCurrencyLimitsQuery, code))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return walletmodel.SpendingLimits{}, nil
+		}
+
+		return walletmodel.SpendingLimits{}, fmt.Errorf("scanSpendingLimits: %w", err)
+	}
+
+	return limits, nil
+}
+
+func (p *Postgres) SetCurrencyLimits(ctx context.Context, code string, limits walletmodel.SpendingLimits) (
+	walletmodel.SpendingLimits, error,
+) {
+	updatedLimits, err := scanSpendingLimits(p.db.QueryRow(ctx, setCurrencyLimitsQuery, code, limits.Daily,
+		limits.Weekly, limits.Monthly))
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgerrcode.ForeignKeyViolation == pgErr.SQLState() {
+				return walletmodel.SpendingLimits{}, ErrCurrencyNotFound
+			}
+		}
+
+		return walletmodel.SpendingLimits{}, fmt.Errorf("scanSpendingLimits: %w", err)
+	}
+
+	return updatedLimits, nil
+}
+
+func (p *Postgres) GetWalletLimits(ctx context.Context, id string) (walletmodel.SpendingLimits, error) {
+	limits, err := scanSpendingLimits(p.db.QueryRow(ctx, getWalletLimitsQuery, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return walletmodel.SpendingLimits{}, nil
+		}
+
+		return walletmodel.SpendingLimits{}, fmt.Errorf("scanSpendingLimits: %w", err)
+	}
+
+	return limits, nil
+}
+
+func (p *Postgres) SetWalletLimits(ctx context.Context, id string, limits walletmodel.SpendingLimits) (
+	walletmodel.SpendingLimits, error,
+) {
+	updatedLimits, err := scanSpendingLimits(p.db.QueryRow(ctx, setWalletLimitsQuery, id, limits.Daily,
+		limits.Weekly, limits.Monthly))
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgerrcode.InvalidTextRepresentation == pgErr.SQLState() {
+				return walletmodel.SpendingLimits{}, ErrInvalidWalletID
+			}
+		}
+
+		return walletmodel.SpendingLimits{}, fmt.Errorf("scanSpendingLimits: %w", err)
+	}
+
+	return updatedLimits, nil
+}
+
+// GetSpentAmounts sums the amounts debited from the wallet in its currency by the given operations, net
+// of reversals, once for every window start, in one pass over the oldest window. The transaction recorded under
+// excludeKey is left out, so that a replayed request is not counted against itself.
+func (p *Postgres) GetSpentAmounts(ctx context.Context, id, currency string, operations []string,
+	excludeKey uuid.NullUUID, since []time.Time,
+) ([]money.Amount, error) {
+	return p.spentAmounts(ctx, p.db, id, currency, operations, excludeKey, since)
+}
+
+// checkSpending fails when the debit of txn would take the spending of its source wallet over a cap. It
+// runs once the source wallet is locked, so concurrent debits from a wallet are checked one at a time.
+func (p *Postgres) checkSpending(ctx context.Context, q querier, txn walletmodel.LedgerTransaction) error {
+	if txn.Spending == nil || txn.Source == nil || len(txn.Spending.Caps) == 0 {
+		return nil
+	}
+
+	since := make([]time.Time, len(txn.Spending.Caps))
+
+	for i, limit := range txn.Spending.Caps {
+		since[i] = limit.Since
+	}
+
+	spent, err := p.spentAmounts(ctx, q, txn.Source.WalletID.String(), txn.Source.Currency,
+		txn.Spending.Operations, uuid.NullUUID{}, since)
+	if err != nil {
+		return fmt.Errorf("spentAmounts: %w", err)
+	}
+
+	for i, limit := range txn.Spending.Caps {
+		total, err := spent[i].Add(txn.Source.Amount)
+		if err != nil {
+			return fmt.Errorf("Add: %w", err)
+		}
+
+		if total.Cmp(limit.Limit) > 0 {
+			return fmt.Errorf("%w: %s", ErrSpendingLimitExceeded, limit.Period)
+		}
+	}
+
+	return nil
+}
+
+func (*Postgres) spentAmounts(ctx context.Context, q querier, id, currency string, operations []string,
+	excludeKey uuid.NullUUID, since []time.Time,
+) ([]money.Amount, error) {
+	if len(since) == 0 {
+		return nil, nil
+	}
+
+	args := []interface{}{id, currency, operations, excludeKey}
+	sums := make([]string, 0, len(since))
+	oldest := since[0]
+
+	for _, start := range since {
+		args = append(args, start)
+		sums = append(sums, fmt.Sprintf(`COALESCE(SUM(%s) FILTER (WHERE created_at > $%d), 0)`, netSpent, len(args)))
+
+		if start.Before(oldest) {
+			oldest = start
+		}
+	}
+
+	args = append(args, oldest)
+	query := fmt.Sprintf(`
+	SELECT %s
+	FROM transactions
+	WHERE src_wallet_id = $1
+	AND src_currency = $2
+	AND operation_type = ANY($3)
+	AND transaction_key IS DISTINCT FROM $4
+	AND created_at > $%d`, strings.Join(sums, ", "), len(args))
+
+	spent := make([]money.Amount, len(since))
+	dest := make([]interface{}, len(since))
+
+	for i := range spent {
+		dest[i] = &spent[i]
+	}
+
+	err := q.QueryRow(ctx, query, args...).Scan(dest...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgerrcode.InvalidTextRepresentation == pgErr.SQLState() {
+				return nil, ErrInvalidWalletID
+			}
+		}
+
+		return nil, fmt.Errorf("row.Scan: %w", err)
+	}
+
+	return spent, nil
+}
+
+func scanSpendingLimits(row pgx.Row) (walletmodel.SpendingLimits, error) {
+	var limits walletmodel.SpendingLimits
+
+	err := row.Scan(&limits.Daily, &limits.Weekly, &limits.Monthly)
+	if err != nil {
+		return walletmodel.SpendingLimits{}, fmt.Errorf("row.Scan: %w", err)
+	}
+
+	return limits, nil
+}
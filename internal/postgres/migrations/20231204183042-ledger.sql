@@ -0,0 +1,63 @@
+-- +migrate Up
+CREATE TABLE ledger_entries (
+    entry_id BIGSERIAL NOT NULL PRIMARY KEY,
+    transaction_id UUID NOT NULL,
+    account VARCHAR NOT NULL,
+    wallet_id UUID,
+    direction VARCHAR NOT NULL CHECK (direction IN ('DEBIT', 'CREDIT')),
+    amount NUMERIC(12, 2) NOT NULL CHECK (amount > 0),
+    currency VARCHAR NOT NULL,
+    operation_type VARCHAR NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT date_trunc('second', NOW())
+);
+
+CREATE INDEX ledger_entries_transaction_id_idx ON ledger_entries (transaction_id);
+CREATE INDEX ledger_entries_wallet_id_idx ON ledger_entries (wallet_id, currency);
+
+WITH opening AS (
+    SELECT wallet_id, currency, balance, gen_random_uuid() AS transaction_id
+    FROM wallet
+    WHERE balance <> 0
+)
+INSERT INTO ledger_entries (transaction_id, account, wallet_id, direction, amount, currency, operation_type)
+SELECT transaction_id, 'equity:' || currency, NULL, CASE WHEN balance > 0 THEN 'DEBIT' ELSE 'CREDIT' END,
+       abs(balance), currency, 'OPENING'
+FROM opening
+UNION ALL
+SELECT transaction_id, wallet_id::VARCHAR, wallet_id, CASE WHEN balance > 0 THEN 'CREDIT' ELSE 'DEBIT' END,
+       abs(balance), currency, 'OPENING'
+FROM opening;
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION check_wallet_ledger()
+RETURNS TRIGGER AS $$
+DECLARE
+    wallet_balance NUMERIC(12, 2);
+    wallet_currency VARCHAR;
+    ledger_balance NUMERIC(12, 2);
+BEGIN
+    SELECT balance, currency INTO wallet_balance, wallet_currency
+    FROM wallet
+    WHERE wallet_id = NEW.wallet_id;
+
+    SELECT COALESCE(SUM(CASE WHEN direction = 'CREDIT' THEN amount ELSE -amount END), 0) INTO ledger_balance
+    FROM ledger_entries
+    WHERE wallet_id = NEW.wallet_id
+    AND currency = wallet_currency;
+
+    IF wallet_balance <> ledger_balance THEN
+        RAISE EXCEPTION 'wallet % balance % does not match ledger balance %',
+            NEW.wallet_id, wallet_balance, ledger_balance
+            USING ERRCODE = 'integrity_constraint_violation';
+    END IF;
+RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+CREATE CONSTRAINT TRIGGER ledger_balance_trigger
+    AFTER INSERT OR UPDATE ON wallet
+        DEFERRABLE INITIALLY DEFERRED
+        FOR EACH ROW
+        EXECUTE FUNCTION check_wallet_ledger();
+
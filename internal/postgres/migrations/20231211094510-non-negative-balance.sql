@@ -0,0 +1,2 @@
+-- +migrate Up
+ALTER TABLE wallet ADD CONSTRAINT wallet_balance_non_negative CHECK (balance >= 0);
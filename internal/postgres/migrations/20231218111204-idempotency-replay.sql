@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE idempotency
+    ADD COLUMN fingerprint VARCHAR,
+    ADD COLUMN response JSONB,
+    ADD COLUMN created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+
+CREATE INDEX idempotency_created_at_idx ON idempotency (created_at);
@@ -0,0 +1,22 @@
+-- +migrate Up
+CREATE TABLE transactions (
+    transaction_id UUID NOT NULL PRIMARY KEY,
+    operation_type VARCHAR NOT NULL,
+    amount NUMERIC(12, 2) NOT NULL,
+    currency VARCHAR NOT NULL,
+    src_wallet_id UUID,
+    src_amount NUMERIC(12, 2),
+    src_currency VARCHAR,
+    src_rate NUMERIC(18, 8),
+    dst_wallet_id UUID,
+    dst_amount NUMERIC(12, 2),
+    dst_currency VARCHAR,
+    dst_rate NUMERIC(18, 8),
+    transaction_key UUID,
+    status VARCHAR NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT date_trunc('second', NOW())
+);
+
+CREATE INDEX transactions_src_wallet_id_idx ON transactions (src_wallet_id, created_at);
+CREATE INDEX transactions_dst_wallet_id_idx ON transactions (dst_wallet_id, created_at);
+CREATE INDEX transactions_transaction_key_idx ON transactions (transaction_key);
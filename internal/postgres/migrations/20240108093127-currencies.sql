@@ -0,0 +1,52 @@
+-- +migrate Up
+CREATE TABLE currencies (
+    code VARCHAR(3) NOT NULL PRIMARY KEY CHECK (code ~ '^[A-Z]{3}$'),
+    minor_units SMALLINT NOT NULL CHECK (minor_units BETWEEN 0 AND 3),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    reference_rate NUMERIC(18, 8) NOT NULL CHECK (reference_rate > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT date_trunc('second', NOW()),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT date_trunc('second', NOW())
+);
+
+INSERT INTO currencies (code, minor_units, reference_rate)
+VALUES ('USD', 2, 1), ('EUR', 2, 0.92), ('RUB', 2, 92.5);
+
+ALTER TABLE wallet
+    ALTER COLUMN balance TYPE NUMERIC(13, 3),
+    ADD CONSTRAINT wallet_currency_fkey FOREIGN KEY (currency) REFERENCES currencies (code);
+
+ALTER TABLE history ALTER COLUMN balance TYPE NUMERIC(13, 3);
+
+ALTER TABLE ledger_entries ALTER COLUMN amount TYPE NUMERIC(13, 3);
+
+ALTER TABLE transactions
+    ALTER COLUMN amount TYPE NUMERIC(13, 3),
+    ALTER COLUMN src_amount TYPE NUMERIC(13, 3),
+    ALTER COLUMN dst_amount TYPE NUMERIC(13, 3);
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION check_wallet_ledger()
+RETURNS TRIGGER AS $$
+DECLARE
+    wallet_balance NUMERIC;
+    wallet_currency VARCHAR;
+    ledger_balance NUMERIC;
+BEGIN
+    SELECT balance, currency INTO wallet_balance, wallet_currency
+    FROM wallet
+    WHERE wallet_id = NEW.wallet_id;
+
+    SELECT COALESCE(SUM(CASE WHEN direction = 'CREDIT' THEN amount ELSE -amount END), 0) INTO ledger_balance
+    FROM ledger_entries
+    WHERE wallet_id = NEW.wallet_id
+    AND currency = wallet_currency;
+
+    IF wallet_balance <> ledger_balance THEN
+        RAISE EXCEPTION 'wallet % balance % does not match ledger balance %',
+            NEW.wallet_id, wallet_balance, ledger_balance
+            USING ERRCODE = 'integrity_constraint_violation';
+    END IF;
+RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
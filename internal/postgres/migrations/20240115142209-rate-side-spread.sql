@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE transactions
+    ADD COLUMN src_side VARCHAR CHECK (src_side IN ('BID', 'ASK')),
+    ADD COLUMN src_spread_bps BIGINT,
+    ADD COLUMN dst_side VARCHAR CHECK (dst_side IN ('BID', 'ASK')),
+    ADD COLUMN dst_spread_bps BIGINT;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE transactions
+    ADD COLUMN fee_amount NUMERIC(13, 3) CHECK (fee_amount > 0),
+    ADD COLUMN fee_currency VARCHAR,
+    ADD COLUMN fee_wallet_id UUID;
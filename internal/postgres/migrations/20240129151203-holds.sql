@@ -0,0 +1,26 @@
+-- +migrate Up
+ALTER TABLE wallet
+    ADD COLUMN held NUMERIC(13, 3) NOT NULL DEFAULT 0 CHECK (held >= 0),
+    ADD CONSTRAINT wallet_available_balance_non_negative CHECK (balance - held >= 0);
+
+CREATE TABLE holds (
+    hold_id UUID NOT NULL PRIMARY KEY,
+    wallet_id UUID NOT NULL,
+    amount NUMERIC(13, 3) NOT NULL CHECK (amount > 0),
+    currency VARCHAR NOT NULL,
+    held_amount NUMERIC(13, 3) NOT NULL CHECK (held_amount > 0),
+    held_currency VARCHAR NOT NULL,
+    rate NUMERIC(18, 8),
+    side VARCHAR,
+    spread_bps BIGINT,
+    captured_amount NUMERIC(13, 3) NOT NULL DEFAULT 0,
+    transaction_id UUID,
+    transaction_key UUID,
+    status VARCHAR NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT date_trunc('second', NOW()),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT date_trunc('second', NOW())
+);
+
+CREATE INDEX holds_wallet_id_idx ON holds (wallet_id);
+CREATE INDEX holds_expires_at_idx ON holds (expires_at) WHERE status = 'AUTHORIZED';
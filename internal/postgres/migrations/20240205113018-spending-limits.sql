@@ -0,0 +1,16 @@
+-- +migrate Up
+CREATE TABLE currency_spending_limits (
+    currency VARCHAR NOT NULL PRIMARY KEY REFERENCES currencies (code),
+    daily NUMERIC(13, 3) CHECK (daily >= 0),
+    weekly NUMERIC(13, 3) CHECK (weekly >= 0),
+    monthly NUMERIC(13, 3) CHECK (monthly >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT date_trunc('second', NOW())
+);
+
+CREATE TABLE wallet_spending_limits (
+    wallet_id UUID NOT NULL PRIMARY KEY,
+    daily NUMERIC(13, 3) CHECK (daily >= 0),
+    weekly NUMERIC(13, 3) CHECK (weekly >= 0),
+    monthly NUMERIC(13, 3) CHECK (monthly >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT date_trunc('second', NOW())
+);
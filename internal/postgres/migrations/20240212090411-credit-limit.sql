@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE wallet
+    ADD COLUMN credit_limit NUMERIC(13, 3) NOT NULL DEFAULT 0 CHECK (credit_limit >= 0),
+    DROP CONSTRAINT wallet_balance_non_negative,
+    DROP CONSTRAINT wallet_available_balance_non_negative,
+    ADD CONSTRAINT wallet_balance_within_credit_limit CHECK (balance + credit_limit >= 0),
+    ADD CONSTRAINT wallet_available_balance_within_credit_limit CHECK (balance - held + credit_limit >= 0);
@@ -0,0 +1,51 @@
+-- +migrate Up
+CREATE TABLE wallet_balances (
+    wallet_id UUID NOT NULL,
+    currency VARCHAR NOT NULL REFERENCES currencies (code),
+    balance NUMERIC(13, 3) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT date_trunc('second', NOW()),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT date_trunc('second', NOW()),
+    PRIMARY KEY (wallet_id, currency),
+    CONSTRAINT sub_balance_non_negative CHECK (balance >= 0)
+);
+
+INSERT INTO wallet_balances (wallet_id, currency, balance)
+SELECT l.wallet_id, l.currency, SUM(CASE WHEN l.direction = 'CREDIT' THEN l.amount ELSE -l.amount END)
+FROM ledger_entries l
+JOIN wallet w ON w.wallet_id = l.wallet_id
+WHERE l.currency <> w.currency
+GROUP BY l.wallet_id, l.currency
+HAVING SUM(CASE WHEN l.direction = 'CREDIT' THEN l.amount ELSE -l.amount END) <> 0;
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION check_sub_balance_ledger()
+RETURNS TRIGGER AS $$
+DECLARE
+    sub_balance NUMERIC;
+    ledger_balance NUMERIC;
+BEGIN
+    SELECT balance INTO sub_balance
+    FROM wallet_balances
+    WHERE wallet_id = NEW.wallet_id
+    AND currency = NEW.currency;
+
+    SELECT COALESCE(SUM(CASE WHEN direction = 'CREDIT' THEN amount ELSE -amount END), 0) INTO ledger_balance
+    FROM ledger_entries
+    WHERE wallet_id = NEW.wallet_id
+    AND currency = NEW.currency;
+
+    IF sub_balance <> ledger_balance THEN
+        RAISE EXCEPTION 'wallet % % sub-balance % does not match ledger balance %',
+            NEW.wallet_id, NEW.currency, sub_balance, ledger_balance
+            USING ERRCODE = 'integrity_constraint_violation';
+    END IF;
+RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+CREATE CONSTRAINT TRIGGER sub_balance_ledger_trigger
+    AFTER INSERT OR UPDATE ON wallet_balances
+        DEFERRABLE INITIALLY DEFERRED
+        FOR EACH ROW
+        EXECUTE FUNCTION check_sub_balance_ledger();
@@ -0,0 +1,76 @@
+-- +migrate Up
+ALTER TABLE wallet
+    ADD COLUMN status VARCHAR NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'FROZEN', 'CLOSED')),
+    ADD COLUMN status_reason VARCHAR;
+
+UPDATE wallet SET status = 'CLOSED' WHERE deleted = TRUE;
+
+ALTER TABLE wallet ADD CONSTRAINT wallet_closed_is_deleted CHECK (deleted = (status = 'CLOSED'));
+
+ALTER TABLE history
+    ADD COLUMN status VARCHAR NOT NULL DEFAULT 'ACTIVE',
+    ADD COLUMN reason VARCHAR;
+
+UPDATE history SET status = 'CLOSED' WHERE operation_type = 'DELETE';
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION log_history()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO history (wallet_id, email, owner, balance, currency, created_at, operation_type, status)
+        VALUES (NEW.wallet_id, NEW.email, NEW.owner, NEW.balance, NEW.currency, date_trunc('second', NOW()), 'CREATE',
+                NEW.status);
+    ELSIF TG_OP = 'UPDATE' AND OLD.status = NEW.status THEN
+        INSERT INTO history (wallet_id, email, owner, balance, currency, created_at, operation_type, status)
+        VALUES (NEW.wallet_id, NEW.email, NEW.owner, NEW.balance, NEW.currency, date_trunc('second', NOW()), 'UPDATE',
+                NEW.status);
+END IF;
+RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION log_deleted_wallet()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO history (wallet_id, email, owner, balance, currency, created_at, operation_type, status, reason)
+    VALUES (OLD.wallet_id, OLD.email, OLD.owner, OLD.balance, OLD.currency, date_trunc('second', NOW()), 'DELETE',
+            NEW.status, NEW.status_reason);
+RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION log_mailed_wallet()
+    RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO history (wallet_id, email, owner, balance, currency, created_at, operation_type, status)
+    VALUES (OLD.wallet_id, OLD.email, OLD.owner, OLD.balance, OLD.currency, date_trunc('second', NOW()), 'MAIL',
+            NEW.status);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION log_status_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO history (wallet_id, email, owner, balance, currency, created_at, operation_type, status, reason)
+    VALUES (NEW.wallet_id, NEW.email, NEW.owner, NEW.balance, NEW.currency, date_trunc('second', NOW()), 'STATUS',
+            NEW.status, NEW.status_reason);
+RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+DROP TRIGGER delete_trigger ON wallet;
+
+CREATE TRIGGER delete_trigger
+    AFTER UPDATE ON wallet
+        FOR EACH ROW
+        WHEN ( OLD.deleted=FALSE AND NEW.deleted=TRUE)
+        EXECUTE FUNCTION log_deleted_wallet();
+
+CREATE TRIGGER status_trigger
+    AFTER UPDATE ON wallet
+        FOR EACH ROW
+        WHEN ( OLD.status <> NEW.status AND NEW.deleted=FALSE)
+        EXECUTE FUNCTION log_status_change();
@@ -0,0 +1,16 @@
+-- +migrate Up
+ALTER TABLE wallet
+    ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN purged_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE wallet SET deleted_at = updated_at WHERE deleted = TRUE;
+
+ALTER TABLE wallet
+    ADD CONSTRAINT wallet_deleted_at CHECK (deleted = (deleted_at IS NOT NULL)),
+    ADD CONSTRAINT wallet_purged_is_deleted CHECK (purged_at IS NULL OR deleted = TRUE);
+
+ALTER TABLE wallet DROP CONSTRAINT wallet_email_key;
+
+CREATE UNIQUE INDEX wallet_email_key ON wallet (email) WHERE deleted = FALSE;
+
+CREATE INDEX wallet_deleted_at_idx ON wallet (deleted_at) WHERE purged_at IS NULL;
@@ -0,0 +1,29 @@
+-- +migrate Up
+CREATE TABLE standing_orders (
+    order_id UUID NOT NULL PRIMARY KEY,
+    src_wallet_id UUID NOT NULL,
+    dst_wallet_id UUID NOT NULL,
+    amount NUMERIC(13, 3) NOT NULL CHECK (amount > 0),
+    currency VARCHAR NOT NULL REFERENCES currencies (code),
+    frequency VARCHAR NOT NULL CHECK (frequency IN ('ONCE', 'WEEKLY', 'MONTHLY')),
+    start_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    runs INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'COMPLETED', 'CANCELLED')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT date_trunc('second', NOW()),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT date_trunc('second', NOW())
+);
+
+CREATE INDEX standing_orders_src_wallet_id_idx ON standing_orders (src_wallet_id);
+CREATE INDEX standing_orders_next_run_at_idx ON standing_orders (next_run_at) WHERE status = 'ACTIVE';
+
+CREATE TABLE standing_order_executions (
+    order_id UUID NOT NULL,
+    scheduled_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    transaction_key UUID NOT NULL,
+    transaction_id UUID,
+    status VARCHAR NOT NULL CHECK (status IN ('SUCCEEDED', 'FAILED')),
+    error VARCHAR,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (order_id, scheduled_at)
+);
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE transactions
+    ADD COLUMN reversal_of UUID,
+    ADD COLUMN reversed_amount NUMERIC(13, 3) NOT NULL DEFAULT 0,
+    ADD CONSTRAINT transactions_reversed_amount CHECK (reversed_amount >= 0 AND reversed_amount <= amount);
+
+CREATE INDEX transactions_reversal_of_idx ON transactions (reversal_of);
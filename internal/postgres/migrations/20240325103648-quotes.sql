@@ -0,0 +1,16 @@
+-- +migrate Up
+CREATE TABLE quotes (
+    quote_id UUID NOT NULL PRIMARY KEY,
+    currency VARCHAR NOT NULL,
+    amount NUMERIC(13, 3) NOT NULL CHECK (amount > 0),
+    target_currency VARCHAR NOT NULL,
+    converted_amount NUMERIC(13, 3) NOT NULL CHECK (converted_amount >= 0),
+    rate NUMERIC(18, 8) NOT NULL,
+    side VARCHAR NOT NULL CHECK (side IN ('BID', 'ASK')),
+    spread_bps BIGINT NOT NULL,
+    transaction_id UUID,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT date_trunc('second', NOW())
+);
+
+CREATE INDEX quotes_expires_at_idx ON quotes (expires_at);
@@ -0,0 +1,4 @@
+-- +migrate Up
+ALTER TABLE wallet ADD COLUMN owner_id VARCHAR;
+
+CREATE INDEX wallet_owner_id_idx ON wallet (owner_id);
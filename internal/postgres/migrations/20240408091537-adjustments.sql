@@ -0,0 +1,2 @@
+-- +migrate Up
+ALTER TABLE transactions ADD COLUMN reason VARCHAR;
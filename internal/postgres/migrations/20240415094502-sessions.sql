@@ -0,0 +1,23 @@
+-- +migrate Up
+CREATE TABLE sessions (
+    session_id UUID NOT NULL PRIMARY KEY,
+    subject VARCHAR NOT NULL,
+    email VARCHAR NOT NULL DEFAULT '',
+    roles VARCHAR[] NOT NULL DEFAULT '{}',
+    permissions VARCHAR[] NOT NULL DEFAULT '{}',
+    refresh_token_hash VARCHAR NOT NULL,
+    access_token_id UUID NOT NULL,
+    access_expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT date_trunc('second', NOW())
+);
+
+CREATE INDEX sessions_subject_idx ON sessions (subject);
+
+CREATE TABLE revoked_tokens (
+    token_id VARCHAR NOT NULL PRIMARY KEY,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX revoked_tokens_expires_at_idx ON revoked_tokens (expires_at);
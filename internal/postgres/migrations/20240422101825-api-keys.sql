@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE api_keys (
+    key_id UUID NOT NULL PRIMARY KEY,
+    name VARCHAR NOT NULL,
+    key_hash VARCHAR NOT NULL UNIQUE,
+    scopes VARCHAR[] NOT NULL,
+    wallet_ids UUID[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_by VARCHAR NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT date_trunc('second', NOW())
+);
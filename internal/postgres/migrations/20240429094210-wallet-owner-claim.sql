@@ -0,0 +1,19 @@
+-- +migrate Up
+-- Wallets created before owners were recorded keep a NULL owner_id. Such a wallet is listed for every
+-- subject whose token carries the wallet's email, and the first of them to use the wallet becomes its
+-- owner. Claiming a wallet is not a change of the wallet, so it is kept out of the wallet history.
+DROP TRIGGER history_trigger ON wallet;
+
+CREATE TRIGGER history_trigger
+    AFTER INSERT ON wallet
+        FOR EACH ROW
+        WHEN ( NEW.deleted=FALSE AND NEW.inactive_mailed=FALSE)
+        EXECUTE FUNCTION log_history();
+
+CREATE TRIGGER history_update_trigger
+    AFTER UPDATE ON wallet
+        FOR EACH ROW
+        WHEN ( NEW.deleted=FALSE AND NEW.inactive_mailed=FALSE AND OLD.owner_id IS NOT DISTINCT FROM NEW.owner_id)
+        EXECUTE FUNCTION log_history();
+
+CREATE INDEX wallet_unowned_email_idx ON wallet (lower(email)) WHERE owner_id IS NULL;
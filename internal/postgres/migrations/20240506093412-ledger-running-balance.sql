@@ -0,0 +1,73 @@
+-- +migrate Up
+CREATE TABLE ledger_balances (
+    wallet_id UUID NOT NULL,
+    currency VARCHAR NOT NULL,
+    balance NUMERIC(13, 3) NOT NULL,
+    PRIMARY KEY (wallet_id, currency)
+);
+
+INSERT INTO ledger_balances (wallet_id, currency, balance)
+SELECT wallet_id, currency, SUM(CASE WHEN direction = 'CREDIT' THEN amount ELSE -amount END)
+FROM ledger_entries
+WHERE wallet_id IS NOT NULL
+GROUP BY wallet_id, currency;
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION add_ledger_balance()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO ledger_balances (wallet_id, currency, balance)
+    VALUES (NEW.wallet_id, NEW.currency, CASE WHEN NEW.direction = 'CREDIT' THEN NEW.amount ELSE -NEW.amount END)
+    ON CONFLICT (wallet_id, currency) DO UPDATE
+    SET balance = ledger_balances.balance + EXCLUDED.balance;
+RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION check_wallet_ledger()
+RETURNS TRIGGER AS $$
+DECLARE
+    wallet_balance NUMERIC;
+    ledger_balance NUMERIC;
+BEGIN
+    SELECT w.balance, COALESCE(l.balance, 0) INTO wallet_balance, ledger_balance
+    FROM wallet w
+    LEFT JOIN ledger_balances l ON l.wallet_id = w.wallet_id AND l.currency = w.currency
+    WHERE w.wallet_id = NEW.wallet_id;
+
+    IF wallet_balance <> ledger_balance THEN
+        RAISE EXCEPTION 'wallet % balance % does not match ledger balance %',
+            NEW.wallet_id, wallet_balance, ledger_balance
+            USING ERRCODE = 'integrity_constraint_violation';
+    END IF;
+RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION check_sub_balance_ledger()
+RETURNS TRIGGER AS $$
+DECLARE
+    sub_balance NUMERIC;
+    ledger_balance NUMERIC;
+BEGIN
+    SELECT b.balance, COALESCE(l.balance, 0) INTO sub_balance, ledger_balance
+    FROM wallet_balances b
+    LEFT JOIN ledger_balances l ON l.wallet_id = b.wallet_id AND l.currency = b.currency
+    WHERE b.wallet_id = NEW.wallet_id
+    AND b.currency = NEW.currency;
+
+    IF sub_balance <> ledger_balance THEN
+        RAISE EXCEPTION 'wallet % % sub-balance % does not match ledger balance %',
+            NEW.wallet_id, NEW.currency, sub_balance, ledger_balance
+            USING ERRCODE = 'integrity_constraint_violation';
+    END IF;
+RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+CREATE TRIGGER ledger_entry_balance_trigger
+    AFTER INSERT ON ledger_entries
+        FOR EACH ROW
+        WHEN ( NEW.wallet_id IS NOT NULL)
+        EXECUTE FUNCTION add_ledger_balance();
@@ -4,9 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"embed"
+	"errors"
 	"fmt"
+	"net"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 	migrate "github.com/rubenv/sql-migrate"
 	"github.com/sirupsen/logrus"
 )
@@ -15,15 +19,15 @@ import (
 var migrations embed.FS
 
 type Postgres struct {
-	db  *pgx.Conn
+	db  *pgxpool.Pool
 	log *logrus.Entry
 	dsn string
 }
 
 func ConnectDB(ctx context.Context, log *logrus.Logger, dsn string) (*Postgres, error) {
-	db, err := pgx.Connect(ctx, dsn)
+	db, err := pgxpool.New(ctx, dsn)
 	if err != nil {
-		return nil, fmt.Errorf("pgx.Connect: %w", err)
+		return nil, fmt.Errorf("pgxpool.New: %w", err)
 	}
 
 	err = db.Ping(ctx)
@@ -38,6 +42,31 @@ func ConnectDB(ctx context.Context, log *logrus.Logger, dsn string) (*Postgres,
 	}, nil
 }
 
+// IsTransient reports whether the request failed for a reason that may pass on its own, such as a lost
+// connection, a deadlock or a concurrent change, so that it is worth retrying as it is.
+func IsTransient(err error) bool {
+	if errors.Is(err, ErrConcurrentUpdate) || pgconn.SafeToRetry(err) || pgconn.Timeout(err) {
+		return true
+	}
+
+	var netErr net.Error
+
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) {
+		code := pgErr.SQLState()
+
+		return pgerrcode.IsConnectionException(code) || pgerrcode.IsTransactionRollback(code) ||
+			pgerrcode.IsInsufficientResources(code) || pgerrcode.IsOperatorIntervention(code)
+	}
+
+	return false
+}
+
 func (p *Postgres) Migrate(direction migrate.MigrationDirection) error {
 	conn, err := sql.Open("pgx", p.dsn)
 	if err != nil {
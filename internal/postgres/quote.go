@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	walletmodel "github.com/AlexZav1327/service/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	insertQuoteQuery = `
+	INSERT INTO quotes (quote_id, currency, amount, target_currency, converted_amount, rate, side, spread_bps,
+		expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING quote_id, currency, amount, target_currency, converted_amount, rate, side, spread_bps, transaction_id,
+		expires_at, created_at;
+	`
+	getQuoteQuery = `
+	SELECT quote_id, currency, amount, target_currency, converted_amount, rate, side, spread_bps, transaction_id,
+		expires_at, created_at
+	FROM quotes
+	WHERE quote_id = $1;
+	`
+	lockQuoteQuery = `
+	SELECT transaction_id IS NOT NULL, expires_at <= now()
+	FROM quotes
+	WHERE quote_id = $1
+	FOR UPDATE;
+	`
+	useQuoteQuery = `
+	UPDATE quotes
+	SET transaction_id = $2
+	WHERE quote_id = $1;
+	`
+	deleteExpiredQuotesQuery = `
+	DELETE FROM quotes
+	WHERE expires_at < $1;
+	`
+)
+
+var (
+	ErrQuoteNotFound  = errors.New("no such quote")
+	ErrInvalidQuoteID = errors.New("invalid quoteID for type uuid")
+	ErrQuoteUsed      = errors.New("quote was already used")
+	ErrQuoteExpired   = errors.New("quote has expired")
+)
+
+func (p *Postgres) CreateQuote(ctx context.Context, quote walletmodel.Quote) (walletmodel.Quote, error) {
+	createdQuote, err := scanQuote(p.db.QueryRow(
+		ctx,
+		insertQuoteQuery,
+		quote.QuoteID,
+		quote.Currency,
+		quote.Amount,
+		quote.TargetCurrency,
+		quote.ConvertedAmount,
+		quote.Rate,
+		quote.Side,
+		quote.SpreadBps,
+		quote.ExpiresAt,
+	))
+	if err != nil {
+		return walletmodel.Quote{}, fmt.Errorf("scanQuote: %w", err)
+	}
+
+	return createdQuote, nil
+}
+
+func (p *Postgres) GetQuote(ctx context.Context, id string) (walletmodel.Quote, error) {
+	quote, err := scanQuote(p.db.QueryRow(ctx, getQuoteQuery, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return walletmodel.Quote{}, ErrQuoteNotFound
+		}
+
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) && pgerrcode.InvalidTextRepresentation == pgErr.SQLState() {
+			return walletmodel.Quote{}, ErrInvalidQuoteID
+		}
+
+		return walletmodel.Quote{}, fmt.Errorf("scanQuote: %w", err)
+	}
+
+	return quote, nil
+}
+
+func (p *Postgres) DeleteExpiredQuotes(ctx context.Context, retention time.Duration) (int64, error) {
+	commandTag, err := p.db.Exec(ctx, deleteExpiredQuotesQuery, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("db.Exec: %w", err)
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
+// useQuote ties the quote to the transaction booked at its rate. A quote serves one transaction and
+// only until it expires, by the database clock. Callers check the idempotency key first, so a replayed
+// request gets its stored response even after its quote was used or has expired.
+func (*Postgres) useQuote(ctx context.Context, q querier, quoteID, transactionID uuid.UUID) error {
+	var used, expired bool
+
+	err := q.QueryRow(ctx, lockQuoteQuery, quoteID).Scan(&used, &expired)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrQuoteNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("row.Scan: %w", err)
+	}
+
+	if used {
+		return ErrQuoteUsed
+	}
+
+	if expired {
+		return ErrQuoteExpired
+	}
+
+	_, err = q.Exec(ctx, useQuoteQuery, quoteID, transactionID)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func scanQuote(row pgx.Row) (walletmodel.Quote, error) {
+	var quote walletmodel.Quote
+
+	err := row.Scan(
+		&quote.QuoteID,
+		&quote.Currency,
+		&quote.Amount,
+		&quote.TargetCurrency,
+		&quote.ConvertedAmount,
+		&quote.Rate,
+		&quote.Side,
+		&quote.SpreadBps,
+		&quote.TransactionID,
+		&quote.ExpiresAt,
+		&quote.Created,
+	)
+	if err != nil {
+		return walletmodel.Quote{}, fmt.Errorf("row.Scan: %w", err)
+	}
+
+	return quote, nil
+}
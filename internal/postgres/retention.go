@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	walletmodel "github.com/AlexZav1327/service/internal/models"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	restoreWalletQuery = `
+	UPDATE wallet
+	SET status = 'ACTIVE', status_reason = $2, deleted = FALSE, deleted_at = NULL
+	WHERE wallet_id = $1
+	AND deleted = TRUE
+	AND purged_at IS NULL
+	AND deleted_at > $3
+	RETURNING ` + walletColumns + `;
+	`
+	purgeWalletsQuery = `
+	WITH purged AS (
+		UPDATE wallet
+		SET email = '', owner = '', status_reason = NULL, purged_at = now()
+		WHERE deleted = TRUE
+		AND purged_at IS NULL
+		AND deleted_at <= $1
+		RETURNING wallet_id
+	), anonymized AS (
+		UPDATE history
+		SET email = '', owner = '', reason = NULL
+		WHERE wallet_id IN (SELECT wallet_id FROM purged)
+	), limits AS (
+		DELETE FROM wallet_spending_limits
+		WHERE wallet_id IN (SELECT wallet_id FROM purged)
+	)
+	SELECT count(*) FROM purged;
+	`
+)
+
+// RestoreWallet reopens a closed wallet deleted after deletedAfter. It fails with ErrEmailNotUnique when
+// another wallet has taken the email meanwhile.
+func (p *Postgres) RestoreWallet(ctx context.Context, id, reason string, deletedAfter time.Time) (
+	walletmodel.ResponseWalletInstance, error,
+) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("db.Begin: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				p.log.Warningf("tx.Rollback: %s", err)
+			}
+		}
+	}()
+
+	wallet, err := p.queryRowToWallet(ctx, tx, restoreWalletQuery, id, reason, deletedAfter)
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgerrcode.UniqueViolation == pgErr.SQLState() {
+				err = ErrEmailNotUnique
+			}
+
+			if pgerrcode.InvalidTextRepresentation == pgErr.SQLState() {
+				err = ErrInvalidWalletID
+			}
+		}
+
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("queryRowToWallet: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// PurgeDeletedWallets anonymizes the wallets deleted longer than retention ago, together with their
+// history. The rows themselves are kept, as the ledger still refers to them.
+func (p *Postgres) PurgeDeletedWallets(ctx context.Context, retention time.Duration) (int64, error) {
+	var purged int64
+
+	err := p.db.QueryRow(ctx, purgeWalletsQuery, time.Now().Add(-retention)).Scan(&purged)
+	if err != nil {
+		return 0, fmt.Errorf("row.Scan: %w", err)
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	walletmodel "github.com/AlexZav1327/service/internal/models"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	getTransactionEntriesQuery = `
+	SELECT account, wallet_id, direction, amount, currency
+	FROM ledger_entries
+	WHERE transaction_id = $1
+	ORDER BY entry_id;
+	`
+	getReversedEntriesQuery = `
+	SELECT e.account, e.wallet_id, e.direction, SUM(e.amount), e.currency
+	FROM ledger_entries e
+	JOIN transactions t ON t.transaction_id = e.transaction_id
+	WHERE t.reversal_of = $1
+	GROUP BY e.account, e.wallet_id, e.direction, e.currency;
+	`
+	lockTransactionQuery = `
+	SELECT transaction_id, operation_type, amount, currency, src_wallet_id, src_amount, src_currency, src_rate,
+		src_side, src_spread_bps, dst_wallet_id, dst_amount, dst_currency, dst_rate, dst_side, dst_spread_bps,
+		fee_amount, fee_currency, fee_wallet_id, transaction_key, status, reversal_of, reversed_amount, reason, created_at
+	FROM transactions
+	WHERE transaction_id = $1
+	FOR UPDATE;
+	`
+	setReversedAmountQuery = `
+	UPDATE transactions
+	SET reversed_amount = $2, status = $3
+	WHERE transaction_id = $1
+	RETURNING transaction_id, operation_type, amount, currency, src_wallet_id, src_amount, src_currency, src_rate,
+		src_side, src_spread_bps, dst_wallet_id, dst_amount, dst_currency, dst_rate, dst_side, dst_spread_bps,
+		fee_amount, fee_currency, fee_wallet_id, transaction_key, status, reversal_of, reversed_amount, reason, created_at;
+	`
+)
+
+var ErrTransactionReversed = errors.New("transaction is already reversed")
+
+func (p *Postgres) GetTransactionEntries(ctx context.Context, id string) ([]walletmodel.LedgerEntry, error) {
+	return p.queryLedgerEntries(ctx, getTransactionEntriesQuery, id)
+}
+
+// GetReversedEntries returns what the reversals of the transaction have booked so far, summed per account,
+// wallet, direction and currency.
+func (p *Postgres) GetReversedEntries(ctx context.Context, id string) ([]walletmodel.LedgerEntry, error) {
+	return p.queryLedgerEntries(ctx, getReversedEntriesQuery, id)
+}
+
+func (p *Postgres) queryLedgerEntries(ctx context.Context, query, id string) ([]walletmodel.LedgerEntry, error) {
+	rows, err := p.db.Query(ctx, query, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) && pgerrcode.InvalidTextRepresentation == pgErr.SQLState() {
+			return nil, ErrInvalidTransactionID
+		}
+
+		return nil, fmt.Errorf("db.Query: %w", err)
+	}
+
+	defer rows.Close()
+
+	entries := make([]walletmodel.LedgerEntry, 0)
+
+	for rows.Next() {
+		var entry walletmodel.LedgerEntry
+
+		err = rows.Scan(&entry.Account, &entry.WalletID, &entry.Direction, &entry.Amount, &entry.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	return entries, nil
+}
+
+// ReverseTransaction books the compensating transaction and adds its amount to what has been reversed of
+// the original. The original is locked first, so two reversals cannot undo more than it moved, and the
+// reversal fails with ErrConcurrentUpdate when another one was booked after its entries were shared out.
+func (p *Postgres) ReverseTransaction(ctx context.Context, key walletmodel.IdempotencyKey,
+	txn walletmodel.LedgerTransaction,
+) (walletmodel.ResponseReversal, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return walletmodel.ResponseReversal{}, fmt.Errorf("db.Begin: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				p.log.Warningf("tx.Rollback: %s", err)
+			}
+		}
+	}()
+
+	var response walletmodel.ResponseReversal
+
+	replayed, err := p.idempotency(ctx, tx, key, &response)
+	if err != nil {
+		return walletmodel.ResponseReversal{}, fmt.Errorf("idempotency: %w", err)
+	}
+
+	if replayed {
+		err = tx.Commit(ctx)
+		if err != nil {
+			return walletmodel.ResponseReversal{}, fmt.Errorf("tx.Commit: %w", err)
+		}
+
+		return response, nil
+	}
+
+	original, err := scanTransaction(tx.QueryRow(ctx, lockTransactionQuery, txn.ReversalOf.UUID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrTransactionNotFound
+		}
+
+		return walletmodel.ResponseReversal{}, fmt.Errorf("scanTransaction: %w", err)
+	}
+
+	if original.ReversedAmount != txn.ReversedBefore {
+		err = ErrConcurrentUpdate
+
+		return walletmodel.ResponseReversal{}, err
+	}
+
+	reversed, err := original.ReversedAmount.Add(txn.Amount)
+	if err != nil {
+		return walletmodel.ResponseReversal{}, fmt.Errorf("Add: %w", err)
+	}
+
+	if reversed.Cmp(original.Amount) > 0 {
+		err = ErrTransactionReversed
+
+		return walletmodel.ResponseReversal{}, err
+	}
+
+	_, err = p.postTransaction(ctx, tx, txn)
+	if err != nil {
+		return walletmodel.ResponseReversal{}, fmt.Errorf("postTransaction: %w", err)
+	}
+
+	status := walletmodel.TransactionPartiallyReversed
+	if reversed == original.Amount {
+		status = walletmodel.TransactionReversed
+	}
+
+	response.Original, err = scanTransaction(tx.QueryRow(ctx, setReversedAmountQuery, original.TransactionID,
+		reversed, status))
+	if err != nil {
+		return walletmodel.ResponseReversal{}, fmt.Errorf("scanTransaction: %w", err)
+	}
+
+	response.Reversal, err = scanTransaction(tx.QueryRow(ctx, getTransactionQuery, txn.TransactionID))
+	if err != nil {
+		return walletmodel.ResponseReversal{}, fmt.Errorf("scanTransaction: %w", err)
+	}
+
+	err = p.saveIdempotentResponse(ctx, tx, key, response)
+	if err != nil {
+		return walletmodel.ResponseReversal{}, fmt.Errorf("saveIdempotentResponse: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return walletmodel.ResponseReversal{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,293 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	walletmodel "github.com/AlexZav1327/service/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	insertSessionQuery = `
+	INSERT INTO sessions (session_id, subject, email, roles, permissions, refresh_token_hash, access_token_id,
+		access_expires_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING session_id, subject, email, roles, permissions, access_token_id, access_expires_at, expires_at,
+		revoked_at, created_at;
+	`
+	getSessionForUpdateQuery = `
+	SELECT session_id, subject, email, roles, permissions, access_token_id, access_expires_at, expires_at,
+		revoked_at, created_at, refresh_token_hash
+	FROM sessions
+	WHERE session_id = $1
+	FOR UPDATE;
+	`
+	rotateSessionQuery = `
+	UPDATE sessions
+	SET refresh_token_hash = $2, access_token_id = $3, access_expires_at = $4
+	WHERE session_id = $1
+	RETURNING session_id, subject, email, roles, permissions, access_token_id, access_expires_at, expires_at,
+		revoked_at, created_at;
+	`
+	revokeSessionsQuery = `
+	UPDATE sessions
+	SET revoked_at = now()
+	WHERE subject = $1
+	AND revoked_at IS NULL
+	`
+	insertRevokedTokenQuery = `
+	INSERT INTO revoked_tokens (token_id, expires_at)
+	VALUES ($1, $2)
+	ON CONFLICT (token_id) DO NOTHING;
+	`
+	isTokenRevokedQuery = `
+	SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE token_id = $1);
+	`
+	deleteExpiredSessionsQuery = `
+	DELETE FROM sessions
+	WHERE expires_at < $1;
+	`
+	deleteExpiredRevokedTokensQuery = `
+	DELETE FROM revoked_tokens
+	WHERE expires_at < now();
+	`
+)
+
+var (
+	ErrSessionNotFound    = errors.New("no such session")
+	ErrSessionRevoked     = errors.New("session was revoked or has expired")
+	ErrRefreshTokenReused = errors.New("refresh token was already used")
+)
+
+func (p *Postgres) CreateSession(ctx context.Context, session walletmodel.Session, refreshTokenHash string) (
+	walletmodel.Session, error,
+) {
+	createdSession, err := scanSession(p.db.QueryRow(
+		ctx,
+		insertSessionQuery,
+		session.SessionID,
+		session.Subject,
+		session.Email,
+		session.Roles,
+		session.Permissions,
+		refreshTokenHash,
+		session.AccessTokenID,
+		session.AccessExpiresAt,
+		session.ExpiresAt,
+	))
+	if err != nil {
+		return walletmodel.Session{}, fmt.Errorf("scanSession: %w", err)
+	}
+
+	return createdSession, nil
+}
+
+// RotateSession swaps the refresh token of a live session for a new one and revokes the access token
+// issued with the old one. A refresh token that is no longer current has been used before, so the
+// session is revoked: either the client or whoever replayed the token holds a stolen copy. The session
+// keeps the expiry it was opened with.
+func (p *Postgres) RotateSession(ctx context.Context, id uuid.UUID, refreshTokenHash, newRefreshTokenHash string,
+	access walletmodel.AccessToken,
+) (walletmodel.Session, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return walletmodel.Session{}, fmt.Errorf("db.Begin: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				p.log.Warningf("tx.Rollback: %s", err)
+			}
+		}
+	}()
+
+	var currentHash string
+
+	session, err := scanSession(tx.QueryRow(ctx, getSessionForUpdateQuery, id), &currentHash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return walletmodel.Session{}, ErrSessionNotFound
+	}
+
+	if err != nil {
+		return walletmodel.Session{}, fmt.Errorf("scanSession: %w", err)
+	}
+
+	if session.RevokedAt != nil || !session.ExpiresAt.After(time.Now()) {
+		err = ErrSessionRevoked
+
+		return walletmodel.Session{}, err
+	}
+
+	if currentHash != refreshTokenHash {
+		err = p.revokeSessions(ctx, tx, session.Subject, uuid.NullUUID{UUID: id, Valid: true})
+		if err != nil {
+			return walletmodel.Session{}, fmt.Errorf("revokeSessions: %w", err)
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			return walletmodel.Session{}, fmt.Errorf("tx.Commit: %w", err)
+		}
+
+		return walletmodel.Session{}, ErrRefreshTokenReused
+	}
+
+	_, err = tx.Exec(ctx, insertRevokedTokenQuery, session.AccessTokenID.String(), session.AccessExpiresAt)
+	if err != nil {
+		return walletmodel.Session{}, fmt.Errorf("tx.Exec: %w", err)
+	}
+
+	rotatedSession, err := scanSession(tx.QueryRow(ctx, rotateSessionQuery, id, newRefreshTokenHash,
+		access.TokenID, access.ExpiresAt))
+	if err != nil {
+		return walletmodel.Session{}, fmt.Errorf("scanSession: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return walletmodel.Session{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return rotatedSession, nil
+}
+
+// RevokeSessions revokes the live sessions of subject, or only sessionID when it is set, together with
+// their current access tokens.
+func (p *Postgres) RevokeSessions(ctx context.Context, subject string, sessionID uuid.NullUUID) error {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("db.Begin: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				p.log.Warningf("tx.Rollback: %s", err)
+			}
+		}
+	}()
+
+	err = p.revokeSessions(ctx, tx, subject, sessionID)
+	if err != nil {
+		return fmt.Errorf("revokeSessions: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (*Postgres) revokeSessions(ctx context.Context, tx pgx.Tx, subject string, sessionID uuid.NullUUID) error {
+	query := revokeSessionsQuery
+	args := []interface{}{subject}
+
+	if sessionID.Valid {
+		query += " AND session_id = $2"
+
+		args = append(args, sessionID.UUID)
+	}
+
+	rows, err := tx.Query(ctx, query+" RETURNING access_token_id, access_expires_at;", args...)
+	if err != nil {
+		return fmt.Errorf("tx.Query: %w", err)
+	}
+
+	var tokens []walletmodel.AccessToken
+
+	for rows.Next() {
+		var token walletmodel.AccessToken
+
+		err = rows.Scan(&token.TokenID, &token.ExpiresAt)
+		if err != nil {
+			rows.Close()
+
+			return fmt.Errorf("rows.Scan: %w", err)
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	rows.Close()
+
+	err = rows.Err()
+	if err != nil {
+		return fmt.Errorf("rows.Err: %w", err)
+	}
+
+	for _, token := range tokens {
+		_, err = tx.Exec(ctx, insertRevokedTokenQuery, token.TokenID.String(), token.ExpiresAt)
+		if err != nil {
+			return fmt.Errorf("tx.Exec: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (p *Postgres) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	_, err := p.db.Exec(ctx, insertRevokedTokenQuery, tokenID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("db.Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var revoked bool
+
+	err := p.db.QueryRow(ctx, isTokenRevokedQuery, tokenID).Scan(&revoked)
+	if err != nil {
+		return false, fmt.Errorf("row.Scan: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// DeleteExpiredSessions removes sessions that expired more than retention ago, and revoked tokens that
+// have expired anyway.
+func (p *Postgres) DeleteExpiredSessions(ctx context.Context, retention time.Duration) (int64, error) {
+	_, err := p.db.Exec(ctx, deleteExpiredRevokedTokensQuery)
+	if err != nil {
+		return 0, fmt.Errorf("db.Exec: %w", err)
+	}
+
+	commandTag, err := p.db.Exec(ctx, deleteExpiredSessionsQuery, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("db.Exec: %w", err)
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
+func scanSession(row pgx.Row, extra ...interface{}) (walletmodel.Session, error) {
+	var session walletmodel.Session
+
+	err := row.Scan(append([]interface{}{
+		&session.SessionID,
+		&session.Subject,
+		&session.Email,
+		&session.Roles,
+		&session.Permissions,
+		&session.AccessTokenID,
+		&session.AccessExpiresAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+		&session.Created,
+	}, extra...)...)
+	if err != nil {
+		return walletmodel.Session{}, fmt.Errorf("row.Scan: %w", err)
+	}
+
+	return session, nil
+}
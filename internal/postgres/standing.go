@@ -0,0 +1,328 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	walletmodel "github.com/AlexZav1327/service/internal/models"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	createStandingOrderQuery = `
+	INSERT INTO standing_orders (order_id, src_wallet_id, dst_wallet_id, amount, currency, frequency, start_at,
+		next_run_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+	RETURNING order_id, src_wallet_id, dst_wallet_id, amount, currency, frequency, start_at, next_run_at, runs,
+		status, created_at, updated_at;
+	`
+	getStandingOrderQuery = `
+	SELECT order_id, src_wallet_id, dst_wallet_id, amount, currency, frequency, start_at, next_run_at, runs,
+		status, created_at, updated_at
+	FROM standing_orders
+	WHERE order_id = $1;
+	`
+	getWalletStandingOrdersQuery = `
+	SELECT order_id, src_wallet_id, dst_wallet_id, amount, currency, frequency, start_at, next_run_at, runs,
+		status, created_at, updated_at
+	FROM standing_orders
+	WHERE src_wallet_id = $1
+	ORDER BY created_at, order_id;
+	`
+	updateStandingOrderQuery = `
+	UPDATE standing_orders
+	SET dst_wallet_id = $2, amount = $3, currency = $4, frequency = $5, start_at = $6, next_run_at = $7,
+		runs = $8, updated_at = now()
+	WHERE order_id = $1
+	AND status = 'ACTIVE'
+	RETURNING order_id, src_wallet_id, dst_wallet_id, amount, currency, frequency, start_at, next_run_at, runs,
+		status, created_at, updated_at;
+	`
+	cancelStandingOrderQuery = `
+	UPDATE standing_orders
+	SET status = 'CANCELLED', updated_at = now()
+	WHERE order_id = $1
+	AND status = 'ACTIVE';
+	`
+	getDueStandingOrdersQuery = `
+	SELECT order_id, src_wallet_id, dst_wallet_id, amount, currency, frequency, start_at, next_run_at, runs,
+		status, created_at, updated_at
+	FROM standing_orders
+	WHERE status = 'ACTIVE'
+	AND next_run_at <= $1
+	ORDER BY next_run_at
+	LIMIT $2;
+	`
+	insertExecutionQuery = `
+	INSERT INTO standing_order_executions (order_id, scheduled_at, transaction_key, transaction_id, status, error)
+	VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+	ON CONFLICT (order_id, scheduled_at) DO NOTHING;
+	`
+	advanceStandingOrderQuery = `
+	UPDATE standing_orders
+	SET next_run_at = $3, runs = runs + 1, status = $4, updated_at = now()
+	WHERE order_id = $1
+	AND next_run_at = $2
+	AND status = 'ACTIVE';
+	`
+	getExecutionsQuery = `
+	SELECT order_id, scheduled_at, transaction_key, transaction_id, status, COALESCE(error, ''), created_at
+	FROM standing_order_executions
+	WHERE order_id = $1
+	ORDER BY scheduled_at DESC;
+	`
+)
+
+var (
+	ErrStandingOrderNotFound  = errors.New("no such standing order")
+	ErrInvalidStandingOrderID = errors.New("invalid standing order ID for type uuid")
+	ErrStandingOrderNotActive = errors.New("standing order is completed or cancelled")
+)
+
+func (p *Postgres) CreateStandingOrder(ctx context.Context, order walletmodel.StandingOrder) (
+	walletmodel.StandingOrder, error,
+) {
+	createdOrder, err := scanStandingOrder(p.db.QueryRow(ctx, createStandingOrderQuery, order.OrderID,
+		order.SrcWalletID, order.DstWalletID, order.Amount, order.Currency, order.Frequency, order.StartAt))
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgerrcode.ForeignKeyViolation == pgErr.SQLState() {
+				return walletmodel.StandingOrder{}, ErrCurrencyNotFound
+			}
+		}
+
+		return walletmodel.StandingOrder{}, fmt.Errorf("scanStandingOrder: %w", err)
+	}
+
+	return createdOrder, nil
+}
+
+func (p *Postgres) GetStandingOrder(ctx context.Context, id string) (walletmodel.StandingOrder, error) {
+	order, err := scanStandingOrder(p.db.QueryRow(ctx, getStandingOrderQuery, id))
+	if err != nil {
+		return walletmodel.StandingOrder{}, standingOrderError(err)
+	}
+
+	return order, nil
+}
+
+func (p *Postgres) GetWalletStandingOrders(ctx context.Context, id string) ([]walletmodel.StandingOrder, error) {
+	rows, err := p.db.Query(ctx, getWalletStandingOrdersQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("db.Query: %w", err)
+	}
+
+	orders, err := scanStandingOrders(rows)
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgerrcode.InvalidTextRepresentation == pgErr.SQLState() {
+				return nil, ErrInvalidWalletID
+			}
+		}
+
+		return nil, fmt.Errorf("scanStandingOrders: %w", err)
+	}
+
+	return orders, nil
+}
+
+// UpdateStandingOrder replaces the terms and the schedule of an active order.
+func (p *Postgres) UpdateStandingOrder(ctx context.Context, order walletmodel.StandingOrder) (
+	walletmodel.StandingOrder, error,
+) {
+	updatedOrder, err := scanStandingOrder(p.db.QueryRow(ctx, updateStandingOrderQuery, order.OrderID,
+		order.DstWalletID, order.Amount, order.Currency, order.Frequency, order.StartAt, order.NextRunAt, order.Runs))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return walletmodel.StandingOrder{}, ErrStandingOrderNotActive
+		}
+
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgerrcode.ForeignKeyViolation == pgErr.SQLState() {
+				return walletmodel.StandingOrder{}, ErrCurrencyNotFound
+			}
+		}
+
+		return walletmodel.StandingOrder{}, fmt.Errorf("scanStandingOrder: %w", err)
+	}
+
+	return updatedOrder, nil
+}
+
+func (p *Postgres) CancelStandingOrder(ctx context.Context, id string) error {
+	commandTag, err := p.db.Exec(ctx, cancelStandingOrderQuery, id)
+	if err != nil {
+		return standingOrderError(err)
+	}
+
+	if commandTag.RowsAffected() != 1 {
+		return ErrStandingOrderNotActive
+	}
+
+	return nil
+}
+
+func (p *Postgres) GetDueStandingOrders(ctx context.Context, now time.Time, limit int) (
+	[]walletmodel.StandingOrder, error,
+) {
+	rows, err := p.db.Query(ctx, getDueStandingOrdersQuery, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("db.Query: %w", err)
+	}
+
+	orders, err := scanStandingOrders(rows)
+	if err != nil {
+		return nil, fmt.Errorf("scanStandingOrders: %w", err)
+	}
+
+	return orders, nil
+}
+
+// RecordStandingOrderExecution stores the result of a run and moves the order on to nextRunAt with the
+// given status. An order changed or cancelled meanwhile keeps its new schedule.
+func (p *Postgres) RecordStandingOrderExecution(ctx context.Context, execution walletmodel.StandingOrderExecution,
+	nextRunAt time.Time, status string,
+) error {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("db.Begin: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				p.log.Warningf("tx.Rollback: %s", err)
+			}
+		}
+	}()
+
+	_, err = tx.Exec(ctx, insertExecutionQuery, execution.OrderID, execution.ScheduledAt, execution.TransactionKey,
+		execution.TransactionID, execution.Status, execution.Error)
+	if err != nil {
+		return fmt.Errorf("tx.Exec: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, advanceStandingOrderQuery, execution.OrderID, execution.ScheduledAt, nextRunAt, status)
+	if err != nil {
+		return fmt.Errorf("tx.Exec: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) GetStandingOrderExecutions(ctx context.Context, id string) (
+	[]walletmodel.StandingOrderExecution, error,
+) {
+	rows, err := p.db.Query(ctx, getExecutionsQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("db.Query: %w", err)
+	}
+
+	defer rows.Close()
+
+	executions := make([]walletmodel.StandingOrderExecution, 0)
+
+	for rows.Next() {
+		var execution walletmodel.StandingOrderExecution
+
+		err = rows.Scan(
+			&execution.OrderID,
+			&execution.ScheduledAt,
+			&execution.TransactionKey,
+			&execution.TransactionID,
+			&execution.Status,
+			&execution.Error,
+			&execution.Created,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+
+		executions = append(executions, execution)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, standingOrderError(err)
+	}
+
+	return executions, nil
+}
+
+func standingOrderError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrStandingOrderNotFound
+	}
+
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) {
+		if pgerrcode.InvalidTextRepresentation == pgErr.SQLState() {
+			return ErrInvalidStandingOrderID
+		}
+	}
+
+	return fmt.Errorf("scanStandingOrder: %w", err)
+}
+
+func scanStandingOrders(rows pgx.Rows) ([]walletmodel.StandingOrder, error) {
+	defer rows.Close()
+
+	var orders []walletmodel.StandingOrder
+
+	for rows.Next() {
+		order, err := scanStandingOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, order)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	return orders, nil
+}
+
+func scanStandingOrder(row pgx.Row) (walletmodel.StandingOrder, error) {
+	var order walletmodel.StandingOrder
+
+	err := row.Scan(
+		&order.OrderID,
+		&order.SrcWalletID,
+		&order.DstWalletID,
+		&order.Amount,
+		&order.Currency,
+		&order.Frequency,
+		&order.StartAt,
+		&order.NextRunAt,
+		&order.Runs,
+		&order.Status,
+		&order.Created,
+		&order.Updated,
+	)
+	if err != nil {
+		return walletmodel.StandingOrder{}, fmt.Errorf("row.Scan: %w", err)
+	}
+
+	return order, nil
+}
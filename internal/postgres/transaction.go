@@ -0,0 +1,310 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	walletmodel "github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	insertTransactionQuery = `
+	INSERT INTO transactions (transaction_id, operation_type, amount, currency, src_wallet_id, src_amount,
+		src_currency, src_rate, src_side, src_spread_bps, dst_wallet_id, dst_amount, dst_currency, dst_rate, dst_side,
+		dst_spread_bps, fee_amount, fee_currency, fee_wallet_id, transaction_key, status, reversal_of,
+		reason)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22,
+		$23);
+	`
+	getTransactionQuery = `
+	SELECT transaction_id, operation_type, amount, currency, src_wallet_id, src_amount, src_currency, src_rate,
+		src_side, src_spread_bps, dst_wallet_id, dst_amount, dst_currency, dst_rate, dst_side, dst_spread_bps,
+		fee_amount, fee_currency, fee_wallet_id, transaction_key, status, reversal_of, reversed_amount, reason, created_at
+	FROM transactions
+	WHERE transaction_id = $1;
+	`
+	amount = "amount"
+)
+
+var (
+	ErrTransactionNotFound  = errors.New("no such transaction")
+	ErrInvalidTransactionID = errors.New("invalid transactionID for type uuid")
+)
+
+type transactionLegColumns struct {
+	walletID  uuid.NullUUID
+	amount    *money.Amount
+	currency  *string
+	rate      *money.Rate
+	side      *string
+	spreadBps *int64
+}
+
+type transactionFeeColumns struct {
+	amount   *money.Amount
+	currency *string
+	walletID uuid.NullUUID
+}
+
+func (p *Postgres) GetTransaction(ctx context.Context, id string) (walletmodel.Transaction, error) {
+	transaction, err := scanTransaction(p.db.QueryRow(ctx, getTransactionQuery, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return walletmodel.Transaction{}, ErrTransactionNotFound
+		}
+
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgerrcode.InvalidTextRepresentation == pgErr.SQLState() {
+				return walletmodel.Transaction{}, ErrInvalidTransactionID
+			}
+		}
+
+		return walletmodel.Transaction{}, fmt.Errorf("scanTransaction: %w", err)
+	}
+
+	return transaction, nil
+}
+
+func (p *Postgres) GetWalletTransactions(ctx context.Context, id string, params walletmodel.RequestTransactionsList) (
+	[]walletmodel.Transaction, error,
+) {
+	tableColumnsList := map[string]string{
+		amount:        amount,
+		currency:      currency,
+		createdAt:     createdAt,
+		operationType: operationType,
+	}
+
+	var args []interface{}
+
+	query := `
+	SELECT transaction_id, operation_type, amount, currency, src_wallet_id, src_amount, src_currency, src_rate,
+		src_side, src_spread_bps, dst_wallet_id, dst_amount, dst_currency, dst_rate, dst_side, dst_spread_bps,
+		fee_amount, fee_currency, fee_wallet_id, transaction_key, status, reversal_of, reversed_amount, reason, created_at
+	FROM transactions
+	WHERE TRUE`
+
+	args = append(args, id)
+	query += fmt.Sprintf(` AND (src_wallet_id = $%d OR dst_wallet_id = $%d)`, len(args), len(args))
+
+	if params.OperationType != "" {
+		args = append(args, params.OperationType)
+		query += fmt.Sprintf(` AND operation_type = $%d`, len(args))
+	}
+
+	if params.Currency != "" {
+		args = append(args, params.Currency)
+		query += fmt.Sprintf(` AND currency = $%d`, len(args))
+	}
+
+	if !params.PeriodStart.IsZero() {
+		args = append(args, params.PeriodStart)
+		query += fmt.Sprintf(` AND created_at >= $%d`, len(args))
+	}
+
+	if !params.PeriodEnd.IsZero() {
+		args = append(args, params.PeriodEnd)
+		query += fmt.Sprintf(` AND created_at <= $%d`, len(args))
+	}
+
+	updatedQuery, updatedArgs := p.buildQueryAndArgs(tableColumnsList, args, query, params.ListingQueryParams)
+
+	rows, err := p.db.Query(ctx, updatedQuery, updatedArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("db.Query: %w", err)
+	}
+
+	defer rows.Close()
+
+	transactions := make([]walletmodel.Transaction, 0)
+
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanTransaction: %w", err)
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	return transactions, nil
+}
+
+func (p *Postgres) recordTransaction(ctx context.Context, q querier, txn walletmodel.LedgerTransaction) error {
+	src := legColumns(txn.Source)
+	dst := legColumns(txn.Destination)
+	fee := feeColumns(txn.Fee)
+
+	_, err := q.Exec(
+		ctx,
+		insertTransactionQuery,
+		txn.TransactionID,
+		txn.OperationType,
+		txn.Amount,
+		txn.Currency,
+		src.walletID,
+		src.amount,
+		src.currency,
+		src.rate,
+		src.side,
+		src.spreadBps,
+		dst.walletID,
+		dst.amount,
+		dst.currency,
+		dst.rate,
+		dst.side,
+		dst.spreadBps,
+		fee.amount,
+		fee.currency,
+		fee.walletID,
+		txn.TransactionKey,
+		txn.Status,
+		txn.ReversalOf,
+		txn.Reason,
+	)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	err = p.insertLedgerEntries(ctx, q, txn)
+	if err != nil {
+		return fmt.Errorf("insertLedgerEntries: %w", err)
+	}
+
+	if txn.QuoteID.Valid {
+		err = p.useQuote(ctx, q, txn.QuoteID.UUID, txn.TransactionID)
+		if err != nil {
+			return fmt.Errorf("useQuote: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func scanTransaction(row pgx.Row) (walletmodel.Transaction, error) {
+	var (
+		transaction walletmodel.Transaction
+		src, dst    transactionLegColumns
+		fee         transactionFeeColumns
+	)
+
+	err := row.Scan(
+		&transaction.TransactionID,
+		&transaction.OperationType,
+		&transaction.Amount,
+		&transaction.Currency,
+		&src.walletID,
+		&src.amount,
+		&src.currency,
+		&src.rate,
+		&src.side,
+		&src.spreadBps,
+		&dst.walletID,
+		&dst.amount,
+		&dst.currency,
+		&dst.rate,
+		&dst.side,
+		&dst.spreadBps,
+		&fee.amount,
+		&fee.currency,
+		&fee.walletID,
+		&transaction.TransactionKey,
+		&transaction.Status,
+		&transaction.ReversalOf,
+		&transaction.ReversedAmount,
+		&transaction.Reason,
+		&transaction.Created,
+	)
+	if err != nil {
+		return walletmodel.Transaction{}, fmt.Errorf("row.Scan: %w", err)
+	}
+
+	transaction.Source = src.leg()
+	transaction.Destination = dst.leg()
+	transaction.Fee = fee.fee()
+
+	return transaction, nil
+}
+
+func legColumns(leg *walletmodel.TransactionLeg) transactionLegColumns {
+	if leg == nil {
+		return transactionLegColumns{}
+	}
+
+	columns := transactionLegColumns{
+		walletID: uuid.NullUUID{UUID: leg.WalletID, Valid: true},
+		amount:   &leg.Amount,
+		currency: &leg.Currency,
+	}
+
+	if leg.AppliedRate != nil {
+		columns.rate = &leg.AppliedRate.Rate
+		columns.side = &leg.AppliedRate.Side
+		columns.spreadBps = &leg.AppliedRate.SpreadBps
+	}
+
+	return columns
+}
+
+func (c transactionLegColumns) leg() *walletmodel.TransactionLeg {
+	if !c.walletID.Valid || c.amount == nil || c.currency == nil {
+		return nil
+	}
+
+	leg := &walletmodel.TransactionLeg{
+		WalletID: c.walletID.UUID,
+		Amount:   *c.amount,
+		Currency: *c.currency,
+	}
+
+	if c.rate != nil {
+		leg.AppliedRate = &walletmodel.AppliedRate{Rate: *c.rate}
+
+		if c.side != nil {
+			leg.AppliedRate.Side = *c.side
+		}
+
+		if c.spreadBps != nil {
+			leg.AppliedRate.SpreadBps = *c.spreadBps
+		}
+	}
+
+	return leg
+}
+
+func feeColumns(fee *walletmodel.Fee) transactionFeeColumns {
+	if fee == nil {
+		return transactionFeeColumns{}
+	}
+
+	return transactionFeeColumns{
+		amount:   &fee.Amount,
+		currency: &fee.Currency,
+		walletID: uuid.NullUUID{UUID: fee.FeeWalletID, Valid: true},
+	}
+}
+
+func (c transactionFeeColumns) fee() *walletmodel.Fee {
+	if c.amount == nil || c.currency == nil {
+		return nil
+	}
+
+	return &walletmodel.Fee{
+		Amount:      *c.amount,
+		Currency:    *c.currency,
+		FeeWalletID: c.walletID.UUID,
+	}
+}
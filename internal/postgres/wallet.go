@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 
 	walletmodel "github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
 	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
@@ -13,40 +15,71 @@ import (
 )
 
 const (
+	// walletColumns is what every query returning a wallet selects, in the order queryRowToWallet scans it.
+	walletColumns = `wallet_id, email, owner, currency, status, balance, balance - held, credit_limit,
+		GREATEST(held - balance, 0), credit_limit - GREATEST(held - balance, 0), created_at, updated_at,
+		(SELECT COALESCE(json_agg(json_build_object('currency', b.currency, 'balance', b.balance) ORDER BY b.currency),
+			'[]') FROM wallet_balances b WHERE b.wallet_id = wallet.wallet_id)`
 	createWalletQuery = `
-	INSERT INTO wallet (wallet_id, email, owner, currency) 
-	VALUES ($1, $2, $3, $4)
-	RETURNING wallet_id, email, owner, currency, balance, created_at, updated_at;
+	INSERT INTO wallet (wallet_id, email, owner, currency, owner_id) 
+	VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+	RETURNING ` + walletColumns + `;
 	`
 	getWalletQuery = `
-	SELECT wallet_id, email, owner, currency, balance, created_at, updated_at 
+	SELECT ` + walletColumns + `
 	FROM wallet
 	WHERE wallet_id = $1
 	AND deleted = FALSE;
 	`
 	updateWalletQuery = `
 	UPDATE wallet 
-	SET email = $2, owner = $3, currency = $4, balance = $5, updated_at = now(), inactive_mailed = false
+	SET email = $2, owner = $3, currency = $4, balance = balance + $5, updated_at = now(), inactive_mailed = false
 	WHERE wallet_id = $1
 	AND deleted = FALSE
-	RETURNING wallet_id, email, owner, currency, balance, created_at, updated_at;
+	RETURNING ` + walletColumns + `;
 	`
-	deleteWalletQuery = `
-	UPDATE wallet 
-	SET deleted = TRUE
-	WHERE wallet_id = $1
-	AND deleted = FALSE;
+	claimWalletOwnerQuery = `
+	WITH claimed AS (
+		UPDATE wallet
+		SET owner_id = $2
+		WHERE wallet_id = $1
+		AND owner_id IS NULL
+		AND $3 <> ''
+		AND lower(email) = lower($3)
+		RETURNING owner_id
+	)
+	SELECT COALESCE((SELECT owner_id FROM claimed), owner_id, '')
+	FROM wallet
+	WHERE wallet_id = $1;
+	`
+	walletHasFundsQuery = `
+	SELECT balance <> 0 OR EXISTS (SELECT 1 FROM wallet_balances b WHERE b.wallet_id = wallet.wallet_id AND b.balance <> 0)
+	FROM wallet
+	WHERE wallet_id = $1;
 	`
 	manageFundsQuery = `
 	UPDATE wallet
-	SET balance = $2, updated_at = now(), inactive_mailed = false
+	SET balance = balance + $2, updated_at = now(), inactive_mailed = false
+	WHERE wallet_id = $1
+	AND currency = $3
+	AND deleted = FALSE
+	RETURNING ` + walletColumns + `;
+	`
+	setCreditLimitQuery = `
+	UPDATE wallet
+	SET credit_limit = $2
 	WHERE wallet_id = $1
 	AND deleted = FALSE
-	RETURNING wallet_id, email, owner, currency, balance, created_at, updated_at;
+	RETURNING ` + walletColumns + `;
 	`
-	verifyTransactKeyQuery = `
-	INSERT INTO idempotency (transaction_key)
-	VALUES ($1);
+	setWalletStatusQuery = `
+	UPDATE wallet
+	SET status = $3, status_reason = NULLIF($4, ''), deleted = ($3 = 'CLOSED'),
+		deleted_at = CASE WHEN $3 = 'CLOSED' THEN now() END
+	WHERE wallet_id = $1
+	AND status = $2
+	AND deleted = FALSE
+	RETURNING ` + walletColumns + `;
 	`
 	mailInactiveQuery = `
 	UPDATE wallet
@@ -54,23 +87,29 @@ const (
 	WHERE updated_at <= NOW() - '1 month'::interval
 	AND inactive_mailed = FALSE
 	AND deleted = FALSE
-	RETURNING wallet_id, email, owner, currency, balance, created_at, updated_at;
+	RETURNING ` + walletColumns + `;
 	`
-	walletID      = "wallet_id"
-	email         = "email"
-	owner         = "owner"
-	currency      = "currency"
-	balance       = "balance"
-	createdAt     = "created_at"
-	updatedAt     = "updated_at"
-	operationType = "operation_type"
+	walletID        = "wallet_id"
+	email           = "email"
+	owner           = "owner"
+	currency        = "currency"
+	balance         = "balance"
+	createdAt       = "created_at"
+	updatedAt       = "updated_at"
+	operationType   = "operation_type"
+	balanceCheck    = "wallet_balance_within_credit_limit"
+	heldCheck       = "wallet_available_balance_within_credit_limit"
+	subBalanceCheck = "sub_balance_non_negative"
 )
 
 var (
-	ErrWalletNotFound       = errors.New("no such wallet")
-	ErrRequestNotIdempotent = errors.New("non-idempotent request")
-	ErrInvalidWalletID      = errors.New("invalid walletID for type uuid")
-	ErrEmailNotUnique       = errors.New("non-unique email")
+	ErrWalletNotFound      = errors.New("no such wallet")
+	ErrInvalidWalletID     = errors.New("invalid walletID for type uuid")
+	ErrEmailNotUnique      = errors.New("non-unique email")
+	ErrOverdraft           = errors.New("balance would go below the credit limit")
+	ErrCreditLimitInUse    = errors.New("credit limit is below the credit in use")
+	ErrWalletHasCreditLine = errors.New("wallet has a credit line or a negative balance")
+	ErrWalletHasBalance    = errors.New("wallet still holds funds")
 )
 
 type querier interface {
@@ -78,9 +117,9 @@ type querier interface {
 	QueryRow(ctx context.Context, query string, args ...interface{}) pgx.Row
 }
 
-func (p *Postgres) CreateWallet(ctx context.Context, wallet walletmodel.RequestWalletInstance) (
-	walletmodel.ResponseWalletInstance, error,
-) {
+func (p *Postgres) CreateWallet(ctx context.Context, wallet walletmodel.RequestWalletInstance,
+	key walletmodel.IdempotencyKey,
+) (walletmodel.ResponseWalletInstance, error) {
 	tx, err := p.db.Begin(ctx)
 	if err != nil {
 		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("db.Begin: %w", err)
@@ -95,23 +134,39 @@ func (p *Postgres) CreateWallet(ctx context.Context, wallet walletmodel.RequestW
 		}
 	}()
 
-	err = p.idempotency(ctx, tx, wallet.TransactionKey.String())
+	var createdWallet walletmodel.ResponseWalletInstance
+
+	replayed, err := p.idempotency(ctx, tx, key, &createdWallet)
 	if err != nil {
 		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("idempotency: %w", err)
 	}
 
-	row := tx.QueryRow(ctx, createWalletQuery, wallet.WalletID, wallet.Email, wallet.Owner, wallet.Currency)
+	if replayed {
+		err = tx.Commit(ctx)
+		if err != nil {
+			return walletmodel.ResponseWalletInstance{}, fmt.Errorf("tx.Commit: %w", err)
+		}
 
-	var createdWallet walletmodel.ResponseWalletInstance
+		return createdWallet, nil
+	}
+
+	row := tx.QueryRow(ctx, createWalletQuery, wallet.WalletID, wallet.Email, wallet.Owner, wallet.Currency,
+		wallet.OwnerID)
 
 	err = row.Scan(
 		&createdWallet.WalletID,
 		&createdWallet.Email,
 		&createdWallet.Owner,
 		&createdWallet.Currency,
+		&createdWallet.Status,
 		&createdWallet.Balance,
+		&createdWallet.AvailableBalance,
+		&createdWallet.CreditLimit,
+		&createdWallet.UsedCredit,
+		&createdWallet.AvailableCredit,
 		&createdWallet.Created,
 		&createdWallet.Updated,
+		&createdWallet.SubBalances,
 	)
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -125,6 +180,11 @@ func (p *Postgres) CreateWallet(ctx context.Context, wallet walletmodel.RequestW
 		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("row.Scan: %w", err)
 	}
 
+	err = p.saveIdempotentResponse(ctx, tx, key, createdWallet)
+	if err != nil {
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("saveIdempotentResponse: %w", err)
+	}
+
 	err = tx.Commit(ctx)
 	if err != nil {
 		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("tx.Commit: %w", err)
@@ -143,9 +203,15 @@ func (p *Postgres) GetWallet(ctx context.Context, id string) (walletmodel.Respon
 		&wallet.Email,
 		&wallet.Owner,
 		&wallet.Currency,
+		&wallet.Status,
 		&wallet.Balance,
+		&wallet.AvailableBalance,
+		&wallet.CreditLimit,
+		&wallet.UsedCredit,
+		&wallet.AvailableCredit,
 		&wallet.Created,
 		&wallet.Updated,
+		&wallet.SubBalances,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -166,6 +232,32 @@ func (p *Postgres) GetWallet(ctx context.Context, id string) (walletmodel.Respon
 	return wallet, nil
 }
 
+// ClaimWalletOwner returns the subject the wallet belongs to. A wallet created before owners were
+// recorded has none until the first subject with its email claims it. Closed wallets keep their owner,
+// so the transactions of a closed wallet stay visible to it.
+func (p *Postgres) ClaimWalletOwner(ctx context.Context, id, subject, email string) (string, error) {
+	var ownerID string
+
+	err := p.db.QueryRow(ctx, claimWalletOwnerQuery, id, subject, email).Scan(&ownerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrWalletNotFound
+		}
+
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgerrcode.InvalidTextRepresentation == pgErr.SQLState() {
+				return "", ErrInvalidWalletID
+			}
+		}
+
+		return "", fmt.Errorf("row.Scan: %w", err)
+	}
+
+	return ownerID, nil
+}
+
 func (p *Postgres) GetWalletsList(ctx context.Context, params walletmodel.ListingQueryParams) (
 	[]walletmodel.ResponseWalletInstance, error,
 ) {
@@ -182,10 +274,20 @@ func (p *Postgres) GetWalletsList(ctx context.Context, params walletmodel.Listin
 	var args []interface{}
 
 	query := `
-	SELECT wallet_id, email, owner, currency, balance, created_at, updated_at
+	SELECT ` + walletColumns + `
 	FROM wallet
 	WHERE TRUE AND deleted = FALSE`
 
+	if params.OwnerID != "" {
+		args = append(args, params.OwnerID, params.OwnerEmail)
+		query += fmt.Sprintf(` AND %s`, ownedBy(len(args)-1, len(args)))
+	}
+
+	if params.Status != "" {
+		args = append(args, params.Status)
+		query += fmt.Sprintf(` AND status = $%d`, len(args))
+	}
+
 	updatedQuery, updatedArgs := p.buildQueryAndArgs(tableColumnsList, args, query, params)
 
 	rows, err := p.db.Query(ctx, updatedQuery, updatedArgs...)
@@ -205,9 +307,15 @@ func (p *Postgres) GetWalletsList(ctx context.Context, params walletmodel.Listin
 			&wallet.Email,
 			&wallet.Owner,
 			&wallet.Currency,
+			&wallet.Status,
 			&wallet.Balance,
+			&wallet.AvailableBalance,
+			&wallet.CreditLimit,
+			&wallet.UsedCredit,
+			&wallet.AvailableCredit,
 			&wallet.Created,
 			&wallet.Updated,
+			&wallet.SubBalances,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("row.Scan: %w", err)
@@ -224,7 +332,7 @@ func (p *Postgres) GetWalletsList(ctx context.Context, params walletmodel.Listin
 	return walletsList, nil
 }
 
-func (p *Postgres) GetWalletHistory(ctx context.Context, id string, params walletmodel.RequestWalletHistory) (
+func (p *Postgres) GetWalletHistory(ctx context.Context, params walletmodel.RequestWalletHistory) (
 	[]walletmodel.ResponseWalletHistory, error,
 ) {
 	tableColumnsList := map[string]string{
@@ -240,14 +348,18 @@ func (p *Postgres) GetWalletHistory(ctx context.Context, id string, params walle
 	var args []interface{}
 
 	query := `
-	SELECT *
+	SELECT wallet_id, email, owner, currency, balance, created_at, operation_type, status, reason
 	FROM history
 	WHERE TRUE`
 
-	args = append(args, id)
-	query += fmt.Sprintf(` AND (wallet_id=$%d`, len(args))
+	if params.OwnerID != "" {
+		args = append(args, params.OwnerID, params.OwnerEmail)
+		query += fmt.Sprintf(` AND wallet_id IN (SELECT wallet_id FROM wallet WHERE %s)`,
+			ownedBy(len(args)-1, len(args)))
+	}
+
 	args = append(args, params.PeriodStart)
-	query += fmt.Sprintf(` AND created_at >= $%d`, len(args))
+	query += fmt.Sprintf(` AND (created_at >= $%d`, len(args))
 	args = append(args, params.PeriodEnd)
 	query += fmt.Sprintf(` AND created_at <= $%d)`, len(args))
 
@@ -266,7 +378,7 @@ func (p *Postgres) GetWalletHistory(ctx context.Context, id string, params walle
 		var wallet walletmodel.ResponseWalletHistory
 
 		err = rows.Scan(&wallet.WalletID, &wallet.Email, &wallet.Owner, &wallet.Currency, &wallet.Balance, &wallet.Created,
-			&wallet.Operation)
+			&wallet.Operation, &wallet.Status, &wallet.Reason)
 		if err != nil {
 			return nil, fmt.Errorf("row.Scan: %w", err)
 		}
@@ -282,29 +394,99 @@ func (p *Postgres) GetWalletHistory(ctx context.Context, id string, params walle
 	return walletHistory, nil
 }
 
-func (p *Postgres) UpdateWallet(ctx context.Context, wallet walletmodel.RequestWalletInstance) (
-	walletmodel.ResponseWalletInstance, error,
-) {
-	row := p.db.QueryRow(
+func (p *Postgres) UpdateWallet(ctx context.Context, wallet walletmodel.RequestWalletInstance,
+	txn walletmodel.LedgerTransaction,
+) (walletmodel.ResponseWalletInstance, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("db.Begin: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				p.log.Warningf("tx.Rollback: %s", err)
+			}
+		}
+	}()
+
+	locked, err := p.lockWallets(ctx, tx, []uuid.UUID{wallet.WalletID})
+	if err != nil {
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("lockWallets: %w", err)
+	}
+
+	current, ok := locked[wallet.WalletID]
+	if !ok {
+		err = ErrWalletNotFound
+
+		return walletmodel.ResponseWalletInstance{}, err
+	}
+
+	if wallet.Currency != current.currency && !current.held.IsZero() {
+		err = ErrWalletHasHolds
+
+		return walletmodel.ResponseWalletInstance{}, err
+	}
+
+	if wallet.Currency != current.currency && (current.balance.Sign() < 0 || !current.creditLimit.IsZero()) {
+		err = ErrWalletHasCreditLine
+
+		return walletmodel.ResponseWalletInstance{}, err
+	}
+
+	balanceDelta, err := p.conversionDelta(wallet, current, txn)
+	if err != nil {
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("conversionDelta: %w", err)
+	}
+
+	if wallet.Currency != current.currency {
+		var subBalance money.Amount
+
+		subBalance, err = p.takeSubBalance(ctx, tx, wallet.WalletID, wallet.Currency)
+		if err != nil {
+			return walletmodel.ResponseWalletInstance{}, fmt.Errorf("takeSubBalance: %w", err)
+		}
+
+		balanceDelta, err = balanceDelta.Add(subBalance)
+		if err != nil {
+			return walletmodel.ResponseWalletInstance{}, fmt.Errorf("balanceDelta.Add: %w", err)
+		}
+	}
+
+	if len(txn.Entries) > 0 {
+		err = p.recordTransaction(ctx, tx, txn)
+		if err != nil {
+			return walletmodel.ResponseWalletInstance{}, fmt.Errorf("recordTransaction: %w", err)
+		}
+	}
+
+	row := tx.QueryRow(
 		ctx,
 		updateWalletQuery,
 		wallet.WalletID,
 		wallet.Email,
 		wallet.Owner,
 		wallet.Currency,
-		wallet.Balance,
+		balanceDelta,
 	)
 
 	var updatedWallet walletmodel.ResponseWalletInstance
 
-	err := row.Scan(
+	err = row.Scan(
 		&updatedWallet.WalletID,
 		&updatedWallet.Email,
 		&updatedWallet.Owner,
 		&updatedWallet.Currency,
+		&updatedWallet.Status,
 		&updatedWallet.Balance,
+		&updatedWallet.AvailableBalance,
+		&updatedWallet.CreditLimit,
+		&updatedWallet.UsedCredit,
+		&updatedWallet.AvailableCredit,
 		&updatedWallet.Created,
 		&updatedWallet.Updated,
+		&updatedWallet.SubBalances,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -317,30 +499,66 @@ func (p *Postgres) UpdateWallet(ctx context.Context, wallet walletmodel.RequestW
 			if pgerrcode.UniqueViolation == pgErr.SQLState() {
 				return walletmodel.ResponseWalletInstance{}, ErrEmailNotUnique
 			}
+
+			if pgerrcode.NumericValueOutOfRange == pgErr.SQLState() {
+				return walletmodel.ResponseWalletInstance{}, money.ErrOverflow
+			}
 		}
 
 		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("row.Scan: %w", err)
 	}
 
+	err = tx.Commit(ctx)
+	if err != nil {
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
 	return updatedWallet, nil
 }
 
-func (p *Postgres) DeleteWallet(ctx context.Context, id string) error {
-	commandTag, err := p.db.Exec(ctx, deleteWalletQuery, id)
+// conversionDelta checks that a currency change moves the whole locked balance out of the old currency
+// and returns the balance change to apply together with the new currency.
+func (*Postgres) conversionDelta(wallet walletmodel.RequestWalletInstance, current lockedWallet,
+	txn walletmodel.LedgerTransaction,
+) (money.Amount, error) {
+	if wallet.Currency == current.currency {
+		if len(txn.Entries) > 0 {
+			return money.Amount{}, ErrConcurrentUpdate
+		}
+
+		return money.Amount{}, nil
+	}
+
+	deltas, err := walletDeltas(txn)
+	if err != nil {
+		return money.Amount{}, fmt.Errorf("walletDeltas: %w", err)
+	}
+
+	oldDelta := deltas[walletBalanceKey{walletID: wallet.WalletID, currency: current.currency}]
+	newDelta := deltas[walletBalanceKey{walletID: wallet.WalletID, currency: wallet.Currency}]
+
+	remaining, err := current.balance.Add(oldDelta)
 	if err != nil {
-		return fmt.Errorf("db.Exec: %w", err)
+		return money.Amount{}, fmt.Errorf("balance.Add: %w", err)
 	}
 
-	if commandTag.RowsAffected() != 1 {
-		return ErrWalletNotFound
+	if !remaining.IsZero() || len(deltas) > 2 {
+		return money.Amount{}, ErrConcurrentUpdate
 	}
 
-	return nil
+	return newDelta.Sub(current.balance)
 }
 
-func (p *Postgres) ManageBalance(ctx context.Context, key uuid.UUID, id string, balance float32) (
-	walletmodel.ResponseWalletInstance, error,
-) {
+// CloseWallet sweeps the remaining funds with the given transfers and closes the wallet in the same
+// transaction. The wallet must hold nothing once the sweeps are posted.
+func (p *Postgres) CloseWallet(ctx context.Context, id, from, reason string,
+	sweeps []walletmodel.LedgerTransaction,
+) (walletmodel.ResponseWalletInstance, error) {
+	walletID, err := uuid.Parse(id)
+	if err != nil {
+		return walletmodel.ResponseWalletInstance{}, ErrInvalidWalletID
+	}
+
 	tx, err := p.db.Begin(ctx)
 	if err != nil {
 		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("db.Begin: %w", err)
@@ -355,24 +573,218 @@ func (p *Postgres) ManageBalance(ctx context.Context, key uuid.UUID, id string,
 		}
 	}()
 
-	err = p.idempotency(ctx, tx, key.String())
+	ids := []uuid.UUID{walletID}
+
+	for _, sweep := range sweeps {
+		for _, entry := range sweep.Entries {
+			if entry.WalletID.Valid && !containsWallet(ids, entry.WalletID.UUID) {
+				ids = append(ids, entry.WalletID.UUID)
+			}
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+
+	locked, err := p.lockWallets(ctx, tx, ids)
 	if err != nil {
-		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("idempotency: %w", err)
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("lockWallets: %w", err)
+	}
+
+	current, ok := locked[walletID]
+	if !ok {
+		err = ErrWalletNotFound
+
+		return walletmodel.ResponseWalletInstance{}, err
+	}
+
+	if !current.held.IsZero() {
+		err = ErrWalletHasHolds
+
+		return walletmodel.ResponseWalletInstance{}, err
+	}
+
+	for _, sweep := range sweeps {
+		_, err = p.postTransaction(ctx, tx, sweep)
+		if err != nil {
+			return walletmodel.ResponseWalletInstance{}, fmt.Errorf("postTransaction: %w", err)
+		}
+	}
+
+	var funded bool
+
+	err = tx.QueryRow(ctx, walletHasFundsQuery, walletID).Scan(&funded)
+	if err != nil {
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("row.Scan: %w", err)
+	}
+
+	if funded {
+		err = ErrWalletHasBalance
+
+		return walletmodel.ResponseWalletInstance{}, err
 	}
 
-	updatedWallet, err := p.queryRowToWallet(ctx, tx, manageFundsQuery, id, balance)
+	wallet, err := p.queryRowToWallet(ctx, tx, setWalletStatusQuery, walletID, from, walletmodel.WalletClosed,
+		reason)
+	if err != nil {
+		if errors.Is(err, ErrWalletNotFound) {
+			err = ErrConcurrentUpdate
+		}
+
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("queryRowToWallet: %w", err)
+	}
 
 	err = tx.Commit(ctx)
 	if err != nil {
 		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("tx.Commit: %w", err)
 	}
 
-	return updatedWallet, nil
+	return wallet, nil
 }
 
-func (p *Postgres) TransferFunds(ctx context.Context, key uuid.UUID, idSrc, idDst string, balanceSrc,
-	balanceDst float32,
-) (walletmodel.ResponseWalletInstance, error) {
+func (p *Postgres) ManageBalance(ctx context.Context, key walletmodel.IdempotencyKey, id string,
+	txn walletmodel.LedgerTransaction,
+) (walletmodel.ResponseFundsOperation, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return walletmodel.ResponseFundsOperation{}, fmt.Errorf("db.Begin: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				p.log.Warningf("tx.Rollback: %s", err)
+			}
+		}
+	}()
+
+	var response walletmodel.ResponseFundsOperation
+
+	replayed, err := p.idempotency(ctx, tx, key, &response)
+	if err != nil {
+		return walletmodel.ResponseFundsOperation{}, fmt.Errorf("idempotency: %w", err)
+	}
+
+	if replayed {
+		err = tx.Commit(ctx)
+		if err != nil {
+			return walletmodel.ResponseFundsOperation{}, fmt.Errorf("tx.Commit: %w", err)
+		}
+
+		return response, nil
+	}
+
+	wallets, err := p.postTransaction(ctx, tx, txn)
+	if err != nil {
+		return walletmodel.ResponseFundsOperation{}, fmt.Errorf("postTransaction: %w", err)
+	}
+
+	updatedWallet, ok := wallets[id]
+	if !ok {
+		err = ErrWalletNotFound
+
+		return walletmodel.ResponseFundsOperation{}, err
+	}
+
+	response = walletmodel.ResponseFundsOperation{
+		ResponseWalletInstance: updatedWallet,
+		TransactionID:          txn.TransactionID,
+		Fee:                    txn.Fee,
+	}
+
+	err = p.saveIdempotentResponse(ctx, tx, key, response)
+	if err != nil {
+		return walletmodel.ResponseFundsOperation{}, fmt.Errorf("saveIdempotentResponse: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return walletmodel.ResponseFundsOperation{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return response, nil
+}
+
+func (p *Postgres) TransferFunds(ctx context.Context, key walletmodel.IdempotencyKey, idSrc, idDst string,
+	txn walletmodel.LedgerTransaction,
+) (walletmodel.ResponseTransfer, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return walletmodel.ResponseTransfer{}, fmt.Errorf("db.Begin: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				p.log.Warningf("tx.Rollback: %s", err)
+			}
+		}
+	}()
+
+	var response walletmodel.ResponseTransfer
+
+	replayed, err := p.idempotency(ctx, tx, key, &response)
+	if err != nil {
+		return walletmodel.ResponseTransfer{}, fmt.Errorf("idempotency: %w", err)
+	}
+
+	if replayed {
+		err = tx.Commit(ctx)
+		if err != nil {
+			return walletmodel.ResponseTransfer{}, fmt.Errorf("tx.Commit: %w", err)
+		}
+
+		return response, nil
+	}
+
+	wallets, err := p.postTransaction(ctx, tx, txn)
+	if err != nil {
+		return walletmodel.ResponseTransfer{}, fmt.Errorf("postTransaction: %w", err)
+	}
+
+	srcWallet, okSrc := wallets[idSrc]
+	dstWallet, okDst := wallets[idDst]
+
+	if !okSrc || !okDst || txn.Source == nil || txn.Destination == nil {
+		err = ErrWalletNotFound
+
+		return walletmodel.ResponseTransfer{}, err
+	}
+
+	response = walletmodel.ResponseTransfer{
+		TransactionID:  txn.TransactionID,
+		Amount:         txn.Amount,
+		Currency:       txn.Currency,
+		SrcWallet:      srcWallet,
+		DstWallet:      dstWallet,
+		DebitedAmount:  txn.Source.Amount,
+		CreditedAmount: txn.Destination.Amount,
+		SrcRate:        txn.Source.AppliedRate,
+		DstRate:        txn.Destination.AppliedRate,
+		Fee:            txn.Fee,
+	}
+
+	err = p.saveIdempotentResponse(ctx, tx, key, response)
+	if err != nil {
+		return walletmodel.ResponseTransfer{}, fmt.Errorf("saveIdempotentResponse: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return walletmodel.ResponseTransfer{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return response, nil
+}
+
+// SetWalletStatus moves the wallet from one status to another. The change fails with ErrConcurrentUpdate
+// when the wallet has left the expected status meanwhile.
+func (p *Postgres) SetWalletStatus(ctx context.Context, id, from, to, reason string) (
+	walletmodel.ResponseWalletInstance, error,
+) {
 	tx, err := p.db.Begin(ctx)
 	if err != nil {
 		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("db.Begin: %w", err)
@@ -387,21 +799,57 @@ func (p *Postgres) TransferFunds(ctx context.Context, key uuid.UUID, idSrc, idDs
 		}
 	}()
 
-	err = p.idempotency(ctx, tx, key.String())
+	wallet, err := p.queryRowToWallet(ctx, tx, setWalletStatusQuery, id, from, to, reason)
 	if err != nil {
-		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("idempotency: %w", err)
+		if errors.Is(err, ErrWalletNotFound) {
+			err = ErrConcurrentUpdate
+		}
+
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("queryRowToWallet: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// SetCreditLimit changes how far below zero the wallet balance may go. The limit cannot be lowered
+// under the credit already used.
+func (p *Postgres) SetCreditLimit(ctx context.Context, id string, limit money.Amount) (
+	walletmodel.ResponseWalletInstance, error,
+) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("db.Begin: %w", err)
 	}
 
-	_, err = p.queryRowToWallet(ctx, tx, manageFundsQuery, idSrc, balanceSrc)
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				p.log.Warningf("tx.Rollback: %s", err)
+			}
+		}
+	}()
+
+	wallet, err := p.queryRowToWallet(ctx, tx, setCreditLimitQuery, id, limit)
+	if err != nil {
+		if errors.Is(err, ErrOverdraft) {
+			err = ErrCreditLimitInUse
+		}
 
-	dstWallet, err := p.queryRowToWallet(ctx, tx, manageFundsQuery, idDst, balanceDst)
+		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("queryRowToWallet: %w", err)
+	}
 
 	err = tx.Commit(ctx)
 	if err != nil {
 		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("tx.Commit: %w", err)
 	}
 
-	return dstWallet, nil
+	return wallet, nil
 }
 
 func (p *Postgres) TrackInactiveWallets(ctx context.Context) ([]walletmodel.ResponseWalletInstance, error) {
@@ -422,9 +870,15 @@ func (p *Postgres) TrackInactiveWallets(ctx context.Context) ([]walletmodel.Resp
 			&wallet.Email,
 			&wallet.Owner,
 			&wallet.Currency,
+			&wallet.Status,
 			&wallet.Balance,
+			&wallet.AvailableBalance,
+			&wallet.CreditLimit,
+			&wallet.UsedCredit,
+			&wallet.AvailableCredit,
 			&wallet.Created,
 			&wallet.Updated,
+			&wallet.SubBalances,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("row.Scan: %w", err)
@@ -441,28 +895,10 @@ func (p *Postgres) TrackInactiveWallets(ctx context.Context) ([]walletmodel.Resp
 	return walletsList, nil
 }
 
-func (p *Postgres) idempotency(ctx context.Context, q querier, key string) error {
-	_, err := q.Exec(ctx, verifyTransactKeyQuery, key)
-
-	var pgErr *pgconn.PgError
-
-	if errors.As(err, &pgErr) {
-		if pgerrcode.UniqueViolation == pgErr.SQLState() {
-			return ErrRequestNotIdempotent
-		}
-	}
-
-	if err != nil {
-		return fmt.Errorf("exec: %w", err)
-	}
-
-	return nil
-}
-
-func (p *Postgres) queryRowToWallet(ctx context.Context, tx pgx.Tx, query, id string, balance float32) (
+func (p *Postgres) queryRowToWallet(ctx context.Context, tx pgx.Tx, query string, args ...interface{}) (
 	walletmodel.ResponseWalletInstance, error,
 ) {
-	row := tx.QueryRow(ctx, query, id, balance)
+	row := tx.QueryRow(ctx, query, args...)
 
 	var wallet walletmodel.ResponseWalletInstance
 
@@ -471,15 +907,34 @@ func (p *Postgres) queryRowToWallet(ctx context.Context, tx pgx.Tx, query, id st
 		&wallet.Email,
 		&wallet.Owner,
 		&wallet.Currency,
+		&wallet.Status,
 		&wallet.Balance,
+		&wallet.AvailableBalance,
+		&wallet.CreditLimit,
+		&wallet.UsedCredit,
+		&wallet.AvailableCredit,
 		&wallet.Created,
 		&wallet.Updated,
+		&wallet.SubBalances,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return walletmodel.ResponseWalletInstance{}, ErrWalletNotFound
 		}
 
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgerrcode.NumericValueOutOfRange == pgErr.SQLState() {
+				return walletmodel.ResponseWalletInstance{}, money.ErrOverflow
+			}
+
+			if pgerrcode.CheckViolation == pgErr.SQLState() &&
+				(pgErr.ConstraintName == balanceCheck || pgErr.ConstraintName == heldCheck) {
+				return walletmodel.ResponseWalletInstance{}, ErrOverdraft
+			}
+		}
+
 		return walletmodel.ResponseWalletInstance{}, fmt.Errorf("rowSrc.Scan: %w", err)
 	}
 
@@ -514,3 +969,10 @@ func (*Postgres) buildQueryAndArgs(tableColumnsList map[string]string, args []in
 
 	return query, args
 }
+
+// ownedBy matches the wallets of the subject in the idArg placeholder, and the unclaimed wallets with the
+// email in the emailArg placeholder.
+func ownedBy(idArg, emailArg int) string {
+	return fmt.Sprintf(`(owner_id = $%d OR (owner_id IS NULL AND $%d <> '' AND lower(email) = lower($%d)))`,
+		idArg, emailArg, emailArg)
+}
@@ -0,0 +1,197 @@
+package walletserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/postgres"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// walletsOf returns the wallets a resource belongs to. The caller gets access to the resource when
+// it owns any of them.
+type walletsOf func(ctx context.Context, id string) ([]string, error)
+
+// rolePermissions lists what each role may do. A token without roles is treated as RoleUser.
+var rolePermissions = map[string][]string{
+	models.RoleUser:    {models.PermissionSelfService},
+	models.RoleSupport: {models.PermissionReadWallets, models.PermissionManageStatus},
+	models.RoleAuditor: {models.PermissionReadWallets},
+	models.RoleAdmin: {
+		models.PermissionSelfService, models.PermissionReadWallets, models.PermissionOperateWallets,
+		models.PermissionManageStatus, models.PermissionAdjustBalances, models.PermissionManageCurrencies,
+		models.PermissionManageAPIKeys,
+	},
+}
+
+// scopePermissions maps API key scopes to the permissions they grant.
+var scopePermissions = map[string]string{
+	models.ScopeRead:     models.PermissionKeyRead,
+	models.ScopeDeposit:  models.PermissionKeyDeposit,
+	models.ScopeTransfer: models.PermissionKeyTransfer,
+}
+
+// permissionsOf returns the permissions granted by roles together with those granted to the token
+// directly. Unknown roles grant nothing.
+func permissionsOf(roles, granted []string) []string {
+	if len(roles) == 0 {
+		roles = []string{models.RoleUser}
+	}
+
+	permissions := slices.Clone(granted)
+
+	for _, role := range roles {
+		permissions = append(permissions, rolePermissions[role]...)
+	}
+
+	return permissions
+}
+
+// requirePermission guards routes that are not tied to one wallet, so callers restricted to some
+// wallets are turned away even when they hold permission.
+func (h *Handler) requirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		var fn http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+			sessionInfo, ok := h.getSessionInfo(r)
+			if !ok {
+				w.WriteHeader(http.StatusInternalServerError)
+
+				return
+			}
+
+			if !slices.Contains(sessionInfo.Permissions, permission) || len(sessionInfo.Wallets) > 0 {
+				w.WriteHeader(http.StatusForbidden)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return fn
+	}
+}
+
+// walletAccess lets a request through when the caller holds one of permissions, or is a self-service
+// caller that owns a wallet of the resource named by the URL parameter. A caller restricted to some
+// wallets, such as a wallet-scoped API key, also needs the resource to belong to one of them. A
+// resource that cannot be looked up is passed on, so the handler reports it the same way it does for
+// any caller.
+func (h *Handler) walletAccess(param string, wallets walletsOf, permissions ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		var fn http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+			sessionInfo, ok := h.getSessionInfo(r)
+			if !ok {
+				w.WriteHeader(http.StatusInternalServerError)
+
+				return
+			}
+
+			granted := slices.ContainsFunc(permissions, func(permission string) bool {
+				return slices.Contains(sessionInfo.Permissions, permission)
+			})
+
+			if granted && len(sessionInfo.Wallets) == 0 {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			if !granted && !slices.Contains(sessionInfo.Permissions, models.PermissionSelfService) {
+				w.WriteHeader(http.StatusForbidden)
+
+				return
+			}
+
+			ids, err := wallets(r.Context(), chi.URLParam(r, param))
+			if err != nil {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			for _, id := range ids {
+				if granted {
+					if slices.ContainsFunc(sessionInfo.Wallets, func(walletID string) bool {
+						return strings.EqualFold(walletID, id)
+					}) {
+						next.ServeHTTP(w, r)
+
+						return
+					}
+
+					continue
+				}
+
+				ownerID, err := h.service.ClaimWalletOwner(r.Context(), id, sessionInfo.UUID, sessionInfo.Email)
+				if errors.Is(err, postgres.ErrWalletNotFound) || errors.Is(err, postgres.ErrInvalidWalletID) {
+					next.ServeHTTP(w, r)
+
+					return
+				}
+
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+
+					return
+				}
+
+				if ownerID == sessionInfo.UUID {
+					next.ServeHTTP(w, r)
+
+					return
+				}
+			}
+
+			w.WriteHeader(http.StatusForbidden)
+		}
+
+		return fn
+	}
+}
+
+func (*Handler) wallet(_ context.Context, id string) ([]string, error) {
+	return []string{id}, nil
+}
+
+func (h *Handler) holdWallet(ctx context.Context, id string) ([]string, error) {
+	hold, err := h.service.GetHold(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetHold: %w", err)
+	}
+
+	return []string{hold.WalletID.String()}, nil
+}
+
+func (h *Handler) standingOrderWallet(ctx context.Context, id string) ([]string, error) {
+	order, err := h.service.GetStandingOrder(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetStandingOrder: %w", err)
+	}
+
+	return []string{order.SrcWalletID.String()}, nil
+}
+
+// transactionWallets returns both wallets of a transaction, so the sender and the receiver can see it.
+func (h *Handler) transactionWallets(ctx context.Context, id string) ([]string, error) {
+	transaction, err := h.service.GetTransaction(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetTransaction: %w", err)
+	}
+
+	var ids []string
+
+	for _, leg := range []*models.TransactionLeg{transaction.Source, transaction.Destination} {
+		if leg != nil && leg.WalletID != uuid.Nil {
+			ids = append(ids, leg.WalletID.String())
+		}
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,92 @@
+package walletserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/AlexZav1327/service/internal/postgres"
+	walletservice "github.com/AlexZav1327/service/internal/wallet-service"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) getAllWallets(w http.ResponseWriter, r *http.Request) {
+	params := listingParams(r)
+	params.OwnerID = r.URL.Query().Get("ownerId")
+	params.Status = r.URL.Query().Get("status")
+
+	walletsList, err := h.service.GetWalletsList(r.Context(), params)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(walletsList)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
+
+func (h *Handler) adjustBalance(w http.ResponseWriter, r *http.Request) {
+	var adjustment models.RequestAdjustment
+
+	err := json.NewDecoder(r.Body).Decode(&adjustment)
+	if errors.Is(err, money.ErrOverflow) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+
+	response, err := h.service.AdjustBalance(r.Context(), id, adjustment)
+	if errors.Is(err, postgres.ErrInvalidWalletID) || errors.Is(err, money.ErrPrecision) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if errors.Is(err, walletservice.ErrCurrencyNotValid) || errors.Is(err, postgres.ErrWalletNotFound) ||
+		errors.Is(err, walletservice.ErrSubBalanceNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if errors.Is(err, walletservice.ErrAdjustmentNotValid) || errors.Is(err, postgres.ErrOverdraft) ||
+		errors.Is(err, money.ErrOverflow) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrIdempotencyKeyReused) || errors.Is(err, postgres.ErrConcurrentUpdate) {
+		w.WriteHeader(http.StatusConflict)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
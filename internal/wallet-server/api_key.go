@@ -0,0 +1,133 @@
+package walletserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/postgres"
+	walletservice "github.com/AlexZav1327/service/internal/wallet-service"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	sessionInfo, ok := h.getSessionInfo(r)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	var request models.RequestAPIKey
+
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	apiKey, err := h.service.CreateAPIKey(r.Context(), sessionInfo.UUID, request)
+	if errors.Is(err, walletservice.ErrAPIKeyRequestNotValid) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrWalletNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+
+	err = json.NewEncoder(w).Encode(apiKey)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
+
+func (h *Handler) getAPIKeys(w http.ResponseWriter, r *http.Request) {
+	apiKeys, err := h.service.GetAPIKeys(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(apiKeys)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
+
+func (h *Handler) getAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	apiKey, err := h.service.GetAPIKey(r.Context(), id)
+	if errors.Is(err, postgres.ErrInvalidAPIKeyID) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrAPIKeyNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(apiKey)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
+
+func (h *Handler) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	err := h.service.RevokeAPIKey(r.Context(), id)
+	if errors.Is(err, postgres.ErrInvalidAPIKeyID) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrAPIKeyNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrAPIKeyRevoked) {
+		w.WriteHeader(http.StatusConflict)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
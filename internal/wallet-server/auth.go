@@ -0,0 +1,137 @@
+package walletserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/postgres"
+	walletservice "github.com/AlexZav1327/service/internal/wallet-service"
+)
+
+const tokenType = "Bearer"
+
+// issueTokens opens a session for the caller and returns its first access token with a refresh token.
+// Only identity provider tokens open sessions; a token the service issued itself would otherwise turn
+// a short-lived access token into a long-lived refresh token.
+func (h *Handler) issueTokens(w http.ResponseWriter, r *http.Request) {
+	sessionInfo, ok := h.getSessionInfo(r)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	if sessionInfo.SessionID != "" || sessionInfo.Issuer == h.serviceIssuer {
+		w.WriteHeader(http.StatusForbidden)
+
+		return
+	}
+
+	access := h.newAccessToken()
+
+	session, refreshToken, err := h.service.CreateSession(r.Context(), sessionInfo, access)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	h.writeTokens(w, http.StatusCreated, session, access, refreshToken)
+}
+
+func (h *Handler) refreshTokens(w http.ResponseWriter, r *http.Request) {
+	var request models.RequestRefresh
+
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	access := h.newAccessToken()
+
+	session, refreshToken, err := h.service.RefreshSession(r.Context(), request.RefreshToken, access)
+	if errors.Is(err, walletservice.ErrRefreshTokenNotValid) || errors.Is(err, postgres.ErrSessionNotFound) ||
+		errors.Is(err, postgres.ErrSessionRevoked) || errors.Is(err, postgres.ErrRefreshTokenReused) {
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	h.writeTokens(w, http.StatusOK, session, access, refreshToken)
+}
+
+func (h *Handler) logout(w http.ResponseWriter, r *http.Request) {
+	sessionInfo, ok := h.getSessionInfo(r)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	err := h.service.RevokeSession(r.Context(), sessionInfo)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) logoutAll(w http.ResponseWriter, r *http.Request) {
+	sessionInfo, ok := h.getSessionInfo(r)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	err := h.service.RevokeAllSessions(r.Context(), sessionInfo)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) writeTokens(w http.ResponseWriter, status int, session models.Session,
+	access models.AccessToken, refreshToken string,
+) {
+	accessToken, err := h.signToken(&Claims{
+		UUID:        session.Subject,
+		Email:       session.Email,
+		Roles:       session.Roles,
+		Permissions: session.Permissions,
+		SessionID:   session.SessionID.String(),
+	}, access)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(status)
+
+	err = json.NewEncoder(w).Encode(models.ResponseTokens{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        tokenType,
+		ExpiresAt:        access.ExpiresAt,
+		RefreshExpiresAt: session.ExpiresAt,
+	})
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
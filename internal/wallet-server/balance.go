@@ -0,0 +1,120 @@
+package walletserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/AlexZav1327/service/internal/postgres"
+	walletservice "github.com/AlexZav1327/service/internal/wallet-service"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) openSubBalance(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	currency := chi.URLParam(r, "currency")
+
+	wallet, err := h.service.OpenSubBalance(r.Context(), id, currency)
+	if errors.Is(err, postgres.ErrInvalidWalletID) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if errors.Is(err, walletservice.ErrCurrencyNotValid) || errors.Is(err, postgres.ErrWalletNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrPrimaryCurrency) {
+		w.WriteHeader(http.StatusConflict)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(wallet)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
+
+func (h *Handler) exchange(w http.ResponseWriter, r *http.Request) {
+	var exchange models.RequestExchange
+
+	err := json.NewDecoder(r.Body).Decode(&exchange)
+	if errors.Is(err, money.ErrOverflow) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if exchange.Amount.Sign() <= 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+
+	response, err := h.service.ExchangeFunds(r.Context(), id, exchange)
+	if errors.Is(err, money.ErrPrecision) || errors.Is(err, walletservice.ErrExchangeNotValid) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrOverdraft) || errors.Is(err, money.ErrOverflow) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrIdempotencyKeyReused) || errors.Is(err, postgres.ErrConcurrentUpdate) {
+		w.WriteHeader(http.StatusConflict)
+
+		return
+	}
+
+	if errors.Is(err, walletservice.ErrCurrencyNotValid) || errors.Is(err, postgres.ErrWalletNotFound) ||
+		errors.Is(err, walletservice.ErrSubBalanceNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if errors.Is(err, walletservice.ErrWalletFrozen) {
+		w.WriteHeader(http.StatusForbidden)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
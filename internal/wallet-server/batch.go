@@ -0,0 +1,81 @@
+package walletserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/AlexZav1327/service/internal/postgres"
+	walletservice "github.com/AlexZav1327/service/internal/wallet-service"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) batchTransfer(w http.ResponseWriter, r *http.Request) {
+	var batch models.RequestBatchTransfer
+
+	err := json.NewDecoder(r.Body).Decode(&batch)
+	if errors.Is(err, money.ErrOverflow) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+
+	response, err := h.service.BatchTransfer(r.Context(), id, batch)
+	if errors.Is(err, walletservice.ErrBatchNotValid) || errors.Is(err, walletservice.ErrBatchExceedsFunds) ||
+		errors.Is(err, money.ErrOverflow) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrInvalidWalletID) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrIdempotencyKeyReused) || errors.Is(err, postgres.ErrConcurrentUpdate) {
+		w.WriteHeader(http.StatusConflict)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrWalletNotFound) || errors.Is(err, walletservice.ErrSubBalanceNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if errors.Is(err, walletservice.ErrWalletFrozen) {
+		w.WriteHeader(http.StatusForbidden)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	if response.Status == models.BatchFailed {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
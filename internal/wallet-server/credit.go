@@ -0,0 +1,70 @@
+package walletserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/AlexZav1327/service/internal/postgres"
+	walletservice "github.com/AlexZav1327/service/internal/wallet-service"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) setCreditLimit(w http.ResponseWriter, r *http.Request) {
+	var credit models.RequestCreditLimit
+
+	err := json.NewDecoder(r.Body).Decode(&credit)
+	if errors.Is(err, money.ErrOverflow) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+
+	wallet, err := h.service.SetCreditLimit(r.Context(), id, credit)
+	if errors.Is(err, walletservice.ErrCreditLimitNotValid) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrInvalidWalletID) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrWalletNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrCreditLimitInUse) {
+		w.WriteHeader(http.StatusConflict)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(wallet)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
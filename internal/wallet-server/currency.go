@@ -0,0 +1,128 @@
+package walletserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/postgres"
+	walletservice "github.com/AlexZav1327/service/internal/wallet-service"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) getCurrencies(w http.ResponseWriter, r *http.Request) {
+	currencies, err := h.service.GetCurrencies(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(currencies)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
+
+func (h *Handler) getCurrency(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+
+	currency, err := h.service.GetCurrency(r.Context(), code)
+	if errors.Is(err, postgres.ErrCurrencyNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(currency)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
+
+func (h *Handler) createCurrency(w http.ResponseWriter, r *http.Request) {
+	var currency models.RequestCurrency
+
+	err := json.NewDecoder(r.Body).Decode(&currency)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	createdCurrency, err := h.service.CreateCurrency(r.Context(), currency)
+	if errors.Is(err, walletservice.ErrCurrencySettingsNotValid) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrCurrencyExists) {
+		w.WriteHeader(http.StatusConflict)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+
+	err = json.NewEncoder(w).Encode(createdCurrency)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
+
+func (h *Handler) updateCurrency(w http.ResponseWriter, r *http.Request) {
+	var currency models.RequestCurrency
+
+	err := json.NewDecoder(r.Body).Decode(&currency)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	code := chi.URLParam(r, "code")
+
+	updatedCurrency, err := h.service.UpdateCurrency(r.Context(), code, currency)
+	if errors.Is(err, walletservice.ErrCurrencySettingsNotValid) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrCurrencyNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(updatedCurrency)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
 	"github.com/AlexZav1327/service/internal/postgres"
 	walletservice "github.com/AlexZav1327/service/internal/wallet-service"
 	"github.com/go-chi/chi/v5"
@@ -18,44 +19,131 @@ import (
 )
 
 const (
-	defaultLimit     = 20
-	defaultTimeRange = 24
-	timeFormatLayout = "2006-01-02T15:04:05"
+	defaultAccessTTL     = 15 * time.Minute
+	defaultServiceIssuer = "wallets-service"
+	defaultLimit         = 20
+	defaultTimeRange     = 24
+	timeFormatLayout     = "2006-01-02T15:04:05"
 )
 
 type Handler struct {
-	service    WalletService
-	log        *logrus.Entry
-	metrics    *metrics
-	privateKey *rsa.PrivateKey
-	publicKey  *rsa.PublicKey
+	service       WalletService
+	log           *logrus.Entry
+	metrics       *metrics
+	privateKey    *rsa.PrivateKey
+	keyID         string
+	keys          KeySource
+	issuer        string
+	serviceIssuer string
+	audience      string
+	accessTTL     time.Duration
+}
+
+// Config sets the issuer and audience tokens must carry. Empty values are not checked. The service's
+// own tokens carry ServiceIssuer instead of Issuer, and AccessTTL is their lifetime.
+type Config struct {
+	Issuer        string
+	ServiceIssuer string
+	Audience      string
+	AccessTTL     time.Duration
+}
+
+type KeySource interface {
+	Key(ctx context.Context, kid string) (*rsa.PublicKey, error)
 }
 
 type WalletService interface {
 	CreateWallet(ctx context.Context, wallet models.RequestWalletInstance) (models.ResponseWalletInstance, error)
 	GetWallet(ctx context.Context, id string) (models.ResponseWalletInstance, error)
+	ClaimWalletOwner(ctx context.Context, id, subject, email string) (string, error)
 	GetWalletsList(ctx context.Context, params models.ListingQueryParams) ([]models.ResponseWalletInstance, error)
-	GetWalletHistory(ctx context.Context, id string, params models.RequestWalletHistory) (
+	GetWalletHistory(ctx context.Context, params models.RequestWalletHistory) (
 		[]models.ResponseWalletHistory, error)
 	UpdateWallet(ctx context.Context, wallet models.RequestWalletInstance) (models.ResponseWalletInstance, error)
-	DeleteWallet(ctx context.Context, id string) error
+	DeleteWallet(ctx context.Context, id string, sweepTo uuid.NullUUID) error
 	DepositFunds(ctx context.Context, id string, depositFunds models.FundsOperations) (
-		models.ResponseWalletInstance, error)
+		models.ResponseFundsOperation, error)
 	WithdrawFunds(ctx context.Context, id string, withdrawFunds models.FundsOperations) (
-		models.ResponseWalletInstance, error)
+		models.ResponseFundsOperation, error)
 	TransferFunds(ctx context.Context, idSrc, idDst string, transferFunds models.FundsOperations) (
+		models.ResponseTransfer, error)
+	BatchTransfer(ctx context.Context, idSrc string, batch models.RequestBatchTransfer) (
+		models.ResponseBatchTransfer, error)
+	GetTransaction(ctx context.Context, id string) (models.Transaction, error)
+	CreateQuote(ctx context.Context, request models.RequestQuote) (models.Quote, error)
+	GetQuote(ctx context.Context, id string) (models.Quote, error)
+	CreateSession(ctx context.Context, info models.SessionInfo, access models.AccessToken) (
+		models.Session, string, error)
+	RefreshSession(ctx context.Context, refreshToken string, access models.AccessToken) (
+		models.Session, string, error)
+	RevokeSession(ctx context.Context, info models.SessionInfo) error
+	RevokeAllSessions(ctx context.Context, info models.SessionInfo) error
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+	CreateAPIKey(ctx context.Context, createdBy string, request models.RequestAPIKey) (models.ResponseAPIKey, error)
+	GetAPIKey(ctx context.Context, id string) (models.APIKey, error)
+	GetAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	AuthenticateAPIKey(ctx context.Context, key string) (models.APIKey, error)
+	ReverseTransaction(ctx context.Context, id string, reversal models.RequestReversal) (
+		models.ResponseReversal, error)
+	AdjustBalance(ctx context.Context, id string, adjustment models.RequestAdjustment) (
+		models.ResponseFundsOperation, error)
+	GetCurrencies(ctx context.Context) ([]models.Currency, error)
+	GetCurrency(ctx context.Context, code string) (models.Currency, error)
+	CreateCurrency(ctx context.Context, currency models.RequestCurrency) (models.Currency, error)
+	UpdateCurrency(ctx context.Context, code string, currency models.RequestCurrency) (models.Currency, error)
+	GetWalletTransactions(ctx context.Context, id string, params models.RequestTransactionsList) (
+		[]models.Transaction, error)
+	AuthorizeHold(ctx context.Context, id string, authorize models.RequestAuthorize) (models.ResponseHold, error)
+	GetHold(ctx context.Context, id string) (models.Hold, error)
+	CaptureHold(ctx context.Context, id string, capture models.RequestCapture) (models.ResponseHold, error)
+	VoidHold(ctx context.Context, id string) (models.ResponseHold, error)
+	GetRemainingLimits(ctx context.Context, id string) (models.ResponseRemainingLimits, error)
+	GetCurrencyLimits(ctx context.Context, code string) (models.SpendingLimits, error)
+	SetCurrencyLimits(ctx context.Context, code string, limits models.SpendingLimits) (models.SpendingLimits, error)
+	GetWalletLimits(ctx context.Context, id string) (models.SpendingLimits, error)
+	SetWalletLimits(ctx context.Context, id string, limits models.SpendingLimits) (models.SpendingLimits, error)
+	SetCreditLimit(ctx context.Context, id string, credit models.RequestCreditLimit) (
+		models.ResponseWalletInstance, error)
+	OpenSubBalance(ctx context.Context, id, currency string) (models.ResponseWalletInstance, error)
+	ExchangeFunds(ctx context.Context, id string, exchange models.RequestExchange) (
+		models.ResponseFundsOperation, error)
+	ChangeWalletStatus(ctx context.Context, id string, change models.RequestWalletStatus) (
 		models.ResponseWalletInstance, error)
+	RestoreWallet(ctx context.Context, id string, restore models.RequestWalletRestore) (
+		models.ResponseWalletInstance, error)
+	CreateStandingOrder(ctx context.Context, id string, request models.RequestStandingOrder) (
+		models.StandingOrder, error)
+	GetStandingOrder(ctx context.Context, id string) (models.StandingOrder, error)
+	GetWalletStandingOrders(ctx context.Context, id string) ([]models.StandingOrder, error)
+	UpdateStandingOrder(ctx context.Context, id string, request models.RequestStandingOrder) (
+		models.StandingOrder, error)
+	CancelStandingOrder(ctx context.Context, id string) error
+	GetStandingOrderExecutions(ctx context.Context, id string) ([]models.StandingOrderExecution, error)
 }
 
-func NewHandler(service WalletService, log *logrus.Logger, privateKey *rsa.PrivateKey,
-	publicKey *rsa.PublicKey,
+func NewHandler(service WalletService, log *logrus.Logger, privateKey *rsa.PrivateKey, keys KeySource,
+	config Config,
 ) *Handler {
+	if config.AccessTTL <= 0 {
+		config.AccessTTL = defaultAccessTTL
+	}
+
+	if config.ServiceIssuer == "" {
+		config.ServiceIssuer = defaultServiceIssuer
+	}
+
 	return &Handler{
-		service:    service,
-		log:        log.WithField("module", "handler"),
-		metrics:    newMetrics(),
-		privateKey: privateKey,
-		publicKey:  publicKey,
+		service:       service,
+		log:           log.WithField("module", "handler"),
+		metrics:       newMetrics(),
+		privateKey:    privateKey,
+		keyID:         keyIDOf(&privateKey.PublicKey),
+		keys:          keys,
+		issuer:        config.Issuer,
+		serviceIssuer: config.ServiceIssuer,
+		audience:      config.Audience,
+		accessTTL:     config.AccessTTL,
 	}
 }
 
@@ -69,10 +157,18 @@ func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sessionInfo, ok := h.getSessionInfo(r)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
 	wallet.WalletID = uuid.New()
+	wallet.OwnerID = sessionInfo.UUID
 
 	createdWallet, err := h.service.CreateWallet(r.Context(), wallet)
-	if errors.Is(err, postgres.ErrRequestNotIdempotent) || errors.Is(err, postgres.ErrEmailNotUnique) {
+	if errors.Is(err, postgres.ErrIdempotencyKeyReused) || errors.Is(err, postgres.ErrEmailNotUnique) {
 		w.WriteHeader(http.StatusConflict)
 
 		return
@@ -129,17 +225,17 @@ func (h *Handler) get(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) getList(w http.ResponseWriter, r *http.Request) {
-	params := models.ListingQueryParams{}
-	params.TextFilter = r.URL.Query().Get("textFilter")
+	params := listingParams(r)
 
-	params.ItemsPerPage, _ = strconv.Atoi(r.URL.Query().Get("itemsPerPage"))
-	if params.ItemsPerPage == 0 {
-		params.ItemsPerPage = defaultLimit
+	sessionInfo, ok := h.getSessionInfo(r)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
 	}
 
-	params.Offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
-	params.Sorting = r.URL.Query().Get("sorting")
-	params.Descending, _ = strconv.ParseBool(r.URL.Query().Get("descending"))
+	params.OwnerID = sessionInfo.UUID
+	params.OwnerEmail = sessionInfo.Email
 
 	walletsList, err := h.service.GetWalletsList(r.Context(), params)
 	if err != nil {
@@ -157,13 +253,7 @@ func (h *Handler) getList(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) getHistory(w http.ResponseWriter, r *http.Request) {
-	params := models.RequestWalletHistory{}
-	params.TextFilter = r.URL.Query().Get("textFilter")
-
-	params.ItemsPerPage, _ = strconv.Atoi(r.URL.Query().Get("itemsPerPage"))
-	if params.ItemsPerPage == 0 {
-		params.ItemsPerPage = defaultLimit
-	}
+	params := models.RequestWalletHistory{ListingQueryParams: listingParams(r)}
 
 	params.PeriodStart = time.Now().Add(-defaultTimeRange * time.Hour)
 	if r.URL.Query().Get("periodStart") != "" {
@@ -175,10 +265,6 @@ func (h *Handler) getHistory(w http.ResponseWriter, r *http.Request) {
 		params.PeriodEnd, _ = time.Parse(timeFormatLayout, r.URL.Query().Get("periodEnd"))
 	}
 
-	params.Offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
-	params.Sorting = r.URL.Query().Get("sorting")
-	params.Descending, _ = strconv.ParseBool(r.URL.Query().Get("descending"))
-
 	sessionInfo, ok := h.getSessionInfo(r)
 	if !ok {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -186,9 +272,10 @@ func (h *Handler) getHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id := sessionInfo.UUID
+	params.OwnerID = sessionInfo.UUID
+	params.OwnerEmail = sessionInfo.Email
 
-	walletHistory, err := h.service.GetWalletHistory(r.Context(), id, params)
+	walletHistory, err := h.service.GetWalletHistory(r.Context(), params)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 
@@ -224,12 +311,19 @@ func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if errors.Is(err, postgres.ErrEmailNotUnique) {
+	if errors.Is(err, postgres.ErrEmailNotUnique) || errors.Is(err, postgres.ErrConcurrentUpdate) ||
+		errors.Is(err, postgres.ErrWalletHasHolds) || errors.Is(err, postgres.ErrWalletHasCreditLine) {
 		w.WriteHeader(http.StatusConflict)
 
 		return
 	}
 
+	if errors.Is(err, money.ErrOverflow) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 
@@ -247,13 +341,43 @@ func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) delete(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	err := h.service.DeleteWallet(r.Context(), id)
+	var sweepTo uuid.NullUUID
+
+	if r.URL.Query().Get("sweepTo") != "" {
+		err := sweepTo.UnmarshalText([]byte(r.URL.Query().Get("sweepTo")))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	err := h.service.DeleteWallet(r.Context(), id, sweepTo)
+	if errors.Is(err, postgres.ErrInvalidWalletID) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
 	if errors.Is(err, postgres.ErrWalletNotFound) {
 		w.WriteHeader(http.StatusNotFound)
 
 		return
 	}
 
+	if errors.Is(err, walletservice.ErrSweepTargetNotValid) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if errors.Is(err, walletservice.ErrStatusChangeNotAllowed) || errors.Is(err, postgres.ErrWalletHasBalance) ||
+		errors.Is(err, postgres.ErrWalletHasHolds) || errors.Is(err, postgres.ErrConcurrentUpdate) {
+		w.WriteHeader(http.StatusConflict)
+
+		return
+	}
+
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 
@@ -267,13 +391,19 @@ func (h *Handler) deposit(w http.ResponseWriter, r *http.Request) {
 	var depositFunds models.FundsOperations
 
 	err := json.NewDecoder(r.Body).Decode(&depositFunds)
+	if errors.Is(err, money.ErrOverflow) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 
 		return
 	}
 
-	if depositFunds.Amount <= 0 {
+	if depositFunds.Amount.Sign() <= 0 {
 		w.WriteHeader(http.StatusUnprocessableEntity)
 
 		return
@@ -281,19 +411,40 @@ func (h *Handler) deposit(w http.ResponseWriter, r *http.Request) {
 
 	id := chi.URLParam(r, "id")
 
-	updatedWallet, err := h.service.DepositFunds(r.Context(), id, depositFunds)
-	if errors.Is(err, postgres.ErrRequestNotIdempotent) {
+	response, err := h.service.DepositFunds(r.Context(), id, depositFunds)
+	if errors.Is(err, money.ErrPrecision) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if errors.Is(err, money.ErrOverflow) || errors.Is(err, walletservice.ErrFeeExceedsAmount) ||
+		errors.Is(err, walletservice.ErrQuoteMismatch) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrIdempotencyKeyReused) || errors.Is(err, postgres.ErrConcurrentUpdate) ||
+		errors.Is(err, postgres.ErrQuoteUsed) {
 		w.WriteHeader(http.StatusConflict)
 
 		return
 	}
 
-	if errors.Is(err, walletservice.ErrCurrencyNotValid) || errors.Is(err, postgres.ErrWalletNotFound) {
+	if errors.Is(err, walletservice.ErrCurrencyNotValid) || errors.Is(err, postgres.ErrWalletNotFound) ||
+		errors.Is(err, postgres.ErrQuoteNotFound) {
 		w.WriteHeader(http.StatusNotFound)
 
 		return
 	}
 
+	if errors.Is(err, postgres.ErrQuoteExpired) {
+		w.WriteHeader(http.StatusGone)
+
+		return
+	}
+
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 
@@ -302,7 +453,7 @@ func (h *Handler) deposit(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 
-	err = json.NewEncoder(w).Encode(updatedWallet)
+	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
 		h.log.Warningf("json.NewEncoder.Encode: %s", err)
 	}
@@ -312,13 +463,19 @@ func (h *Handler) withdraw(w http.ResponseWriter, r *http.Request) {
 	var withdrawFunds models.FundsOperations
 
 	err := json.NewDecoder(r.Body).Decode(&withdrawFunds)
+	if errors.Is(err, money.ErrOverflow) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 
 		return
 	}
 
-	if withdrawFunds.Amount <= 0 {
+	if withdrawFunds.Amount.Sign() <= 0 {
 		w.WriteHeader(http.StatusUnprocessableEntity)
 
 		return
@@ -326,25 +483,52 @@ func (h *Handler) withdraw(w http.ResponseWriter, r *http.Request) {
 
 	id := chi.URLParam(r, "id")
 
-	updatedWallet, err := h.service.WithdrawFunds(r.Context(), id, withdrawFunds)
-	if errors.Is(err, walletservice.ErrOverdraft) {
+	response, err := h.service.WithdrawFunds(r.Context(), id, withdrawFunds)
+	if errors.Is(err, money.ErrPrecision) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrSpendingLimitExceeded) {
+		w.WriteHeader(http.StatusPaymentRequired)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrOverdraft) || errors.Is(err, money.ErrOverflow) ||
+		errors.Is(err, walletservice.ErrQuoteMismatch) {
 		w.WriteHeader(http.StatusUnprocessableEntity)
 
 		return
 	}
 
-	if errors.Is(err, postgres.ErrRequestNotIdempotent) {
+	if errors.Is(err, postgres.ErrIdempotencyKeyReused) || errors.Is(err, postgres.ErrConcurrentUpdate) ||
+		errors.Is(err, postgres.ErrQuoteUsed) {
 		w.WriteHeader(http.StatusConflict)
 
 		return
 	}
 
-	if errors.Is(err, walletservice.ErrCurrencyNotValid) || errors.Is(err, postgres.ErrWalletNotFound) {
+	if errors.Is(err, walletservice.ErrCurrencyNotValid) || errors.Is(err, postgres.ErrWalletNotFound) ||
+		errors.Is(err, walletservice.ErrSubBalanceNotFound) || errors.Is(err, postgres.ErrQuoteNotFound) {
 		w.WriteHeader(http.StatusNotFound)
 
 		return
 	}
 
+	if errors.Is(err, walletservice.ErrWalletFrozen) {
+		w.WriteHeader(http.StatusForbidden)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrQuoteExpired) {
+		w.WriteHeader(http.StatusGone)
+
+		return
+	}
+
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 
@@ -353,7 +537,7 @@ func (h *Handler) withdraw(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 
-	err = json.NewEncoder(w).Encode(updatedWallet)
+	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
 		h.log.Warningf("json.NewEncoder.Encode: %s", err)
 	}
@@ -363,13 +547,19 @@ func (h *Handler) transfer(w http.ResponseWriter, r *http.Request) {
 	var transferFunds models.FundsOperations
 
 	err := json.NewDecoder(r.Body).Decode(&transferFunds)
+	if errors.Is(err, money.ErrOverflow) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 
 		return
 	}
 
-	if transferFunds.Amount <= 0 {
+	if transferFunds.Amount.Sign() <= 0 {
 		w.WriteHeader(http.StatusUnprocessableEntity)
 
 		return
@@ -378,25 +568,52 @@ func (h *Handler) transfer(w http.ResponseWriter, r *http.Request) {
 	idSrc := chi.URLParam(r, "idSrc")
 	idDst := chi.URLParam(r, "idDst")
 
-	dstWallet, err := h.service.TransferFunds(r.Context(), idSrc, idDst, transferFunds)
-	if errors.Is(err, walletservice.ErrOverdraft) {
+	response, err := h.service.TransferFunds(r.Context(), idSrc, idDst, transferFunds)
+	if errors.Is(err, money.ErrPrecision) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrSpendingLimitExceeded) {
+		w.WriteHeader(http.StatusPaymentRequired)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrOverdraft) || errors.Is(err, money.ErrOverflow) ||
+		errors.Is(err, walletservice.ErrQuoteMismatch) {
 		w.WriteHeader(http.StatusUnprocessableEntity)
 
 		return
 	}
 
-	if errors.Is(err, postgres.ErrRequestNotIdempotent) {
+	if errors.Is(err, postgres.ErrIdempotencyKeyReused) || errors.Is(err, postgres.ErrConcurrentUpdate) ||
+		errors.Is(err, postgres.ErrQuoteUsed) {
 		w.WriteHeader(http.StatusConflict)
 
 		return
 	}
 
-	if errors.Is(err, walletservice.ErrCurrencyNotValid) || errors.Is(err, postgres.ErrWalletNotFound) {
+	if errors.Is(err, walletservice.ErrCurrencyNotValid) || errors.Is(err, postgres.ErrWalletNotFound) ||
+		errors.Is(err, walletservice.ErrSubBalanceNotFound) || errors.Is(err, postgres.ErrQuoteNotFound) {
 		w.WriteHeader(http.StatusNotFound)
 
 		return
 	}
 
+	if errors.Is(err, walletservice.ErrWalletFrozen) {
+		w.WriteHeader(http.StatusForbidden)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrQuoteExpired) {
+		w.WriteHeader(http.StatusGone)
+
+		return
+	}
+
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 
@@ -405,7 +622,81 @@ func (h *Handler) transfer(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 
-	err = json.NewEncoder(w).Encode(dstWallet)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
+
+func (h *Handler) getTransaction(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	transaction, err := h.service.GetTransaction(r.Context(), id)
+	if errors.Is(err, postgres.ErrInvalidTransactionID) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrTransactionNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(transaction)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
+
+func (h *Handler) getWalletTransactions(w http.ResponseWriter, r *http.Request) {
+	params := models.RequestTransactionsList{ListingQueryParams: listingParams(r)}
+	params.OperationType = r.URL.Query().Get("type")
+	params.Currency = r.URL.Query().Get("currency")
+	// Transactions have no owner to match a text filter against.
+	params.TextFilter = ""
+
+	if r.URL.Query().Get("periodStart") != "" {
+		params.PeriodStart, _ = time.Parse(timeFormatLayout, r.URL.Query().Get("periodStart"))
+	}
+
+	if r.URL.Query().Get("periodEnd") != "" {
+		params.PeriodEnd, _ = time.Parse(timeFormatLayout, r.URL.Query().Get("periodEnd"))
+	}
+
+	id := chi.URLParam(r, "id")
+
+	transactions, err := h.service.GetWalletTransactions(r.Context(), id, params)
+	if errors.Is(err, postgres.ErrInvalidWalletID) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrWalletNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(transactions)
 	if err != nil {
 		h.log.Warningf("json.NewEncoder.Encode: %s", err)
 	}
@@ -421,3 +712,19 @@ func (h *Handler) getSessionInfo(r *http.Request) (models.SessionInfo, bool) {
 
 	return sessionInfo, true
 }
+
+func listingParams(r *http.Request) models.ListingQueryParams {
+	params := models.ListingQueryParams{}
+	params.TextFilter = r.URL.Query().Get("textFilter")
+
+	params.ItemsPerPage, _ = strconv.Atoi(r.URL.Query().Get("itemsPerPage"))
+	if params.ItemsPerPage == 0 {
+		params.ItemsPerPage = defaultLimit
+	}
+
+	params.Offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+	params.Sorting = r.URL.Query().Get("sorting")
+	params.Descending, _ = strconv.ParseBool(r.URL.Query().Get("descending"))
+
+	return params
+}
@@ -0,0 +1,227 @@
+package walletserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/AlexZav1327/service/internal/postgres"
+	walletservice "github.com/AlexZav1327/service/internal/wallet-service"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) authorizeHold(w http.ResponseWriter, r *http.Request) {
+	var authorize models.RequestAuthorize
+
+	err := json.NewDecoder(r.Body).Decode(&authorize)
+	if errors.Is(err, money.ErrOverflow) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if authorize.Amount.Sign() <= 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+
+	response, err := h.service.AuthorizeHold(r.Context(), id, authorize)
+	if errors.Is(err, money.ErrPrecision) || errors.Is(err, postgres.ErrInvalidWalletID) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrOverdraft) || errors.Is(err, money.ErrOverflow) ||
+		errors.Is(err, walletservice.ErrHoldTTLNotValid) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrIdempotencyKeyReused) || errors.Is(err, postgres.ErrConcurrentUpdate) {
+		w.WriteHeader(http.StatusConflict)
+
+		return
+	}
+
+	if errors.Is(err, walletservice.ErrCurrencyNotValid) || errors.Is(err, postgres.ErrWalletNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if errors.Is(err, walletservice.ErrWalletFrozen) {
+		w.WriteHeader(http.StatusForbidden)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
+
+func (h *Handler) getHold(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	hold, err := h.service.GetHold(r.Context(), id)
+	if errors.Is(err, postgres.ErrInvalidHoldID) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrHoldNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(hold)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
+
+func (h *Handler) captureHold(w http.ResponseWriter, r *http.Request) {
+	var capture models.RequestCapture
+
+	err := json.NewDecoder(r.Body).Decode(&capture)
+	if errors.Is(err, money.ErrOverflow) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if capture.Amount.Sign() < 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+
+	response, err := h.service.CaptureHold(r.Context(), id, capture)
+	if errors.Is(err, money.ErrPrecision) || errors.Is(err, postgres.ErrInvalidHoldID) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrSpendingLimitExceeded) {
+		w.WriteHeader(http.StatusPaymentRequired)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrOverdraft) || errors.Is(err, money.ErrOverflow) ||
+		errors.Is(err, walletservice.ErrCaptureExceedsHold) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrIdempotencyKeyReused) || errors.Is(err, postgres.ErrConcurrentUpdate) ||
+		errors.Is(err, postgres.ErrHoldNotActive) || errors.Is(err, postgres.ErrHoldExpired) ||
+		errors.Is(err, postgres.ErrHoldChanged) {
+		w.WriteHeader(http.StatusConflict)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrHoldNotFound) || errors.Is(err, postgres.ErrWalletNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if errors.Is(err, walletservice.ErrWalletFrozen) {
+		w.WriteHeader(http.StatusForbidden)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
+
+func (h *Handler) voidHold(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	response, err := h.service.VoidHold(r.Context(), id)
+	if errors.Is(err, postgres.ErrInvalidHoldID) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrHoldNotActive) || errors.Is(err, postgres.ErrHoldChanged) {
+		w.WriteHeader(http.StatusConflict)
+
+		return
+	}
+
+	if errors.Is(err, postgres.ErrHoldNotFound) || errors.Is(err, postgres.ErrWalletNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.log.Warningf("json.NewEncoder.Encode: %s", err)
+	}
+}
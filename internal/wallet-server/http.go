@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/AlexZav1327/service/internal/models"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -24,9 +25,9 @@ type Server struct {
 }
 
 func New(host string, port int, service WalletService, log *logrus.Logger, privateKey *rsa.PrivateKey,
-	publicKey *rsa.PublicKey,
+	keys KeySource, config Config,
 ) *Server {
-	h := NewHandler(service, log, privateKey, publicKey)
+	h := NewHandler(service, log, privateKey, keys, config)
 
 	server := Server{
 		host:    host,
@@ -42,18 +43,103 @@ func New(host string, port int, service WalletService, log *logrus.Logger, priva
 	r.Get("/metrics", promhttp.Handler().ServeHTTP)
 	r.Group(func(r chi.Router) {
 		r.Use(h.metric)
-		r.Use(h.jwtAuth)
 		r.Route("/api/v1", func(r chi.Router) {
 			r.Use(middleware.RequestLogger(&middleware.DefaultLogFormatter{Logger: log, NoColor: true}))
-			r.Post("/wallet/create", h.create)
-			r.Get("/wallet/{id}", h.get)
-			r.Get("/wallets", h.getList)
-			r.Get("/wallet/history", h.getHistory)
-			r.Patch("/wallet/update/{id}", h.update)
-			r.Delete("/wallet/delete/{id}", h.delete)
-			r.Put("/wallet/{id}/deposit", h.deposit)
-			r.Put("/wallet/{id}/withdraw", h.withdraw)
-			r.Put("/wallet/{idSrc}/transfer/{idDst}", h.transfer)
+			r.Route("/auth", func(r chi.Router) {
+				r.Post("/refresh", h.refreshTokens)
+				r.With(h.jwtAuth).Post("/token", h.issueTokens)
+				r.With(h.jwtAuth).Post("/logout", h.logout)
+				r.With(h.jwtAuth).Post("/logout-all", h.logoutAll)
+			})
+			r.Group(func(r chi.Router) {
+				r.Use(h.apiKeyAuth)
+				r.Use(h.jwtAuth)
+				r.Group(func(r chi.Router) {
+					r.Use(h.requirePermission(models.PermissionSelfService))
+					r.Post("/wallet/create", h.create)
+					r.Get("/wallets", h.getList)
+					r.Get("/wallet/history", h.getHistory)
+					r.Post("/quotes", h.createQuote)
+					r.Get("/quotes/{id}", h.getQuote)
+				})
+				r.Group(func(r chi.Router) {
+					r.Use(h.walletAccess("id", h.wallet, models.PermissionReadWallets, models.PermissionKeyRead))
+					r.Get("/wallet/{id}", h.get)
+					r.Get("/wallet/{id}/transactions", h.getWalletTransactions)
+					r.Get("/wallet/{id}/limits", h.getRemainingLimits)
+					r.Get("/wallet/{id}/standing-orders", h.getWalletStandingOrders)
+				})
+				r.Group(func(r chi.Router) {
+					r.Use(h.walletAccess("id", h.wallet, models.PermissionOperateWallets))
+					r.Patch("/wallet/update/{id}", h.update)
+					r.Delete("/wallet/delete/{id}", h.delete)
+					r.Put("/wallet/{id}/withdraw", h.withdraw)
+					r.Put("/wallet/{id}/balances/{currency}", h.openSubBalance)
+					r.Put("/wallet/{id}/exchange", h.exchange)
+					r.Post("/wallet/{id}/holds", h.authorizeHold)
+					r.Post("/wallet/{id}/standing-orders", h.createStandingOrder)
+				})
+				r.With(h.walletAccess("id", h.wallet, models.PermissionOperateWallets, models.PermissionKeyDeposit)).
+					Put("/wallet/{id}/deposit", h.deposit)
+				r.With(h.walletAccess("id", h.wallet, models.PermissionOperateWallets, models.PermissionKeyTransfer)).
+					Put("/wallet/{id}/batch-transfer", h.batchTransfer)
+				r.With(h.walletAccess("idSrc", h.wallet, models.PermissionOperateWallets, models.PermissionKeyTransfer)).
+					Put("/wallet/{idSrc}/transfer/{idDst}", h.transfer)
+				r.With(h.walletAccess("id", h.transactionWallets, models.PermissionReadWallets, models.PermissionKeyRead)).
+					Get("/transactions/{id}", h.getTransaction)
+				r.With(h.walletAccess("id", h.holdWallet, models.PermissionReadWallets, models.PermissionKeyRead)).
+					Get("/holds/{id}", h.getHold)
+				r.Group(func(r chi.Router) {
+					r.Use(h.walletAccess("id", h.holdWallet, models.PermissionOperateWallets))
+					r.Put("/holds/{id}/capture", h.captureHold)
+					r.Put("/holds/{id}/void", h.voidHold)
+				})
+				r.Group(func(r chi.Router) {
+					r.Use(h.walletAccess("id", h.standingOrderWallet, models.PermissionReadWallets, models.PermissionKeyRead))
+					r.Get("/standing-orders/{id}", h.getStandingOrder)
+					r.Get("/standing-orders/{id}/executions", h.getStandingOrderExecutions)
+				})
+				r.Group(func(r chi.Router) {
+					r.Use(h.walletAccess("id", h.standingOrderWallet, models.PermissionOperateWallets))
+					r.Put("/standing-orders/{id}", h.updateStandingOrder)
+					r.Delete("/standing-orders/{id}", h.cancelStandingOrder)
+				})
+				r.Route("/admin", func(r chi.Router) {
+					read := h.requirePermission(models.PermissionReadWallets)
+					manageStatus := h.requirePermission(models.PermissionManageStatus)
+					adjust := h.requirePermission(models.PermissionAdjustBalances)
+					manageCurrencies := h.requirePermission(models.PermissionManageCurrencies)
+					manageAPIKeys := h.requirePermission(models.PermissionManageAPIKeys)
+
+					r.Route("/currencies", func(r chi.Router) {
+						r.With(read).Get("/", h.getCurrencies)
+						r.With(manageCurrencies).Post("/", h.createCurrency)
+						r.With(read).Get("/{code}", h.getCurrency)
+						r.With(manageCurrencies).Patch("/{code}", h.updateCurrency)
+						r.With(read).Get("/{code}/limits", h.getCurrencyLimits)
+						r.With(manageCurrencies).Put("/{code}/limits", h.setCurrencyLimits)
+					})
+					r.Route("/wallets", func(r chi.Router) {
+						r.With(read).Get("/", h.getAllWallets)
+						r.With(read).Get("/{id}/limits", h.getWalletLimits)
+						r.With(adjust).Put("/{id}/limits", h.setWalletLimits)
+						r.With(adjust).Put("/{id}/credit-limit", h.setCreditLimit)
+						r.With(adjust).Post("/{id}/adjustments", h.adjustBalance)
+						r.With(manageStatus).Put("/{id}/status", h.changeWalletStatus)
+						r.With(manageStatus).Put("/{id}/restore", h.restoreWallet)
+					})
+					r.Route("/transactions", func(r chi.Router) {
+						r.With(adjust).Put("/{id}/reverse", h.reverseTransaction)
+					})
+					r.Route("/api-keys", func(r chi.Router) {
+						r.Use(manageAPIKeys)
+						r.Post("/", h.createAPIKey)
+						r.Get("/", h.getAPIKeys)
+						r.Get("/{id}", h.getAPIKey)
+						r.Delete("/{id}", h.revokeAPIKey)
+					})
+				})
+			})
 		})
 	})
 
@@ -91,6 +177,6 @@ func (s *Server) Run(ctx context.Context) error {
 	return nil
 }
 
-func (s *Server) GenerateToken(uuid, email string) (string, error) {
-	return s.handler.generateToken(uuid, email)
+func (s *Server) GenerateToken(uuid, email string, roles ...string) (string, error) {
+	return s.handler.generateToken(uuid, email, roles)
 }
@@ -3,41 +3,76 @@ package walletserver
 import (
 	"context"
 	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/AlexZav1327/service/internal/jwks"
 	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/postgres"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyHeader  = "X-API-Key"
+	apiKeySubject = "apikey:"
 )
 
 var (
 	ErrInvalidToken         = errors.New("invalid token")
 	ErrInvalidSigningMethod = errors.New("invalid signing method")
+	ErrInvalidIssuer        = errors.New("invalid issuer")
 )
 
 type Claims struct {
 	jwt.RegisteredClaims
-	UUID  string
-	Email string
+	UUID        string
+	Email       string
+	Roles       []string `json:",omitempty"`
+	Permissions []string `json:",omitempty"`
+	SessionID   string   `json:",omitempty"`
+}
+
+func (h *Handler) generateToken(subject, email string, roles []string) (string, error) {
+	return h.signToken(&Claims{UUID: subject, Email: email, Roles: roles}, h.newAccessToken())
+}
+
+func (h *Handler) newAccessToken() models.AccessToken {
+	return models.AccessToken{TokenID: uuid.New(), ExpiresAt: time.Now().Add(h.accessTTL)}
+}
+
+// keyIDOf names the service's signing key by the SHA-256 thumbprint of its public key.
+func keyIDOf(key *rsa.PublicKey) string {
+	sum := sha256.Sum256(x509.MarshalPKCS1PublicKey(key))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
 }
 
-func (h *Handler) generateToken(uuid, email string) (string, error) {
-	claims := &Claims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
-		UUID:  uuid,
-		Email: email,
+// signToken sets the registered claims of access on claims and signs them with the private key. The
+// token names the service as its issuer and carries the kid of the service's key.
+func (h *Handler) signToken(claims *Claims, access models.AccessToken) (string, error) {
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        access.TokenID.String(),
+		Issuer:    h.serviceIssuer,
+		ExpiresAt: jwt.NewNumericDate(access.ExpiresAt),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		NotBefore: jwt.NewNumericDate(time.Now()),
+	}
+
+	if h.audience != "" {
+		claims.Audience = jwt.ClaimStrings{h.audience}
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = h.keyID
 
 	tokenString, err := token.SignedString(h.privateKey)
 	if err != nil {
@@ -47,25 +82,72 @@ func (h *Handler) generateToken(uuid, email string) (string, error) {
 	return tokenString, nil
 }
 
-func (h *Handler) verifyToken(accessToken string, publicKey *rsa.PublicKey) (models.SessionInfo, error) {
+// verifyToken checks the token against the key named by its kid header, and against the configured
+// issuer and audience. Tokens signed with the service's own key must name the service as their
+// issuer, and no other token may. Tokens that fail any check are reported as ErrInvalidToken; only a
+// key set that cannot be loaded is reported as a server error.
+func (h *Handler) verifyToken(ctx context.Context, accessToken string) (models.SessionInfo, error) {
 	var sessionInfo models.SessionInfo
 
+	var options []jwt.ParserOption
+
+	if h.audience != "" {
+		options = append(options, jwt.WithAudience(h.audience))
+	}
+
 	token, err := jwt.ParseWithClaims(accessToken, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		_, ok := token.Method.(*jwt.SigningMethodRSA)
 		if !ok {
 			return nil, ErrInvalidSigningMethod
 		}
 
+		issuer, err := token.Claims.GetIssuer()
+		if err != nil {
+			return nil, fmt.Errorf("Claims.GetIssuer: %w", err)
+		}
+
+		kid, _ := token.Header["kid"].(string)
+
+		if kid == h.keyID {
+			if issuer != h.serviceIssuer {
+				return nil, ErrInvalidIssuer
+			}
+
+			return &h.privateKey.PublicKey, nil
+		}
+
+		if issuer == h.serviceIssuer || (h.issuer != "" && issuer != h.issuer) {
+			return nil, ErrInvalidIssuer
+		}
+
+		publicKey, err := h.keys.Key(ctx, kid)
+		if err != nil {
+			return nil, fmt.Errorf("keys.Key: %w", err)
+		}
+
 		return publicKey, nil
-	})
-	if err != nil {
+	}, options...)
+	if errors.Is(err, jwks.ErrKeysUnavailable) {
 		return models.SessionInfo{}, fmt.Errorf("jwt.ParseWithClaims: %w", err)
 	}
 
+	if err != nil {
+		return models.SessionInfo{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
 	claims, ok := token.Claims.(*Claims)
-	if ok && token.Valid {
+	if ok && token.Valid && claims.UUID != "" {
 		sessionInfo.UUID = claims.UUID
 		sessionInfo.Email = claims.Email
+		sessionInfo.Roles = claims.Roles
+		sessionInfo.Permissions = permissionsOf(claims.Roles, claims.Permissions)
+		sessionInfo.SessionID = claims.SessionID
+		sessionInfo.TokenID = claims.ID
+		sessionInfo.Issuer = claims.Issuer
+
+		if claims.ExpiresAt != nil {
+			sessionInfo.ExpiresAt = claims.ExpiresAt.Time
+		}
 
 		return sessionInfo, nil
 	}
@@ -73,8 +155,62 @@ func (h *Handler) verifyToken(accessToken string, publicKey *rsa.PublicKey) (mod
 	return models.SessionInfo{}, ErrInvalidToken
 }
 
+// apiKeyAuth authenticates requests that carry an API key. The key's scopes become the permissions of
+// the caller and its wallets, if any, the only wallets it may use. Requests without a key are left to
+// jwtAuth.
+func (h *Handler) apiKeyAuth(next http.Handler) http.Handler {
+	var fn http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(apiKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		apiKey, err := h.service.AuthenticateAPIKey(r.Context(), key)
+		if errors.Is(err, postgres.ErrAPIKeyNotValid) {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		sessionInfo := models.SessionInfo{
+			UUID:    apiKeySubject + apiKey.KeyID.String(),
+			Wallets: make([]string, 0, len(apiKey.WalletIDs)),
+		}
+
+		for _, scope := range apiKey.Scopes {
+			sessionInfo.Permissions = append(sessionInfo.Permissions, scopePermissions[scope])
+		}
+
+		for _, walletID := range apiKey.WalletIDs {
+			sessionInfo.Wallets = append(sessionInfo.Wallets, walletID.String())
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), models.SessionInfo{}, sessionInfo))
+		next.ServeHTTP(w, r)
+	}
+
+	return fn
+}
+
+// jwtAuth authenticates requests by their Bearer token, unless apiKeyAuth has already authenticated
+// them.
 func (h *Handler) jwtAuth(next http.Handler) http.Handler {
 	var fn http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+		_, authenticated := r.Context().Value(models.SessionInfo{}).(models.SessionInfo)
+		if authenticated {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
 			w.WriteHeader(http.StatusUnauthorized)
@@ -95,7 +231,7 @@ func (h *Handler) jwtAuth(next http.Handler) http.Handler {
 			return
 		}
 
-		sessionInfo, err := h.verifyToken(headerParts[1], h.publicKey)
+		sessionInfo, err := h.verifyToken(r.Context(), headerParts[1])
 		if errors.Is(err, ErrInvalidToken) {
 			w.WriteHeader(http.StatusUnauthorized)
 
@@ -108,6 +244,21 @@ func (h *Handler) jwtAuth(next http.Handler) http.Handler {
 			return
 		}
 
+		if sessionInfo.TokenID != "" {
+			revoked, err := h.service.IsTokenRevoked(r.Context(), sessionInfo.TokenID)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+
+				return
+			}
+
+			if revoked {
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+		}
+
 		r = r.WithContext(context.WithValue(r.Context(), models.SessionInfo{}, sessionInfo))
 		next.ServeHTTP(w, r)
 	}
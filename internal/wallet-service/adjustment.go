@@ -0,0 +1,82 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	operationAdjustment = "ADJUSTMENT"
+	adjustmentAccount   = "adjustment:"
+)
+
+var ErrAdjustmentNotValid = errors.New("adjustment direction, amount or reason is not valid")
+
+// AdjustBalance books a manual correction against the adjustment account of the currency. It skips
+// fees, spending limits and the frozen status, but a debit still cannot take the wallet over its
+// credit limit.
+func (s *Service) AdjustBalance(ctx context.Context, id string, adjustment models.RequestAdjustment) (
+	models.ResponseFundsOperation, error,
+) {
+	if adjustment.Direction != models.Credit && adjustment.Direction != models.Debit ||
+		adjustment.Amount.Sign() <= 0 || strings.TrimSpace(adjustment.Reason) == "" {
+		return models.ResponseFundsOperation{}, ErrAdjustmentNotValid
+	}
+
+	err := s.validateFunds(ctx, models.FundsOperations{Currency: adjustment.Currency, Amount: adjustment.Amount})
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("validateFunds: %w", err)
+	}
+
+	currentWallet, err := s.pg.GetWallet(ctx, id)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("pg.GetWallet: %w", err)
+	}
+
+	currency, err := debitedSubBalance(currentWallet, adjustment.Currency)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("debitedSubBalance: %w", err)
+	}
+
+	txn := newLedgerTransaction(operationAdjustment, uuid.NullUUID{UUID: adjustment.TransactionKey, Valid: true},
+		adjustment.Amount, currency)
+	txn.Reason = &adjustment.Reason
+
+	leg := transactionLeg(currentWallet.WalletID, adjustment.Amount, currency, nil)
+
+	if adjustment.Direction == models.Credit {
+		txn.Destination = leg
+		addMovement(&txn, systemAccount(adjustmentAccount, currency), walletAccount(currentWallet.WalletID),
+			adjustment.Amount, currency)
+	} else {
+		txn.Source = leg
+		addMovement(&txn, walletAccount(currentWallet.WalletID), systemAccount(adjustmentAccount, currency),
+			adjustment.Amount, currency)
+	}
+
+	key, err := idempotencyKey(adjustment.TransactionKey, "adjust", id, adjustment)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("idempotencyKey: %w", err)
+	}
+
+	started := time.Now()
+	defer func() {
+		s.metrics.duration.WithLabelValues("adjust_balance").Observe(time.Since(started).Seconds())
+	}()
+
+	response, err := s.pg.ManageBalance(ctx, key, id, txn)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("pg.ManageBalance: %w", err)
+	}
+
+	s.log.Infof("wallet %s adjusted: %s %s %s, %s", id, adjustment.Direction, adjustment.Amount, currency,
+		adjustment.Reason)
+
+	return response, nil
+}
@@ -0,0 +1,126 @@
+package walletservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyPrefix = "wk_"
+	apiKeyBytes  = 32
+)
+
+var (
+	ErrAPIKeyRequestNotValid = errors.New("API key name, scopes or expiry are not valid")
+	apiKeyScopes             = []string{models.ScopeRead, models.ScopeDeposit, models.ScopeTransfer}
+)
+
+// CreateAPIKey issues a key with the requested scopes. The key itself is only part of the response;
+// the database keeps its hash.
+func (s *Service) CreateAPIKey(ctx context.Context, createdBy string, request models.RequestAPIKey) (
+	models.ResponseAPIKey, error,
+) {
+	if strings.TrimSpace(request.Name) == "" || len(request.Scopes) == 0 ||
+		(request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now())) {
+		return models.ResponseAPIKey{}, ErrAPIKeyRequestNotValid
+	}
+
+	for _, scope := range request.Scopes {
+		if !slices.Contains(apiKeyScopes, scope) {
+			return models.ResponseAPIKey{}, ErrAPIKeyRequestNotValid
+		}
+	}
+
+	for _, walletID := range request.WalletIDs {
+		_, err := s.pg.GetWallet(ctx, walletID.String())
+		if err != nil {
+			return models.ResponseAPIKey{}, fmt.Errorf("pg.GetWallet: %w", err)
+		}
+	}
+
+	secret := make([]byte, apiKeyBytes)
+
+	_, err := rand.Read(secret)
+	if err != nil {
+		return models.ResponseAPIKey{}, fmt.Errorf("rand.Read: %w", err)
+	}
+
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	scopes := slices.Clone(request.Scopes)
+	slices.Sort(scopes)
+
+	walletIDs := request.WalletIDs
+	if walletIDs == nil {
+		walletIDs = []uuid.UUID{}
+	}
+
+	apiKey, err := s.pg.CreateAPIKey(ctx, models.APIKey{
+		KeyID:     uuid.New(),
+		Name:      request.Name,
+		Scopes:    slices.Compact(scopes),
+		WalletIDs: walletIDs,
+		ExpiresAt: request.ExpiresAt,
+		CreatedBy: createdBy,
+	}, hashToken(key))
+	if err != nil {
+		return models.ResponseAPIKey{}, fmt.Errorf("pg.CreateAPIKey: %w", err)
+	}
+
+	s.log.Infof("API key %s %q created by %s", apiKey.KeyID, apiKey.Name, createdBy)
+
+	return models.ResponseAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+func (s *Service) GetAPIKey(ctx context.Context, id string) (models.APIKey, error) {
+	apiKey, err := s.pg.GetAPIKey(ctx, id)
+	if err != nil {
+		return models.APIKey{}, fmt.Errorf("pg.GetAPIKey: %w", err)
+	}
+
+	return apiKey, nil
+}
+
+func (s *Service) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	apiKeys, err := s.pg.GetAPIKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("pg.GetAPIKeys: %w", err)
+	}
+
+	return apiKeys, nil
+}
+
+func (s *Service) RevokeAPIKey(ctx context.Context, id string) error {
+	_, err := s.pg.GetAPIKey(ctx, id)
+	if err != nil {
+		return fmt.Errorf("pg.GetAPIKey: %w", err)
+	}
+
+	err = s.pg.RevokeAPIKey(ctx, id)
+	if err != nil {
+		return fmt.Errorf("pg.RevokeAPIKey: %w", err)
+	}
+
+	s.log.Infof("API key %s revoked", id)
+
+	return nil
+}
+
+// AuthenticateAPIKey returns the live key matching key and records its use.
+func (s *Service) AuthenticateAPIKey(ctx context.Context, key string) (models.APIKey, error) {
+	apiKey, err := s.pg.UseAPIKey(ctx, hashToken(key))
+	if err != nil {
+		return models.APIKey{}, fmt.Errorf("pg.UseAPIKey: %w", err)
+	}
+
+	return apiKey, nil
+}
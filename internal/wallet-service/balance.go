@@ -0,0 +1,140 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSubBalanceNotFound = errors.New("wallet has no sub-balance in the currency")
+	ErrExchangeNotValid   = errors.New("exchange currencies must differ")
+)
+
+// OpenSubBalance lets the wallet hold funds in another currency. Deposits and incoming transfers in
+// that currency then land in the sub-balance without conversion.
+func (s *Service) OpenSubBalance(ctx context.Context, id, currency string) (models.ResponseWalletInstance, error) {
+	_, err := s.validateCurrency(ctx, currency)
+	if err != nil {
+		return models.ResponseWalletInstance{}, fmt.Errorf("validateCurrency: %w", err)
+	}
+
+	wallet, err := s.pg.OpenSubBalance(ctx, id, currency)
+	if err != nil {
+		return models.ResponseWalletInstance{}, fmt.Errorf("pg.OpenSubBalance: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// ExchangeFunds sells the amount from one sub-balance of the wallet and credits the proceeds to
+// another, opening it when needed.
+func (s *Service) ExchangeFunds(ctx context.Context, id string, exchange models.RequestExchange) (
+	models.ResponseFundsOperation, error,
+) {
+	err := s.validateFunds(ctx, models.FundsOperations{Currency: exchange.FromCurrency, Amount: exchange.Amount})
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("validateFunds: %w", err)
+	}
+
+	_, err = s.validateCurrency(ctx, exchange.ToCurrency)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("validateCurrency: %w", err)
+	}
+
+	if exchange.FromCurrency == exchange.ToCurrency {
+		return models.ResponseFundsOperation{}, ErrExchangeNotValid
+	}
+
+	currentWallet, err := s.pg.GetWallet(ctx, id)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("pg.GetWallet: %w", err)
+	}
+
+	err = checkCanSend(currentWallet)
+	if err != nil {
+		return models.ResponseFundsOperation{}, err
+	}
+
+	_, err = debitedSubBalance(currentWallet, exchange.FromCurrency)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("debitedSubBalance: %w", err)
+	}
+
+	if creditedSubBalance(currentWallet, exchange.ToCurrency) != exchange.ToCurrency {
+		currentWallet, err = s.pg.OpenSubBalance(ctx, id, exchange.ToCurrency)
+		if err != nil {
+			return models.ResponseFundsOperation{}, fmt.Errorf("pg.OpenSubBalance: %w", err)
+		}
+	}
+
+	creditAmount, rate, err := s.convert(ctx, models.SideBid, exchange.FromCurrency, exchange.ToCurrency,
+		exchange.Amount)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("convert: %w", err)
+	}
+
+	txn := newLedgerTransaction(operationExchange, uuid.NullUUID{UUID: exchange.TransactionKey, Valid: true},
+		exchange.Amount, exchange.FromCurrency)
+	txn.Source = transactionLeg(currentWallet.WalletID, exchange.Amount, exchange.FromCurrency, nil)
+	txn.Destination = transactionLeg(currentWallet.WalletID, creditAmount, exchange.ToCurrency, rate)
+	addExchange(&txn, walletAccount(currentWallet.WalletID), exchange.Amount, exchange.FromCurrency,
+		walletAccount(currentWallet.WalletID), creditAmount, exchange.ToCurrency)
+
+	err = s.chargeFee(ctx, &txn, currentWallet.WalletID, exchange.FromCurrency, exchange.Amount,
+		exchange.FromCurrency, exchange.ToCurrency)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("chargeFee: %w", err)
+	}
+
+	key, err := idempotencyKey(exchange.TransactionKey, "exchange", id, exchange)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("idempotencyKey: %w", err)
+	}
+
+	started := time.Now()
+	defer func() {
+		s.metrics.duration.WithLabelValues("exchange").Observe(time.Since(started).Seconds())
+	}()
+
+	response, err := s.pg.ManageBalance(ctx, key, id, txn)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("pg.ManageBalance: %w", err)
+	}
+
+	s.notifyNegativeBalance(ctx, currentWallet, response.ResponseWalletInstance)
+
+	return response, nil
+}
+
+// debitedSubBalance returns the currency of the sub-balance to debit, the primary balance unless
+// another open sub-balance is requested.
+func debitedSubBalance(wallet models.ResponseWalletInstance, currency string) (string, error) {
+	if currency == "" || currency == wallet.Currency {
+		return wallet.Currency, nil
+	}
+
+	for _, subBalance := range wallet.SubBalances {
+		if subBalance.Currency == currency {
+			return currency, nil
+		}
+	}
+
+	return "", ErrSubBalanceNotFound
+}
+
+// creditedSubBalance returns the currency that incoming funds are credited in: their own when the
+// wallet has a sub-balance in it, the primary currency otherwise.
+func creditedSubBalance(wallet models.ResponseWalletInstance, currency string) string {
+	for _, subBalance := range wallet.SubBalances {
+		if subBalance.Currency == currency {
+			return currency
+		}
+	}
+
+	return wallet.Currency
+}
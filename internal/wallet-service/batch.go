@@ -0,0 +1,280 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/AlexZav1327/service/internal/postgres"
+	"github.com/google/uuid"
+)
+
+const maxBatchItems = 1000
+
+var (
+	ErrBatchNotValid     = errors.New("batch is empty or too large")
+	ErrBatchItemNotValid = errors.New("batch item is not valid")
+	ErrBatchExceedsFunds = errors.New("batch total exceeds the available funds")
+	batchItemErrors      = []error{
+		ErrBatchItemNotValid, ErrCurrencyNotValid, postgres.ErrSpendingLimitExceeded, ErrFeeExceedsAmount,
+		money.ErrPrecision, money.ErrOverflow, postgres.ErrWalletNotFound, postgres.ErrOverdraft,
+		postgres.ErrConcurrentUpdate,
+	}
+)
+
+// BatchTransfer pays every item of the batch from one wallet. The items are priced and checked against
+// the spending limits as a whole, and an all-or-nothing batch is turned down when its total, fees included,
+// is more than the wallet can spend. An item that fails fails the batch unless it is best-effort, in which
+// case the items the funds do not cover are left out as they are booked.
+func (s *Service) BatchTransfer(ctx context.Context, idSrc string, batch models.RequestBatchTransfer) (
+	models.ResponseBatchTransfer, error,
+) {
+	if len(batch.Items) == 0 || len(batch.Items) > maxBatchItems {
+		return models.ResponseBatchTransfer{}, ErrBatchNotValid
+	}
+
+	currentSrcWallet, err := s.pg.GetWallet(ctx, idSrc)
+	if err != nil {
+		return models.ResponseBatchTransfer{}, fmt.Errorf("pg.GetWallet: %w", err)
+	}
+
+	err = checkCanSend(currentSrcWallet)
+	if err != nil {
+		return models.ResponseBatchTransfer{}, err
+	}
+
+	debitCurrency, err := debitedSubBalance(currentSrcWallet, batch.FromCurrency)
+	if err != nil {
+		return models.ResponseBatchTransfer{}, fmt.Errorf("debitedSubBalance: %w", err)
+	}
+
+	key, err := idempotencyKey(batch.TransactionKey, "batch_transfer", idSrc, batch)
+	if err != nil {
+		return models.ResponseBatchTransfer{}, fmt.Errorf("idempotencyKey: %w", err)
+	}
+
+	entries, total, err := s.prepareBatch(ctx, currentSrcWallet, debitCurrency, batch)
+	if err != nil {
+		return models.ResponseBatchTransfer{}, fmt.Errorf("prepareBatch: %w", err)
+	}
+
+	if !batch.BestEffort {
+		for _, entry := range entries {
+			if entry.Txn == nil {
+				return rejectedBatch(batch.TransactionKey, debitCurrency, currentSrcWallet, entries), nil
+			}
+		}
+
+		if total.Cmp(spendableFunds(currentSrcWallet, debitCurrency)) > 0 {
+			return models.ResponseBatchTransfer{}, ErrBatchExceedsFunds
+		}
+	}
+
+	started := time.Now()
+	defer func() {
+		s.metrics.duration.WithLabelValues("batch_transfer").Observe(time.Since(started).Seconds())
+	}()
+
+	response, err := s.pg.BatchTransfer(ctx, key, idSrc, debitCurrency, entries, batch.BestEffort, batchItemError)
+	if err != nil {
+		return models.ResponseBatchTransfer{}, fmt.Errorf("pg.BatchTransfer: %w", err)
+	}
+
+	s.notifyNegativeBalance(ctx, currentSrcWallet, response.SrcWallet)
+
+	return response, nil
+}
+
+// prepareBatch builds the transfer of every item and returns the total to be debited for the ones that
+// can be booked. Items that cannot are marked FAILED and get no transaction.
+func (s *Service) prepareBatch(ctx context.Context, srcWallet models.ResponseWalletInstance, debitCurrency string,
+	batch models.RequestBatchTransfer,
+) ([]models.BatchTransferEntry, money.Amount, error) {
+	remaining, err := s.remainingLimits(ctx, srcWallet, debitCurrency,
+		uuid.NullUUID{UUID: batch.TransactionKey, Valid: true})
+	if err != nil {
+		return nil, money.Amount{}, fmt.Errorf("remainingLimits: %w", err)
+	}
+
+	spending, err := s.spendingCheck(ctx, srcWallet, debitCurrency)
+	if err != nil {
+		return nil, money.Amount{}, fmt.Errorf("spendingCheck: %w", err)
+	}
+
+	entries := make([]models.BatchTransferEntry, len(batch.Items))
+
+	var total, limited money.Amount
+
+	for i, item := range batch.Items {
+		err = ctx.Err()
+		if err != nil {
+			return nil, money.Amount{}, err
+		}
+
+		entries[i].Result = models.BatchTransferResult{
+			DstWalletID: item.DstWalletID,
+			Amount:      item.Amount,
+			Currency:    item.Currency,
+			Status:      models.BatchItemSkipped,
+		}
+
+		txn, err := s.batchItemTransaction(ctx, srcWallet, debitCurrency, batch.TransactionKey, item)
+
+		var itemLimited, itemTotal money.Amount
+
+		if err == nil {
+			itemLimited, itemTotal, err = addBatchItem(remaining, limited, total, txn)
+		}
+
+		if err != nil && batchItemError(err) == nil {
+			return nil, money.Amount{}, fmt.Errorf("batchItem: %w", err)
+		}
+
+		if err != nil {
+			entries[i].Result.Status = models.BatchItemFailed
+			entries[i].Result.Error = batchItemError(err).Error()
+
+			continue
+		}
+
+		limited, total = itemLimited, itemTotal
+		txn.Spending = spending
+		entries[i].Txn = &txn
+	}
+
+	return entries, total, nil
+}
+
+func (s *Service) batchItemTransaction(ctx context.Context, srcWallet models.ResponseWalletInstance,
+	debitCurrency string, transactionKey uuid.UUID, item models.BatchTransferItem,
+) (models.LedgerTransaction, error) {
+	if item.Amount.Sign() <= 0 || item.DstWalletID == srcWallet.WalletID {
+		return models.LedgerTransaction{}, ErrBatchItemNotValid
+	}
+
+	err := s.validateFunds(ctx, models.FundsOperations{Currency: item.Currency, Amount: item.Amount})
+	if err != nil {
+		return models.LedgerTransaction{}, fmt.Errorf("validateFunds: %w", err)
+	}
+
+	withdrawAmount, srcRate, err := s.convert(ctx, models.SideAsk, item.Currency, debitCurrency, item.Amount)
+	if err != nil {
+		return models.LedgerTransaction{}, fmt.Errorf("convert: %w", err)
+	}
+
+	dstWallet, err := s.pg.GetWallet(ctx, item.DstWalletID.String())
+	if err != nil {
+		return models.LedgerTransaction{}, fmt.Errorf("pg.GetWallet: %w", err)
+	}
+
+	creditCurrency := creditedSubBalance(dstWallet, item.Currency)
+
+	depositAmount, dstRate, err := s.convert(ctx, models.SideBid, item.Currency, creditCurrency, item.Amount)
+	if err != nil {
+		return models.LedgerTransaction{}, fmt.Errorf("convert: %w", err)
+	}
+
+	txn := newLedgerTransaction(operationTransfer, uuid.NullUUID{UUID: transactionKey, Valid: true},
+		item.Amount, item.Currency)
+	txn.Source = transactionLeg(srcWallet.WalletID, withdrawAmount, debitCurrency, srcRate)
+	txn.Destination = transactionLeg(dstWallet.WalletID, depositAmount, creditCurrency, dstRate)
+	addExchange(&txn, walletAccount(srcWallet.WalletID), withdrawAmount, debitCurrency,
+		walletAccount(dstWallet.WalletID), depositAmount, creditCurrency)
+
+	err = s.chargeFee(ctx, &txn, srcWallet.WalletID, debitCurrency, withdrawAmount, debitCurrency, creditCurrency)
+	if err != nil {
+		return models.LedgerTransaction{}, fmt.Errorf("chargeFee: %w", err)
+	}
+
+	return txn, nil
+}
+
+// rejectedBatch reports an all-or-nothing batch that failed before anything was booked.
+func rejectedBatch(transactionKey uuid.UUID, currency string, srcWallet models.ResponseWalletInstance,
+	entries []models.BatchTransferEntry,
+) models.ResponseBatchTransfer {
+	response := models.ResponseBatchTransfer{
+		TransactionKey: transactionKey,
+		Status:         models.BatchFailed,
+		Currency:       currency,
+		SrcWallet:      srcWallet,
+		Items:          make([]models.BatchTransferResult, len(entries)),
+	}
+
+	for i, entry := range entries {
+		response.Items[i] = entry.Result
+	}
+
+	return response
+}
+
+// addBatchItem adds the item to the amounts the batch counts against the spending limits and debits in
+// all, and fails when the limits would be exceeded.
+func addBatchItem(remaining []models.RemainingLimit, limited, total money.Amount, txn models.LedgerTransaction) (
+	money.Amount, money.Amount, error,
+) {
+	limited, err := limited.Add(txn.Source.Amount)
+	if err != nil {
+		return money.Amount{}, money.Amount{}, fmt.Errorf("Add: %w", err)
+	}
+
+	err = exceededLimit(remaining, limited)
+	if err != nil {
+		return money.Amount{}, money.Amount{}, err
+	}
+
+	total, err = total.Add(txn.Source.Amount)
+	if err != nil {
+		return money.Amount{}, money.Amount{}, fmt.Errorf("Add: %w", err)
+	}
+
+	if txn.Fee != nil {
+		total, err = total.Add(txn.Fee.Amount)
+		if err != nil {
+			return money.Amount{}, money.Amount{}, fmt.Errorf("Add: %w", err)
+		}
+	}
+
+	return limited, total, nil
+}
+
+// spendableFunds is what a debit in currency can take from the wallet: the available primary balance
+// and the unused credit line, or the balance of the sub-balance.
+func spendableFunds(wallet models.ResponseWalletInstance, currency string) money.Amount {
+	if currency != wallet.Currency {
+		for _, subBalance := range wallet.SubBalances {
+			if subBalance.Currency == currency {
+				return subBalance.Balance
+			}
+		}
+
+		return money.Amount{}
+	}
+
+	available := wallet.AvailableBalance
+	if available.Sign() < 0 {
+		available = money.Amount{}
+	}
+
+	spendable, err := available.Add(wallet.AvailableCredit)
+	if err != nil {
+		return available
+	}
+
+	return spendable
+}
+
+// batchItemError returns the error that fails a single item without failing the batch, or nil when err
+// is not one.
+func batchItemError(err error) error {
+	for _, itemErr := range batchItemErrors {
+		if errors.Is(err, itemErr) {
+			return itemErr
+		}
+	}
+
+	return nil
+}
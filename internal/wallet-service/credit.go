@@ -0,0 +1,58 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/AlexZav1327/service/internal/models"
+)
+
+var ErrCreditLimitNotValid = errors.New("credit limit is not valid")
+
+// SetCreditLimit lets the wallet balance go down to minus the limit. A limit of zero closes the
+// credit line.
+func (s *Service) SetCreditLimit(ctx context.Context, id string, credit models.RequestCreditLimit) (
+	models.ResponseWalletInstance, error,
+) {
+	wallet, err := s.pg.GetWallet(ctx, id)
+	if err != nil {
+		return models.ResponseWalletInstance{}, fmt.Errorf("pg.GetWallet: %w", err)
+	}
+
+	currency, err := s.pg.GetCurrency(ctx, wallet.Currency)
+	if err != nil {
+		return models.ResponseWalletInstance{}, fmt.Errorf("pg.GetCurrency: %w", err)
+	}
+
+	if credit.CreditLimit.Sign() < 0 || !credit.CreditLimit.HasMinorUnits(currency.MinorUnits) {
+		return models.ResponseWalletInstance{}, ErrCreditLimitNotValid
+	}
+
+	updatedWallet, err := s.pg.SetCreditLimit(ctx, id, credit.CreditLimit)
+	if err != nil {
+		return models.ResponseWalletInstance{}, fmt.Errorf("pg.SetCreditLimit: %w", err)
+	}
+
+	return updatedWallet, nil
+}
+
+// notifyNegativeBalance tells the owner that the wallet has started to draw on its credit line. The
+// operation has already been committed, so a failed notification is only logged.
+func (s *Service) notifyNegativeBalance(ctx context.Context, before, after models.ResponseWalletInstance) {
+	if before.Balance.Sign() < 0 || after.Balance.Sign() >= 0 {
+		return
+	}
+
+	message, err := s.message.CreateOverdraftMessage(after)
+	if err != nil {
+		s.log.Warningf("message.CreateOverdraftMessage: %s", err)
+
+		return
+	}
+
+	err = s.notification.Notify(ctx, message)
+	if err != nil {
+		s.log.Warningf("notification.Notify: %s", err)
+	}
+}
@@ -0,0 +1,119 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/AlexZav1327/service/internal/postgres"
+)
+
+var (
+	ErrCurrencySettingsNotValid = errors.New("currency settings are not valid")
+	currencyCodePattern         = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+func (s *Service) GetCurrencies(ctx context.Context) ([]models.Currency, error) {
+	currencies, err := s.pg.GetCurrencies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("pg.GetCurrencies: %w", err)
+	}
+
+	return currencies, nil
+}
+
+func (s *Service) GetCurrency(ctx context.Context, code string) (models.Currency, error) {
+	currency, err := s.pg.GetCurrency(ctx, code)
+	if err != nil {
+		return models.Currency{}, fmt.Errorf("pg.GetCurrency: %w", err)
+	}
+
+	return currency, nil
+}
+
+func (s *Service) CreateCurrency(ctx context.Context, currency models.RequestCurrency) (models.Currency, error) {
+	if !currencyCodePattern.MatchString(currency.Code) || currency.MinorUnits == nil || *currency.MinorUnits < 0 ||
+		*currency.MinorUnits > money.Scale || currency.ReferenceRate.IsZero() {
+		return models.Currency{}, ErrCurrencySettingsNotValid
+	}
+
+	enabled := true
+	if currency.Enabled != nil {
+		enabled = *currency.Enabled
+	}
+
+	createdCurrency, err := s.pg.CreateCurrency(ctx, models.Currency{
+		Code:          currency.Code,
+		MinorUnits:    *currency.MinorUnits,
+		Enabled:       enabled,
+		ReferenceRate: currency.ReferenceRate,
+	})
+	if err != nil {
+		return models.Currency{}, fmt.Errorf("pg.CreateCurrency: %w", err)
+	}
+
+	return createdCurrency, nil
+}
+
+// UpdateCurrency changes the enabled flag and the reference rate. Minor units cannot be changed
+// once a currency is registered, as stored balances are already rounded to them.
+func (s *Service) UpdateCurrency(ctx context.Context, code string, currency models.RequestCurrency) (
+	models.Currency, error,
+) {
+	currentCurrency, err := s.pg.GetCurrency(ctx, code)
+	if err != nil {
+		return models.Currency{}, fmt.Errorf("pg.GetCurrency: %w", err)
+	}
+
+	if currency.MinorUnits != nil && *currency.MinorUnits != currentCurrency.MinorUnits {
+		return models.Currency{}, ErrCurrencySettingsNotValid
+	}
+
+	if currency.Enabled != nil {
+		currentCurrency.Enabled = *currency.Enabled
+	}
+
+	if !currency.ReferenceRate.IsZero() {
+		currentCurrency.ReferenceRate = currency.ReferenceRate
+	}
+
+	updatedCurrency, err := s.pg.UpdateCurrency(ctx, currentCurrency)
+	if err != nil {
+		return models.Currency{}, fmt.Errorf("pg.UpdateCurrency: %w", err)
+	}
+
+	return updatedCurrency, nil
+}
+
+func (s *Service) validateCurrency(ctx context.Context, code string) (models.Currency, error) {
+	currency, err := s.pg.GetCurrency(ctx, code)
+	if errors.Is(err, postgres.ErrCurrencyNotFound) {
+		return models.Currency{}, ErrCurrencyNotValid
+	}
+
+	if err != nil {
+		return models.Currency{}, fmt.Errorf("pg.GetCurrency: %w", err)
+	}
+
+	if !currency.Enabled {
+		return models.Currency{}, ErrCurrencyNotValid
+	}
+
+	return currency, nil
+}
+
+func (s *Service) validateFunds(ctx context.Context, funds models.FundsOperations) error {
+	currency, err := s.validateCurrency(ctx, funds.Currency)
+	if err != nil {
+		return err
+	}
+
+	if !funds.Amount.HasMinorUnits(currency.MinorUnits) {
+		return money.ErrPrecision
+	}
+
+	return nil
+}
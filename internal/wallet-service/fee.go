@@ -0,0 +1,166 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+var ErrFeeExceedsAmount = errors.New("fee exceeds the operation amount")
+
+// FeeRule prices one kind of operation. Empty Operation, FromCurrency or ToCurrency match anything, and
+// the most specific matching rule wins. Amounts are in the currency of the paying wallet and Percent is
+// in percent. The first tier whose UpTo covers the amount, or has no UpTo, replaces Flat and Percent.
+// Zero Min or Max means no cap.
+type FeeRule struct {
+	Operation    string       `mapstructure:"operation"`
+	FromCurrency string       `mapstructure:"fromCurrency"`
+	ToCurrency   string       `mapstructure:"toCurrency"`
+	Flat         money.Amount `mapstructure:"flat"`
+	Percent      money.Rate   `mapstructure:"percent"`
+	Min          money.Amount `mapstructure:"min"`
+	Max          money.Amount `mapstructure:"max"`
+	Tiers        []FeeTier    `mapstructure:"tiers"`
+}
+
+type FeeTier struct {
+	UpTo    money.Amount `mapstructure:"upTo"`
+	Flat    money.Amount `mapstructure:"flat"`
+	Percent money.Rate   `mapstructure:"percent"`
+}
+
+func (r FeeRule) matches(operation, fromCurrency, toCurrency string) bool {
+	return (r.Operation == "" || r.Operation == operation) &&
+		(r.FromCurrency == "" || r.FromCurrency == fromCurrency) &&
+		(r.ToCurrency == "" || r.ToCurrency == toCurrency)
+}
+
+func (r FeeRule) specificity() int {
+	var n int
+
+	for _, field := range []string{r.Operation, r.FromCurrency, r.ToCurrency} {
+		if field != "" {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (r FeeRule) charge(amount money.Amount, minorUnits int) (money.Amount, error) {
+	flat, percent := r.Flat, r.Percent
+
+	for _, tier := range r.Tiers {
+		if tier.UpTo.IsZero() || amount.Cmp(tier.UpTo) <= 0 {
+			flat, percent = tier.Flat, tier.Percent
+
+			break
+		}
+	}
+
+	share, err := amount.Percent(percent, minorUnits)
+	if err != nil {
+		return money.Amount{}, fmt.Errorf("Percent: %w", err)
+	}
+
+	fee, err := share.Add(flat)
+	if err != nil {
+		return money.Amount{}, fmt.Errorf("Add: %w", err)
+	}
+
+	if r.Min.Sign() > 0 && fee.Cmp(r.Min) < 0 {
+		fee = r.Min
+	}
+
+	if r.Max.Sign() > 0 && fee.Cmp(r.Max) > 0 {
+		fee = r.Max
+	}
+
+	rounded, err := fee.Round(minorUnits)
+	if err != nil {
+		return money.Amount{}, fmt.Errorf("Round: %w", err)
+	}
+
+	return rounded, nil
+}
+
+func (s *Service) feeRule(operation, fromCurrency, toCurrency string) (FeeRule, bool) {
+	var (
+		found FeeRule
+		ok    bool
+	)
+
+	for _, rule := range s.config.FeeRules {
+		if !rule.matches(operation, fromCurrency, toCurrency) {
+			continue
+		}
+
+		if !ok || rule.specificity() > found.specificity() {
+			found, ok = rule, true
+		}
+	}
+
+	return found, ok
+}
+
+// priceFee returns the fee the payer owes for the operation on the amount it is debited or credited, or
+// zero when the operation is free.
+func (s *Service) priceFee(ctx context.Context, operation string, payerID uuid.UUID, payerCurrency string,
+	amount money.Amount, fromCurrency, toCurrency string,
+) (money.Amount, error) {
+	if s.config.FeeWalletID == uuid.Nil || s.config.FeeWalletID == payerID {
+		return money.Amount{}, nil
+	}
+
+	rule, ok := s.feeRule(operation, fromCurrency, toCurrency)
+	if !ok {
+		return money.Amount{}, nil
+	}
+
+	currency, err := s.pg.GetCurrency(ctx, payerCurrency)
+	if err != nil {
+		return money.Amount{}, fmt.Errorf("pg.GetCurrency: %w", err)
+	}
+
+	fee, err := rule.charge(amount, currency.MinorUnits)
+	if err != nil {
+		return money.Amount{}, fmt.Errorf("charge: %w", err)
+	}
+
+	return fee, nil
+}
+
+// chargeFee prices the operation and books the fee from the payer to the fee wallet, converting it when
+// the fee wallet holds another currency.
+func (s *Service) chargeFee(ctx context.Context, txn *models.LedgerTransaction, payerID uuid.UUID,
+	payerCurrency string, amount money.Amount, fromCurrency, toCurrency string,
+) error {
+	fee, err := s.priceFee(ctx, txn.OperationType, payerID, payerCurrency, amount, fromCurrency, toCurrency)
+	if err != nil {
+		return fmt.Errorf("priceFee: %w", err)
+	}
+
+	if fee.Sign() <= 0 {
+		return nil
+	}
+
+	feeWallet, err := s.pg.GetWallet(ctx, s.config.FeeWalletID.String())
+	if err != nil {
+		return fmt.Errorf("pg.GetWallet: %w", err)
+	}
+
+	credited, _, err := s.convert(ctx, models.SideBid, payerCurrency, feeWallet.Currency, fee)
+	if err != nil {
+		return fmt.Errorf("convert: %w", err)
+	}
+
+	txn.Fee = &models.Fee{Amount: fee, Currency: payerCurrency, FeeWalletID: feeWallet.WalletID}
+	addExchange(txn, walletAccount(payerID), fee, payerCurrency,
+		walletAccount(feeWallet.WalletID), credited, feeWallet.Currency)
+
+	return nil
+}
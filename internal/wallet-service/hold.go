@@ -0,0 +1,247 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultHoldTTL      = 7 * 24 * time.Hour
+	holdsExpiryInterval = time.Minute
+)
+
+var (
+	ErrHoldTTLNotValid    = errors.New("hold TTL is not valid")
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds the hold")
+)
+
+func (s *Service) AuthorizeHold(ctx context.Context, id string, authorize models.RequestAuthorize) (
+	models.ResponseHold, error,
+) {
+	err := s.validateFunds(ctx, models.FundsOperations{Currency: authorize.Currency, Amount: authorize.Amount})
+	if err != nil {
+		return models.ResponseHold{}, fmt.Errorf("validateFunds: %w", err)
+	}
+
+	ttl := s.config.HoldTTL
+	if ttl <= 0 {
+		ttl = defaultHoldTTL
+	}
+
+	if authorize.TTLSeconds < 0 {
+		return models.ResponseHold{}, ErrHoldTTLNotValid
+	}
+
+	if authorize.TTLSeconds > 0 {
+		ttl = time.Duration(authorize.TTLSeconds) * time.Second
+	}
+
+	currentWallet, err := s.pg.GetWallet(ctx, id)
+	if err != nil {
+		return models.ResponseHold{}, fmt.Errorf("pg.GetWallet: %w", err)
+	}
+
+	err = checkCanSend(currentWallet)
+	if err != nil {
+		return models.ResponseHold{}, err
+	}
+
+	debit, rate, err := s.convert(ctx, models.SideAsk, authorize.Currency, currentWallet.Currency,
+		authorize.Amount)
+	if err != nil {
+		return models.ResponseHold{}, fmt.Errorf("convert: %w", err)
+	}
+
+	fee, err := s.priceFee(ctx, operationCapture, currentWallet.WalletID, currentWallet.Currency, debit,
+		currentWallet.Currency, authorize.Currency)
+	if err != nil {
+		return models.ResponseHold{}, fmt.Errorf("priceFee: %w", err)
+	}
+
+	heldAmount, err := debit.Add(fee)
+	if err != nil {
+		return models.ResponseHold{}, fmt.Errorf("Add: %w", err)
+	}
+
+	key, err := idempotencyKey(authorize.TransactionKey, "authorize", id, authorize)
+	if err != nil {
+		return models.ResponseHold{}, fmt.Errorf("idempotencyKey: %w", err)
+	}
+
+	hold := models.Hold{
+		HoldID:         uuid.New(),
+		WalletID:       currentWallet.WalletID,
+		Amount:         authorize.Amount,
+		Currency:       authorize.Currency,
+		HeldAmount:     heldAmount,
+		HeldCurrency:   currentWallet.Currency,
+		AppliedRate:    rate,
+		TransactionKey: uuid.NullUUID{UUID: authorize.TransactionKey, Valid: true},
+		ExpiresAt:      time.Now().Add(ttl),
+	}
+
+	started := time.Now()
+	defer func() {
+		s.metrics.duration.WithLabelValues("authorize_hold").Observe(time.Since(started).Seconds())
+	}()
+
+	response, err := s.pg.AuthorizeHold(ctx, key, hold)
+	if err != nil {
+		return models.ResponseHold{}, fmt.Errorf("pg.AuthorizeHold: %w", err)
+	}
+
+	return response, nil
+}
+
+func (s *Service) GetHold(ctx context.Context, id string) (models.Hold, error) {
+	hold, err := s.pg.GetHold(ctx, id)
+	if err != nil {
+		return models.Hold{}, fmt.Errorf("pg.GetHold: %w", err)
+	}
+
+	return hold, nil
+}
+
+// CaptureHold debits the captured amount, or the whole hold when no amount is given, with its fee and
+// releases the rest. The capture is converted at the rate locked in by the authorization.
+func (s *Service) CaptureHold(ctx context.Context, id string, capture models.RequestCapture) (
+	models.ResponseHold, error,
+) {
+	hold, err := s.pg.GetHold(ctx, id)
+	if err != nil {
+		return models.ResponseHold{}, fmt.Errorf("pg.GetHold: %w", err)
+	}
+
+	amount := hold.Amount
+	if !capture.Amount.IsZero() {
+		amount = capture.Amount
+	}
+
+	debit, err := s.captureDebit(ctx, hold, amount)
+	if err != nil {
+		return models.ResponseHold{}, fmt.Errorf("captureDebit: %w", err)
+	}
+
+	currentWallet, err := s.pg.GetWallet(ctx, hold.WalletID.String())
+	if err != nil {
+		return models.ResponseHold{}, fmt.Errorf("pg.GetWallet: %w", err)
+	}
+
+	err = checkCanSend(currentWallet)
+	if err != nil {
+		return models.ResponseHold{}, err
+	}
+
+	txn := newLedgerTransaction(operationCapture, uuid.NullUUID{UUID: capture.TransactionKey, Valid: true},
+		amount, hold.Currency)
+
+	txn.Spending, err = s.spendingCheck(ctx, currentWallet, hold.HeldCurrency)
+	if err != nil {
+		return models.ResponseHold{}, fmt.Errorf("spendingCheck: %w", err)
+	}
+
+	txn.Source = transactionLeg(hold.WalletID, debit, hold.HeldCurrency, hold.AppliedRate)
+	addExchange(&txn, walletAccount(hold.WalletID), debit, hold.HeldCurrency,
+		systemAccount(externalAccount, hold.Currency), amount, hold.Currency)
+
+	err = s.chargeFee(ctx, &txn, currentWallet.WalletID, hold.HeldCurrency, debit, hold.HeldCurrency, hold.Currency)
+	if err != nil {
+		return models.ResponseHold{}, fmt.Errorf("chargeFee: %w", err)
+	}
+
+	key, err := idempotencyKey(capture.TransactionKey, "capture", id, capture)
+	if err != nil {
+		return models.ResponseHold{}, fmt.Errorf("idempotencyKey: %w", err)
+	}
+
+	started := time.Now()
+	defer func() {
+		s.metrics.duration.WithLabelValues("capture_hold").Observe(time.Since(started).Seconds())
+	}()
+
+	response, err := s.pg.CaptureHold(ctx, key, hold, txn)
+	if err != nil {
+		return models.ResponseHold{}, fmt.Errorf("pg.CaptureHold: %w", err)
+	}
+
+	s.notifyNegativeBalance(ctx, currentWallet, response.Wallet)
+
+	return response, nil
+}
+
+func (s *Service) VoidHold(ctx context.Context, id string) (models.ResponseHold, error) {
+	hold, err := s.pg.GetHold(ctx, id)
+	if err != nil {
+		return models.ResponseHold{}, fmt.Errorf("pg.GetHold: %w", err)
+	}
+
+	response, err := s.pg.VoidHold(ctx, hold)
+	if err != nil {
+		return models.ResponseHold{}, fmt.Errorf("pg.VoidHold: %w", err)
+	}
+
+	return response, nil
+}
+
+func (s *Service) HoldsExpiryRun(ctx context.Context) error {
+	expiryTicker := time.NewTicker(holdsExpiryInterval)
+	defer expiryTicker.Stop()
+
+	for {
+		select {
+		case <-expiryTicker.C:
+			expired, err := s.pg.ExpireHolds(ctx)
+			if err != nil {
+				s.log.Warningf("pg.ExpireHolds: %s", err)
+
+				continue
+			}
+
+			if expired > 0 {
+				s.log.Infof("holds released on %d wallets", expired)
+			}
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// captureDebit converts the captured amount to the wallet's currency. The held amount also covers the
+// capture fee, so the debit of a full capture is recomputed rather than taken from the hold.
+func (s *Service) captureDebit(ctx context.Context, hold models.Hold, amount money.Amount) (money.Amount, error) {
+	if amount.Sign() < 0 || amount.Cmp(hold.Amount) > 0 {
+		return money.Amount{}, ErrCaptureExceedsHold
+	}
+
+	currency, err := s.pg.GetCurrency(ctx, hold.Currency)
+	if err != nil {
+		return money.Amount{}, fmt.Errorf("pg.GetCurrency: %w", err)
+	}
+
+	if !amount.HasMinorUnits(currency.MinorUnits) {
+		return money.Amount{}, money.ErrPrecision
+	}
+
+	if hold.AppliedRate == nil {
+		return amount, nil
+	}
+
+	heldCurrency, err := s.pg.GetCurrency(ctx, hold.HeldCurrency)
+	if err != nil {
+		return money.Amount{}, fmt.Errorf("pg.GetCurrency: %w", err)
+	}
+
+	debit, err := amount.Convert(hold.AppliedRate.Rate, heldCurrency.MinorUnits)
+	if err != nil {
+		return money.Amount{}, fmt.Errorf("Convert: %w", err)
+	}
+
+	return debit, nil
+}
@@ -0,0 +1,69 @@
+package walletservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/google/uuid"
+)
+
+const cleanupInterval = time.Hour
+
+func idempotencyKey(key uuid.UUID, operation string, request ...any) (models.IdempotencyKey, error) {
+	data, err := json.Marshal(append([]any{operation}, request...))
+	if err != nil {
+		return models.IdempotencyKey{}, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+
+	return models.IdempotencyKey{
+		Key:         key,
+		Fingerprint: hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+func (s *Service) IdempotencyCleanupRun(ctx context.Context, retention time.Duration) error {
+	cleanupTicker := time.NewTicker(cleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-cleanupTicker.C:
+			deleted, err := s.pg.DeleteExpiredIdempotencyKeys(ctx, retention)
+			if err != nil {
+				return fmt.Errorf("pg.DeleteExpiredIdempotencyKeys: %w", err)
+			}
+
+			if deleted > 0 {
+				s.log.Infof("%d expired idempotency keys deleted", deleted)
+			}
+
+			deleted, err = s.pg.DeleteExpiredQuotes(ctx, retention)
+			if err != nil {
+				return fmt.Errorf("pg.DeleteExpiredQuotes: %w", err)
+			}
+
+			if deleted > 0 {
+				s.log.Infof("%d expired quotes deleted", deleted)
+			}
+
+			deleted, err = s.pg.DeleteExpiredSessions(ctx, retention)
+			if err != nil {
+				return fmt.Errorf("pg.DeleteExpiredSessions: %w", err)
+			}
+
+			if deleted > 0 {
+				s.log.Infof("%d expired sessions deleted", deleted)
+			}
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
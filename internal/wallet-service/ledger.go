@@ -0,0 +1,94 @@
+package walletservice
+
+import (
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+const (
+	operationDeposit    = "DEPOSIT"
+	operationWithdrawal = "WITHDRAWAL"
+	operationTransfer   = "TRANSFER"
+	operationConversion = "CONVERSION"
+	operationCapture    = "CAPTURE"
+	operationExchange   = "EXCHANGE"
+	externalAccount     = "external:"
+	fxAccount           = "fx:"
+)
+
+type ledgerAccount struct {
+	name     string
+	walletID uuid.NullUUID
+}
+
+func walletAccount(id uuid.UUID) ledgerAccount {
+	return ledgerAccount{name: id.String(), walletID: uuid.NullUUID{UUID: id, Valid: true}}
+}
+
+func systemAccount(prefix, currency string) ledgerAccount {
+	return ledgerAccount{name: prefix + currency}
+}
+
+func newLedgerTransaction(operationType string, transactionKey uuid.NullUUID, amount money.Amount,
+	currency string,
+) models.LedgerTransaction {
+	return models.LedgerTransaction{
+		Transaction: models.Transaction{
+			TransactionID:  uuid.New(),
+			OperationType:  operationType,
+			Amount:         amount,
+			Currency:       currency,
+			TransactionKey: transactionKey,
+			Status:         models.TransactionCompleted,
+		},
+	}
+}
+
+func transactionLeg(walletID uuid.UUID, amount money.Amount, currency string, rate *models.AppliedRate,
+) *models.TransactionLeg {
+	return &models.TransactionLeg{
+		WalletID:    walletID,
+		Amount:      amount,
+		Currency:    currency,
+		AppliedRate: rate,
+	}
+}
+
+// addExchange moves value from one account to another. When currencies or amounts differ, both legs
+// are booked against the FX clearing accounts so that every currency stays balanced.
+func addExchange(txn *models.LedgerTransaction, from ledgerAccount, fromAmount money.Amount, fromCurrency string,
+	to ledgerAccount, toAmount money.Amount, toCurrency string,
+) {
+	if fromCurrency == toCurrency && fromAmount == toAmount {
+		addMovement(txn, from, to, fromAmount, fromCurrency)
+
+		return
+	}
+
+	addMovement(txn, from, systemAccount(fxAccount, fromCurrency), fromAmount, fromCurrency)
+	addMovement(txn, systemAccount(fxAccount, toCurrency), to, toAmount, toCurrency)
+}
+
+func addMovement(txn *models.LedgerTransaction, from, to ledgerAccount, amount money.Amount, currency string) {
+	if amount.Sign() <= 0 {
+		return
+	}
+
+	txn.Entries = append(txn.Entries,
+		models.LedgerEntry{
+			Account:   from.name,
+			WalletID:  from.walletID,
+			Direction: models.Debit,
+			Amount:    amount,
+			Currency:  currency,
+		},
+		models.LedgerEntry{
+			Account:   to.name,
+			WalletID:  to.walletID,
+			Direction: models.Credit,
+			Amount:    amount,
+			Currency:  currency,
+		},
+	)
+}
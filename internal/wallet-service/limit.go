@@ -0,0 +1,241 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/AlexZav1327/service/internal/postgres"
+	"github.com/google/uuid"
+)
+
+const day = 24 * time.Hour
+
+var (
+	ErrSpendingLimitsNotValid = errors.New("spending limits are not valid")
+	limitedOperations         = []string{operationWithdrawal, operationTransfer, operationCapture}
+	spendingPeriods           = []string{models.PeriodDaily, models.PeriodWeekly, models.PeriodMonthly}
+	spendingWindows           = []time.Duration{day, 7 * day, 30 * day}
+)
+
+func (s *Service) GetCurrencyLimits(ctx context.Context, code string) (models.SpendingLimits, error) {
+	_, err := s.pg.GetCurrency(ctx, code)
+	if err != nil {
+		return models.SpendingLimits{}, fmt.Errorf("pg.GetCurrency: %w", err)
+	}
+
+	limits, err := s.pg.GetCurrencyLimits(ctx, code)
+	if err != nil {
+		return models.SpendingLimits{}, fmt.Errorf("pg.GetCurrencyLimits: %w", err)
+	}
+
+	return limits, nil
+}
+
+func (s *Service) SetCurrencyLimits(ctx context.Context, code string, limits models.SpendingLimits) (
+	models.SpendingLimits, error,
+) {
+	currency, err := s.pg.GetCurrency(ctx, code)
+	if err != nil {
+		return models.SpendingLimits{}, fmt.Errorf("pg.GetCurrency: %w", err)
+	}
+
+	if !validSpendingLimits(limits, currency.MinorUnits) {
+		return models.SpendingLimits{}, ErrSpendingLimitsNotValid
+	}
+
+	updatedLimits, err := s.pg.SetCurrencyLimits(ctx, code, limits)
+	if err != nil {
+		return models.SpendingLimits{}, fmt.Errorf("pg.SetCurrencyLimits: %w", err)
+	}
+
+	return updatedLimits, nil
+}
+
+func (s *Service) GetWalletLimits(ctx context.Context, id string) (models.SpendingLimits, error) {
+	_, err := s.pg.GetWallet(ctx, id)
+	if err != nil {
+		return models.SpendingLimits{}, fmt.Errorf("pg.GetWallet: %w", err)
+	}
+
+	limits, err := s.pg.GetWalletLimits(ctx, id)
+	if err != nil {
+		return models.SpendingLimits{}, fmt.Errorf("pg.GetWalletLimits: %w", err)
+	}
+
+	return limits, nil
+}
+
+// SetWalletLimits overrides the currency defaults for one wallet. Limits left empty fall back to the
+// defaults of the wallet's currency.
+func (s *Service) SetWalletLimits(ctx context.Context, id string, limits models.SpendingLimits) (
+	models.SpendingLimits, error,
+) {
+	wallet, err := s.pg.GetWallet(ctx, id)
+	if err != nil {
+		return models.SpendingLimits{}, fmt.Errorf("pg.GetWallet: %w", err)
+	}
+
+	currency, err := s.pg.GetCurrency(ctx, wallet.Currency)
+	if err != nil {
+		return models.SpendingLimits{}, fmt.Errorf("pg.GetCurrency: %w", err)
+	}
+
+	if !validSpendingLimits(limits, currency.MinorUnits) {
+		return models.SpendingLimits{}, ErrSpendingLimitsNotValid
+	}
+
+	updatedLimits, err := s.pg.SetWalletLimits(ctx, id, limits)
+	if err != nil {
+		return models.SpendingLimits{}, fmt.Errorf("pg.SetWalletLimits: %w", err)
+	}
+
+	return updatedLimits, nil
+}
+
+func (s *Service) GetRemainingLimits(ctx context.Context, id string) (models.ResponseRemainingLimits, error) {
+	wallet, err := s.pg.GetWallet(ctx, id)
+	if err != nil {
+		return models.ResponseRemainingLimits{}, fmt.Errorf("pg.GetWallet: %w", err)
+	}
+
+	remaining, err := s.remainingLimits(ctx, wallet, wallet.Currency, uuid.NullUUID{})
+	if err != nil {
+		return models.ResponseRemainingLimits{}, fmt.Errorf("remainingLimits: %w", err)
+	}
+
+	return models.ResponseRemainingLimits{
+		WalletID: wallet.WalletID,
+		Currency: wallet.Currency,
+		Limits:   remaining,
+	}, nil
+}
+
+// spendingCheck returns the limits a debit from the sub-balance in currency must stay within. They are
+// checked as the debit is booked, once the wallet is locked, so concurrent debits cannot pass together.
+func (s *Service) spendingCheck(ctx context.Context, wallet models.ResponseWalletInstance, currency string) (
+	*models.SpendingCheck, error,
+) {
+	limits, err := s.effectiveLimits(ctx, wallet, currency)
+	if err != nil {
+		return nil, fmt.Errorf("effectiveLimits: %w", err)
+	}
+
+	now := time.Now()
+	check := &models.SpendingCheck{Operations: limitedOperations}
+
+	for i, limit := range []*money.Amount{limits.Daily, limits.Weekly, limits.Monthly} {
+		if limit != nil {
+			check.Caps = append(check.Caps, models.SpendingCap{
+				Period: spendingPeriods[i],
+				Limit:  *limit,
+				Since:  now.Add(-spendingWindows[i]),
+			})
+		}
+	}
+
+	return check, nil
+}
+
+func exceededLimit(remaining []models.RemainingLimit, amount money.Amount) error {
+	for _, limit := range remaining {
+		if limit.Remaining != nil && amount.Cmp(*limit.Remaining) > 0 {
+			return fmt.Errorf("%w: %s", postgres.ErrSpendingLimitExceeded, limit.Period)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) remainingLimits(ctx context.Context, wallet models.ResponseWalletInstance, currency string,
+	excludeKey uuid.NullUUID,
+) ([]models.RemainingLimit, error) {
+	limits, err := s.effectiveLimits(ctx, wallet, currency)
+	if err != nil {
+		return nil, fmt.Errorf("effectiveLimits: %w", err)
+	}
+
+	now := time.Now()
+	since := make([]time.Time, len(spendingWindows))
+
+	for i, window := range spendingWindows {
+		since[i] = now.Add(-window)
+	}
+
+	spent, err := s.pg.GetSpentAmounts(ctx, wallet.WalletID.String(), currency, limitedOperations,
+		excludeKey, since)
+	if err != nil {
+		return nil, fmt.Errorf("pg.GetSpentAmounts: %w", err)
+	}
+
+	caps := []*money.Amount{limits.Daily, limits.Weekly, limits.Monthly}
+	remaining := make([]models.RemainingLimit, len(spendingPeriods))
+
+	for i, period := range spendingPeriods {
+		remaining[i] = models.RemainingLimit{Period: period, Limit: caps[i], Spent: spent[i]}
+
+		if caps[i] == nil {
+			continue
+		}
+
+		left, err := caps[i].Sub(spent[i])
+		if err != nil {
+			return nil, fmt.Errorf("Sub: %w", err)
+		}
+
+		if left.Sign() < 0 {
+			left = money.Amount{}
+		}
+
+		remaining[i].Remaining = &left
+	}
+
+	return remaining, nil
+}
+
+// effectiveLimits returns the limits of a sub-balance. The wallet overrides only apply to the primary
+// balance; sub-balances in other currencies follow the currency defaults.
+func (s *Service) effectiveLimits(ctx context.Context, wallet models.ResponseWalletInstance, currency string) (
+	models.SpendingLimits, error,
+) {
+	limits, err := s.pg.GetCurrencyLimits(ctx, currency)
+	if err != nil {
+		return models.SpendingLimits{}, fmt.Errorf("pg.GetCurrencyLimits: %w", err)
+	}
+
+	if currency != wallet.Currency {
+		return limits, nil
+	}
+
+	overrides, err := s.pg.GetWalletLimits(ctx, wallet.WalletID.String())
+	if err != nil {
+		return models.SpendingLimits{}, fmt.Errorf("pg.GetWalletLimits: %w", err)
+	}
+
+	if overrides.Daily != nil {
+		limits.Daily = overrides.Daily
+	}
+
+	if overrides.Weekly != nil {
+		limits.Weekly = overrides.Weekly
+	}
+
+	if overrides.Monthly != nil {
+		limits.Monthly = overrides.Monthly
+	}
+
+	return limits, nil
+}
+
+func validSpendingLimits(limits models.SpendingLimits, minorUnits int) bool {
+	for _, limit := range []*money.Amount{limits.Daily, limits.Weekly, limits.Monthly} {
+		if limit != nil && (limit.Sign() < 0 || !limit.HasMinorUnits(minorUnits)) {
+			return false
+		}
+	}
+
+	return true
+}
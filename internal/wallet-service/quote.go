@@ -0,0 +1,107 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+const defaultQuoteTTL = 30 * time.Second
+
+var (
+	ErrQuoteNotValid = errors.New("quote request is not valid")
+	ErrQuoteMismatch = errors.New("quote does not match the operation")
+)
+
+// CreateQuote prices the conversion now and locks the rate for QuoteTTL. Operations that pass the quote
+// id are converted at the locked rate instead of the current one.
+func (s *Service) CreateQuote(ctx context.Context, request models.RequestQuote) (models.Quote, error) {
+	if request.Side != models.SideBid && request.Side != models.SideAsk ||
+		request.Currency == request.TargetCurrency || request.Amount.Sign() <= 0 {
+		return models.Quote{}, ErrQuoteNotValid
+	}
+
+	err := s.validateFunds(ctx, models.FundsOperations{Currency: request.Currency, Amount: request.Amount})
+	if err != nil {
+		return models.Quote{}, fmt.Errorf("validateFunds: %w", err)
+	}
+
+	_, err = s.validateCurrency(ctx, request.TargetCurrency)
+	if err != nil {
+		return models.Quote{}, fmt.Errorf("validateCurrency: %w", err)
+	}
+
+	converted, rate, err := s.convert(ctx, request.Side, request.Currency, request.TargetCurrency, request.Amount)
+	if err != nil {
+		return models.Quote{}, fmt.Errorf("convert: %w", err)
+	}
+
+	ttl := s.config.QuoteTTL
+	if ttl <= 0 {
+		ttl = defaultQuoteTTL
+	}
+
+	quote, err := s.pg.CreateQuote(ctx, models.Quote{
+		QuoteID:         uuid.New(),
+		Currency:        request.Currency,
+		Amount:          request.Amount,
+		TargetCurrency:  request.TargetCurrency,
+		ConvertedAmount: converted,
+		ExpiresAt:       time.Now().Add(ttl),
+		AppliedRate:     *rate,
+	})
+	if err != nil {
+		return models.Quote{}, fmt.Errorf("pg.CreateQuote: %w", err)
+	}
+
+	return quote, nil
+}
+
+func (s *Service) GetQuote(ctx context.Context, id string) (models.Quote, error) {
+	quote, err := s.pg.GetQuote(ctx, id)
+	if err != nil {
+		return models.Quote{}, fmt.Errorf("pg.GetQuote: %w", err)
+	}
+
+	return quote, nil
+}
+
+// requestedQuote returns the quote an operation asks for, or an empty one when it asks for none. Whether
+// the quote is still usable is checked when the transaction is booked, after a repeated request has had
+// the chance to replay its response.
+func (s *Service) requestedQuote(ctx context.Context, id uuid.NullUUID) (models.Quote, error) {
+	if !id.Valid {
+		return models.Quote{}, nil
+	}
+
+	quote, err := s.pg.GetQuote(ctx, id.UUID.String())
+	if err != nil {
+		return models.Quote{}, fmt.Errorf("pg.GetQuote: %w", err)
+	}
+
+	return quote, nil
+}
+
+// convertAt converts at the quoted rate when the quote covers this conversion, at the current rate
+// otherwise.
+func (s *Service) convertAt(ctx context.Context, quote models.Quote, side, currentCurrency, requestedCurrency string,
+	amount money.Amount,
+) (money.Amount, *models.AppliedRate, error) {
+	if quoteCovers(quote, side, currentCurrency, requestedCurrency, amount) {
+		rate := quote.AppliedRate
+
+		return quote.ConvertedAmount, &rate, nil
+	}
+
+	return s.convert(ctx, side, currentCurrency, requestedCurrency, amount)
+}
+
+func quoteCovers(quote models.Quote, side, currentCurrency, requestedCurrency string, amount money.Amount) bool {
+	return quote.QuoteID != uuid.Nil && quote.Side == side && quote.Currency == currentCurrency &&
+		quote.TargetCurrency == requestedCurrency && quote.Amount == amount
+}
@@ -0,0 +1,69 @@
+package walletservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/models"
+)
+
+const (
+	defaultRestoreGrace = 30 * 24 * time.Hour
+	purgeInterval       = time.Hour
+)
+
+// RestoreWallet reopens a deleted wallet as long as it was deleted within the restore grace period.
+func (s *Service) RestoreWallet(ctx context.Context, id string, restore models.RequestWalletRestore) (
+	models.ResponseWalletInstance, error,
+) {
+	if strings.TrimSpace(restore.Reason) == "" {
+		return models.ResponseWalletInstance{}, ErrWalletStatusNotValid
+	}
+
+	started := time.Now()
+	defer func() {
+		s.metrics.duration.WithLabelValues("restore_wallet").Observe(time.Since(started).Seconds())
+	}()
+
+	wallet, err := s.pg.RestoreWallet(ctx, id, restore.Reason, time.Now().Add(-s.restoreGrace()))
+	if err != nil {
+		return models.ResponseWalletInstance{}, fmt.Errorf("pg.RestoreWallet: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// PurgeRun anonymizes the wallets that can no longer be restored.
+func (s *Service) PurgeRun(ctx context.Context) error {
+	purgeTicker := time.NewTicker(purgeInterval)
+	defer purgeTicker.Stop()
+
+	for {
+		select {
+		case <-purgeTicker.C:
+			purged, err := s.pg.PurgeDeletedWallets(ctx, s.restoreGrace())
+			if err != nil {
+				s.log.Warningf("pg.PurgeDeletedWallets: %s", err)
+
+				continue
+			}
+
+			if purged > 0 {
+				s.log.Infof("%d deleted wallets purged", purged)
+			}
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (s *Service) restoreGrace() time.Duration {
+	if s.config.RestoreGrace <= 0 {
+		return defaultRestoreGrace
+	}
+
+	return s.config.RestoreGrace
+}
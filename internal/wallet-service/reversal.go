@@ -0,0 +1,282 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/AlexZav1327/service/internal/postgres"
+	"github.com/google/uuid"
+)
+
+const operationReversal = "REVERSAL"
+
+var (
+	ErrReversalNotValid = errors.New("reversal amount or transaction type is not valid")
+	ErrFundsSpent       = errors.New("the credited wallet has already spent the funds")
+	ErrCurrencyClosed   = errors.New("the wallet no longer holds the currency of the transaction")
+	irreversible        = []string{operationReversal, operationConversion}
+)
+
+// ReverseTransaction undoes a transaction, or part of it, by booking every ledger entry of the original
+// in the opposite direction. A partial reversal undoes the same share of each entry, fees included. It
+// fails with ErrFundsSpent when the wallet that received the funds no longer has them, and with
+// ErrCurrencyClosed when a wallet has since moved to another primary currency.
+func (s *Service) ReverseTransaction(ctx context.Context, id string, reversal models.RequestReversal) (
+	models.ResponseReversal, error,
+) {
+	original, err := s.pg.GetTransaction(ctx, id)
+	if err != nil {
+		return models.ResponseReversal{}, fmt.Errorf("pg.GetTransaction: %w", err)
+	}
+
+	for _, operation := range irreversible {
+		if original.OperationType == operation {
+			return models.ResponseReversal{}, ErrReversalNotValid
+		}
+	}
+
+	remaining, err := original.Amount.Sub(original.ReversedAmount)
+	if err != nil {
+		return models.ResponseReversal{}, fmt.Errorf("Sub: %w", err)
+	}
+
+	if remaining.Sign() <= 0 {
+		return models.ResponseReversal{}, postgres.ErrTransactionReversed
+	}
+
+	amount := reversal.Amount
+	if amount.IsZero() {
+		amount = remaining
+	}
+
+	if amount.Sign() < 0 || amount.Cmp(remaining) > 0 {
+		return models.ResponseReversal{}, ErrReversalNotValid
+	}
+
+	currency, err := s.pg.GetCurrency(ctx, original.Currency)
+	if err != nil {
+		return models.ResponseReversal{}, fmt.Errorf("pg.GetCurrency: %w", err)
+	}
+
+	if !amount.HasMinorUnits(currency.MinorUnits) {
+		return models.ResponseReversal{}, money.ErrPrecision
+	}
+
+	txn, err := s.reversalTransaction(ctx, original, amount, reversal.TransactionKey)
+	if err != nil {
+		return models.ResponseReversal{}, fmt.Errorf("reversalTransaction: %w", err)
+	}
+
+	key, err := idempotencyKey(reversal.TransactionKey, "reversal", id, reversal)
+	if err != nil {
+		return models.ResponseReversal{}, fmt.Errorf("idempotencyKey: %w", err)
+	}
+
+	started := time.Now()
+	defer func() {
+		s.metrics.duration.WithLabelValues("reversal").Observe(time.Since(started).Seconds())
+	}()
+
+	response, err := s.pg.ReverseTransaction(ctx, key, txn)
+	if errors.Is(err, postgres.ErrOverdraft) {
+		return models.ResponseReversal{}, ErrFundsSpent
+	}
+
+	if err != nil {
+		return models.ResponseReversal{}, fmt.Errorf("pg.ReverseTransaction: %w", err)
+	}
+
+	return response, nil
+}
+
+// reversalTransaction shares the entries of the original out by what has been reversed of it once this
+// reversal is booked, less what earlier reversals already booked for each entry. The reversal that
+// completes the original therefore books exactly what is left of every entry.
+func (s *Service) reversalTransaction(ctx context.Context, original models.Transaction, amount money.Amount,
+	transactionKey uuid.UUID,
+) (models.LedgerTransaction, error) {
+	entries, err := s.pg.GetTransactionEntries(ctx, original.TransactionID.String())
+	if err != nil {
+		return models.LedgerTransaction{}, fmt.Errorf("pg.GetTransactionEntries: %w", err)
+	}
+
+	reversedEntries, err := s.pg.GetReversedEntries(ctx, original.TransactionID.String())
+	if err != nil {
+		return models.LedgerTransaction{}, fmt.Errorf("pg.GetReversedEntries: %w", err)
+	}
+
+	reversedAfter, err := original.ReversedAmount.Add(amount)
+	if err != nil {
+		return models.LedgerTransaction{}, fmt.Errorf("Add: %w", err)
+	}
+
+	cumulative := func(value money.Amount, code string, reversed money.Amount) (money.Amount, error) {
+		if reversed == original.Amount {
+			return value, nil
+		}
+
+		if reversed.IsZero() {
+			return money.Amount{}, nil
+		}
+
+		currency, err := s.pg.GetCurrency(ctx, code)
+		if err != nil {
+			return money.Amount{}, fmt.Errorf("pg.GetCurrency: %w", err)
+		}
+
+		return value.Share(reversed, original.Amount, currency.MinorUnits)
+	}
+
+	share := func(value money.Amount, code string) (money.Amount, error) {
+		after, err := cumulative(value, code, reversedAfter)
+		if err != nil {
+			return money.Amount{}, err
+		}
+
+		before, err := cumulative(value, code, original.ReversedAmount)
+		if err != nil {
+			return money.Amount{}, err
+		}
+
+		return after.Sub(before)
+	}
+
+	txn := newLedgerTransaction(operationReversal, uuid.NullUUID{UUID: transactionKey, Valid: true}, amount,
+		original.Currency)
+	txn.ReversalOf = uuid.NullUUID{UUID: original.TransactionID, Valid: true}
+	txn.ReversedBefore = original.ReversedAmount
+
+	if original.Destination != nil {
+		txn.Source, err = reversedLeg(*original.Destination, share)
+		if err != nil {
+			return models.LedgerTransaction{}, fmt.Errorf("reversedLeg: %w", err)
+		}
+	}
+
+	if original.Source != nil {
+		txn.Destination, err = reversedLeg(*original.Source, share)
+		if err != nil {
+			return models.LedgerTransaction{}, fmt.Errorf("reversedLeg: %w", err)
+		}
+	}
+
+	booked := make(map[entryKey]money.Amount, len(reversedEntries))
+
+	for _, entry := range reversedEntries {
+		booked[newEntryKey(entry)] = entry.Amount
+	}
+
+	wallets := make(map[uuid.UUID]models.ResponseWalletInstance)
+
+	for _, entry := range entries {
+		if entry.Direction == models.Debit {
+			entry.Direction = models.Credit
+		} else {
+			entry.Direction = models.Debit
+		}
+
+		key := newEntryKey(entry)
+
+		reversed := booked[key]
+		if reversed.Cmp(entry.Amount) > 0 {
+			reversed = entry.Amount
+		}
+
+		booked[key], err = booked[key].Sub(reversed)
+		if err != nil {
+			return models.LedgerTransaction{}, fmt.Errorf("Sub: %w", err)
+		}
+
+		after, err := cumulative(entry.Amount, entry.Currency, reversedAfter)
+		if err != nil {
+			return models.LedgerTransaction{}, fmt.Errorf("cumulative: %w", err)
+		}
+
+		entry.Amount, err = after.Sub(reversed)
+		if err != nil {
+			return models.LedgerTransaction{}, fmt.Errorf("Sub: %w", err)
+		}
+
+		if entry.Amount.Sign() <= 0 {
+			continue
+		}
+
+		if entry.WalletID.Valid {
+			err = s.checkReversalCurrency(ctx, wallets, entry)
+			if err != nil {
+				return models.LedgerTransaction{}, fmt.Errorf("checkReversalCurrency: %w", err)
+			}
+		}
+
+		txn.Entries = append(txn.Entries, entry)
+	}
+
+	if len(txn.Entries) == 0 {
+		return models.LedgerTransaction{}, ErrReversalNotValid
+	}
+
+	return txn, nil
+}
+
+type entryKey struct {
+	account   string
+	walletID  uuid.NullUUID
+	direction string
+	currency  string
+}
+
+func newEntryKey(entry models.LedgerEntry) entryKey {
+	return entryKey{
+		account:   entry.Account,
+		walletID:  entry.WalletID,
+		direction: entry.Direction,
+		currency:  entry.Currency,
+	}
+}
+
+// checkReversalCurrency makes sure the wallet of the entry still holds its currency, either as the primary
+// currency or as a sub-balance. A wallet that has since been converted to another currency no longer does.
+func (s *Service) checkReversalCurrency(ctx context.Context, wallets map[uuid.UUID]models.ResponseWalletInstance,
+	entry models.LedgerEntry,
+) error {
+	wallet, ok := wallets[entry.WalletID.UUID]
+	if !ok {
+		var err error
+
+		wallet, err = s.pg.GetWallet(ctx, entry.WalletID.UUID.String())
+		if err != nil {
+			return fmt.Errorf("pg.GetWallet: %w", err)
+		}
+
+		wallets[entry.WalletID.UUID] = wallet
+	}
+
+	if wallet.Currency == entry.Currency {
+		return nil
+	}
+
+	for _, subBalance := range wallet.SubBalances {
+		if subBalance.Currency == entry.Currency {
+			return nil
+		}
+	}
+
+	return ErrCurrencyClosed
+}
+
+// reversedLeg returns the share of a leg of the original transaction. The reversal swaps the legs, so
+// the wallet credited by the original is the one debited.
+func reversedLeg(leg models.TransactionLeg, share func(money.Amount, string) (money.Amount, error)) (
+	*models.TransactionLeg, error,
+) {
+	amount, err := share(leg.Amount, leg.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return transactionLeg(leg.WalletID, amount, leg.Currency, leg.AppliedRate), nil
+}
@@ -4,27 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math"
 	"time"
 
 	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
-const (
-	eur            = "EUR"
-	rub            = "RUB"
-	usd            = "USD"
-	tickerInterval = 12
-)
+const tickerInterval = 12
 
-var (
-	ErrOverdraft        = errors.New("overdrafts are not allowed")
-	ErrCurrencyNotValid = errors.New("currency is not valid")
-)
+var ErrCurrencyNotValid = errors.New("currency is not valid")
 
 type Service struct {
+	config       Config
 	pg           walletStore
 	xr           exchangeRates
 	message      messageCreator
@@ -34,18 +27,79 @@ type Service struct {
 }
 
 type walletStore interface {
-	CreateWallet(ctx context.Context, wallet models.RequestWalletInstance) (models.ResponseWalletInstance, error)
+	CreateWallet(ctx context.Context, wallet models.RequestWalletInstance, key models.IdempotencyKey) (
+		models.ResponseWalletInstance, error)
 	GetWallet(ctx context.Context, id string) (models.ResponseWalletInstance, error)
+	ClaimWalletOwner(ctx context.Context, id, subject, email string) (string, error)
 	GetWalletsList(ctx context.Context, params models.ListingQueryParams) ([]models.ResponseWalletInstance, error)
-	GetWalletHistory(ctx context.Context, id string, params models.RequestWalletHistory) (
+	GetWalletHistory(ctx context.Context, params models.RequestWalletHistory) (
 		[]models.ResponseWalletHistory, error)
-	UpdateWallet(ctx context.Context, wallet models.RequestWalletInstance) (models.ResponseWalletInstance, error)
-	DeleteWallet(ctx context.Context, id string) error
-	ManageBalance(ctx context.Context, transactionKey uuid.UUID, id string, balance float32) (
+	UpdateWallet(ctx context.Context, wallet models.RequestWalletInstance, txn models.LedgerTransaction) (
 		models.ResponseWalletInstance, error)
-	TransferFunds(ctx context.Context, transactionKey uuid.UUID, idSrc, idDst string, balanceSrc, balanceDst float32) (
+	CloseWallet(ctx context.Context, id, from, reason string, sweeps []models.LedgerTransaction) (
 		models.ResponseWalletInstance, error)
+	ManageBalance(ctx context.Context, key models.IdempotencyKey, id string, txn models.LedgerTransaction) (
+		models.ResponseFundsOperation, error)
+	TransferFunds(ctx context.Context, key models.IdempotencyKey, idSrc, idDst string,
+		txn models.LedgerTransaction) (models.ResponseTransfer, error)
+	BatchTransfer(ctx context.Context, key models.IdempotencyKey, idSrc, currency string,
+		entries []models.BatchTransferEntry, bestEffort bool, itemError func(error) error) (
+		models.ResponseBatchTransfer, error)
+	GetTransaction(ctx context.Context, id string) (models.Transaction, error)
+	GetTransactionEntries(ctx context.Context, id string) ([]models.LedgerEntry, error)
+	GetReversedEntries(ctx context.Context, id string) ([]models.LedgerEntry, error)
+	ReverseTransaction(ctx context.Context, key models.IdempotencyKey, txn models.LedgerTransaction) (
+		models.ResponseReversal, error)
+	GetWalletTransactions(ctx context.Context, id string, params models.RequestTransactionsList) (
+		[]models.Transaction, error)
 	TrackInactiveWallets(ctx context.Context) ([]models.ResponseWalletInstance, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context, retention time.Duration) (int64, error)
+	CreateCurrency(ctx context.Context, currency models.Currency) (models.Currency, error)
+	GetCurrency(ctx context.Context, code string) (models.Currency, error)
+	GetCurrencies(ctx context.Context) ([]models.Currency, error)
+	UpdateCurrency(ctx context.Context, currency models.Currency) (models.Currency, error)
+	GetCurrencyLimits(ctx context.Context, code string) (models.SpendingLimits, error)
+	SetCurrencyLimits(ctx context.Context, code string, limits models.SpendingLimits) (models.SpendingLimits, error)
+	GetWalletLimits(ctx context.Context, id string) (models.SpendingLimits, error)
+	SetWalletLimits(ctx context.Context, id string, limits models.SpendingLimits) (models.SpendingLimits, error)
+	GetSpentAmounts(ctx context.Context, id, currency string, operations []string, excludeKey uuid.NullUUID,
+		since []time.Time) ([]money.Amount, error)
+	AuthorizeHold(ctx context.Context, key models.IdempotencyKey, hold models.Hold) (models.ResponseHold, error)
+	GetHold(ctx context.Context, id string) (models.Hold, error)
+	CaptureHold(ctx context.Context, key models.IdempotencyKey, hold models.Hold, txn models.LedgerTransaction) (
+		models.ResponseHold, error)
+	VoidHold(ctx context.Context, hold models.Hold) (models.ResponseHold, error)
+	ExpireHolds(ctx context.Context) (int64, error)
+	SetCreditLimit(ctx context.Context, id string, limit money.Amount) (models.ResponseWalletInstance, error)
+	OpenSubBalance(ctx context.Context, id, currency string) (models.ResponseWalletInstance, error)
+	SetWalletStatus(ctx context.Context, id, from, to, reason string) (models.ResponseWalletInstance, error)
+	RestoreWallet(ctx context.Context, id, reason string, deletedAfter time.Time) (models.ResponseWalletInstance,
+		error)
+	PurgeDeletedWallets(ctx context.Context, retention time.Duration) (int64, error)
+	CreateStandingOrder(ctx context.Context, order models.StandingOrder) (models.StandingOrder, error)
+	GetStandingOrder(ctx context.Context, id string) (models.StandingOrder, error)
+	GetWalletStandingOrders(ctx context.Context, id string) ([]models.StandingOrder, error)
+	UpdateStandingOrder(ctx context.Context, order models.StandingOrder) (models.StandingOrder, error)
+	CancelStandingOrder(ctx context.Context, id string) error
+	GetDueStandingOrders(ctx context.Context, now time.Time, limit int) ([]models.StandingOrder, error)
+	RecordStandingOrderExecution(ctx context.Context, execution models.StandingOrderExecution, nextRunAt time.Time,
+		status string) error
+	GetStandingOrderExecutions(ctx context.Context, id string) ([]models.StandingOrderExecution, error)
+	CreateQuote(ctx context.Context, quote models.Quote) (models.Quote, error)
+	GetQuote(ctx context.Context, id string) (models.Quote, error)
+	DeleteExpiredQuotes(ctx context.Context, retention time.Duration) (int64, error)
+	CreateSession(ctx context.Context, session models.Session, refreshTokenHash string) (models.Session, error)
+	RotateSession(ctx context.Context, id uuid.UUID, refreshTokenHash, newRefreshTokenHash string,
+		access models.AccessToken) (models.Session, error)
+	RevokeSessions(ctx context.Context, subject string, sessionID uuid.NullUUID) error
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+	DeleteExpiredSessions(ctx context.Context, retention time.Duration) (int64, error)
+	CreateAPIKey(ctx context.Context, apiKey models.APIKey, keyHash string) (models.APIKey, error)
+	GetAPIKey(ctx context.Context, id string) (models.APIKey, error)
+	GetAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	UseAPIKey(ctx context.Context, keyHash string) (models.APIKey, error)
 }
 
 type exchangeRates interface {
@@ -54,14 +108,36 @@ type exchangeRates interface {
 
 type messageCreator interface {
 	CreateMessage(wallet models.ResponseWalletInstance) ([]byte, error)
+	CreateOverdraftMessage(wallet models.ResponseWalletInstance) ([]byte, error)
+	CreateStandingOrderMessage(wallet models.ResponseWalletInstance, order models.StandingOrder,
+		execution models.StandingOrderExecution) ([]byte, error)
 }
 
 type notifier interface {
 	Notify(ctx context.Context, message []byte) error
 }
 
-func New(pg walletStore, xr exchangeRates, message messageCreator, notification notifier, log *logrus.Logger) *Service {
+// Config holds the business settings of the service. SpreadBps is the house spread, in basis points,
+// applied on top of the provider rate in the customer's disfavour. Fees are charged by FeeRules and
+// credited to FeeWalletID; no fees are charged while it is unset. HoldTTL is how long a hold lives
+// unless the authorization asks for another TTL. Deleted wallets can be restored for RestoreGrace and
+// are anonymized afterwards. QuoteTTL is how long a quoted rate stays locked. Sessions end RefreshTTL
+// after they were opened, however often they are refreshed.
+type Config struct {
+	SpreadBps    int64
+	FeeWalletID  uuid.UUID
+	FeeRules     []FeeRule
+	HoldTTL      time.Duration
+	RestoreGrace time.Duration
+	QuoteTTL     time.Duration
+	RefreshTTL   time.Duration
+}
+
+func New(pg walletStore, xr exchangeRates, message messageCreator, notification notifier, log *logrus.Logger,
+	config Config,
+) *Service {
 	return &Service{
+		config:       config,
 		pg:           pg,
 		xr:           xr,
 		log:          log.WithField("module", "service"),
@@ -74,9 +150,15 @@ func New(pg walletStore, xr exchangeRates, message messageCreator, notification
 func (s *Service) CreateWallet(ctx context.Context, wallet models.RequestWalletInstance) (
 	models.ResponseWalletInstance, error,
 ) {
-	err := s.validateCurrency(wallet.Currency)
+	_, err := s.validateCurrency(ctx, wallet.Currency)
+	if err != nil {
+		return models.ResponseWalletInstance{}, fmt.Errorf("validateCurrency: %w", err)
+	}
+
+	key, err := idempotencyKey(wallet.TransactionKey, "create_wallet", wallet.Email, wallet.Owner, wallet.Currency,
+		wallet.OwnerID)
 	if err != nil {
-		return models.ResponseWalletInstance{}, ErrCurrencyNotValid
+		return models.ResponseWalletInstance{}, fmt.Errorf("idempotencyKey: %w", err)
 	}
 
 	started := time.Now()
@@ -84,7 +166,7 @@ func (s *Service) CreateWallet(ctx context.Context, wallet models.RequestWalletI
 		s.metrics.duration.WithLabelValues("create_wallet").Observe(time.Since(started).Seconds())
 	}()
 
-	createdWallet, err := s.pg.CreateWallet(ctx, wallet)
+	createdWallet, err := s.pg.CreateWallet(ctx, wallet, key)
 	if err != nil {
 		return models.ResponseWalletInstance{}, fmt.Errorf("pg.CreateWallet: %w", err)
 	}
@@ -119,10 +201,19 @@ func (s *Service) GetWalletsList(ctx context.Context, params models.ListingQuery
 	return walletsList, nil
 }
 
-func (s *Service) GetWalletHistory(ctx context.Context, id string, params models.RequestWalletHistory) (
+func (s *Service) ClaimWalletOwner(ctx context.Context, id, subject, email string) (string, error) {
+	ownerID, err := s.pg.ClaimWalletOwner(ctx, id, subject, email)
+	if err != nil {
+		return "", fmt.Errorf("pg.ClaimWalletOwner: %w", err)
+	}
+
+	return ownerID, nil
+}
+
+func (s *Service) GetWalletHistory(ctx context.Context, params models.RequestWalletHistory) (
 	[]models.ResponseWalletHistory, error,
 ) {
-	walletHistory, err := s.pg.GetWalletHistory(ctx, id, params)
+	walletHistory, err := s.pg.GetWalletHistory(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("pg.GetWalletHistory: %w", err)
 	}
@@ -133,9 +224,9 @@ func (s *Service) GetWalletHistory(ctx context.Context, id string, params models
 func (s *Service) UpdateWallet(ctx context.Context, wallet models.RequestWalletInstance) (
 	models.ResponseWalletInstance, error,
 ) {
-	err := s.validateCurrency(wallet.Currency)
+	_, err := s.validateCurrency(ctx, wallet.Currency)
 	if err != nil {
-		return models.ResponseWalletInstance{}, ErrCurrencyNotValid
+		return models.ResponseWalletInstance{}, fmt.Errorf("validateCurrency: %w", err)
 	}
 
 	currentWallet, err := s.pg.GetWallet(ctx, wallet.WalletID.String())
@@ -147,13 +238,23 @@ func (s *Service) UpdateWallet(ctx context.Context, wallet models.RequestWalletI
 		wallet.Owner = currentWallet.Owner
 	}
 
+	txn := newLedgerTransaction(operationConversion, uuid.NullUUID{}, currentWallet.Balance, currentWallet.Currency)
+
 	if wallet.Currency == currentWallet.Currency || wallet.Currency == "" {
 		wallet.Balance = currentWallet.Balance
 	} else {
-		wallet.Balance, err = s.ConvertCurrency(ctx, currentWallet.Currency, wallet.Currency, currentWallet.Balance)
+		var rate *models.AppliedRate
+
+		wallet.Balance, rate, err = s.convert(ctx, models.SideBid, currentWallet.Currency, wallet.Currency,
+			currentWallet.Balance)
 		if err != nil {
-			return models.ResponseWalletInstance{}, fmt.Errorf("ConvertCurrency: %w", err)
+			return models.ResponseWalletInstance{}, fmt.Errorf("convert: %w", err)
 		}
+
+		txn.Source = transactionLeg(currentWallet.WalletID, currentWallet.Balance, currentWallet.Currency, nil)
+		txn.Destination = transactionLeg(currentWallet.WalletID, wallet.Balance, wallet.Currency, rate)
+		addExchange(&txn, walletAccount(currentWallet.WalletID), currentWallet.Balance, currentWallet.Currency,
+			walletAccount(currentWallet.WalletID), wallet.Balance, wallet.Currency)
 	}
 
 	started := time.Now()
@@ -161,7 +262,7 @@ func (s *Service) UpdateWallet(ctx context.Context, wallet models.RequestWalletI
 		s.metrics.duration.WithLabelValues("update_wallet").Observe(time.Since(started).Seconds())
 	}()
 
-	updatedWallet, err := s.pg.UpdateWallet(ctx, wallet)
+	updatedWallet, err := s.pg.UpdateWallet(ctx, wallet, txn)
 	if err != nil {
 		return models.ResponseWalletInstance{}, fmt.Errorf("pg.UpdateWallet: %w", err)
 	}
@@ -169,49 +270,73 @@ func (s *Service) UpdateWallet(ctx context.Context, wallet models.RequestWalletI
 	return updatedWallet, nil
 }
 
-func (s *Service) DeleteWallet(ctx context.Context, id string) error {
-	started := time.Now()
-	defer func() {
-		s.metrics.duration.WithLabelValues("delete_wallet").Observe(time.Since(started).Seconds())
-	}()
-
-	err := s.pg.DeleteWallet(ctx, id)
+// DeleteWallet closes the wallet. A wallet that still holds funds is only closed when sweepTo names the
+// wallet that takes them over.
+func (s *Service) DeleteWallet(ctx context.Context, id string, sweepTo uuid.NullUUID) error {
+	currentWallet, err := s.pg.GetWallet(ctx, id)
 	if err != nil {
-		return fmt.Errorf("pg.DeleteWallet: %w", err)
+		return fmt.Errorf("pg.GetWallet: %w", err)
 	}
 
-	s.metrics.deletedWallets.Inc()
+	_, err = s.closeWallet(ctx, currentWallet, "", sweepTo)
+	if err != nil {
+		return fmt.Errorf("closeWallet: %w", err)
+	}
 
 	return nil
 }
 
 func (s *Service) DepositFunds(ctx context.Context, id string, depositFunds models.FundsOperations) (
-	models.ResponseWalletInstance, error,
+	models.ResponseFundsOperation, error,
 ) {
-	err := s.validateCurrency(depositFunds.Currency)
+	err := s.validateFunds(ctx, depositFunds)
 	if err != nil {
-		return models.ResponseWalletInstance{}, ErrCurrencyNotValid
+		return models.ResponseFundsOperation{}, fmt.Errorf("validateFunds: %w", err)
 	}
 
 	currentWallet, err := s.pg.GetWallet(ctx, id)
 	if err != nil {
-		return models.ResponseWalletInstance{}, fmt.Errorf("pg.GetWallet: %w", err)
+		return models.ResponseFundsOperation{}, fmt.Errorf("pg.GetWallet: %w", err)
 	}
 
-	balance := currentWallet.Balance + depositFunds.Amount
+	creditCurrency := creditedSubBalance(currentWallet, depositFunds.Currency)
 
-	if depositFunds.Currency != currentWallet.Currency {
-		convertedDepositAmount, err := s.ConvertCurrency(
-			ctx,
-			depositFunds.Currency,
-			currentWallet.Currency,
-			depositFunds.Amount,
-		)
-		if err != nil {
-			return models.ResponseWalletInstance{}, fmt.Errorf("ConvertCurrency: %w", err)
-		}
+	quote, err := s.requestedQuote(ctx, depositFunds.QuoteID)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("requestedQuote: %w", err)
+	}
+
+	if depositFunds.QuoteID.Valid &&
+		!quoteCovers(quote, models.SideBid, depositFunds.Currency, creditCurrency, depositFunds.Amount) {
+		return models.ResponseFundsOperation{}, ErrQuoteMismatch
+	}
 
-		balance = currentWallet.Balance + convertedDepositAmount
+	depositAmount, rate, err := s.convertAt(ctx, quote, models.SideBid, depositFunds.Currency, creditCurrency,
+		depositFunds.Amount)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("convertAt: %w", err)
+	}
+
+	txn := newLedgerTransaction(operationDeposit, uuid.NullUUID{UUID: depositFunds.TransactionKey, Valid: true},
+		depositFunds.Amount, depositFunds.Currency)
+	txn.QuoteID = depositFunds.QuoteID
+	txn.Destination = transactionLeg(currentWallet.WalletID, depositAmount, creditCurrency, rate)
+	addExchange(&txn, systemAccount(externalAccount, depositFunds.Currency), depositFunds.Amount,
+		depositFunds.Currency, walletAccount(currentWallet.WalletID), depositAmount, creditCurrency)
+
+	err = s.chargeFee(ctx, &txn, currentWallet.WalletID, creditCurrency, depositAmount, depositFunds.Currency,
+		creditCurrency)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("chargeFee: %w", err)
+	}
+
+	if txn.Fee != nil && txn.Fee.Amount.Cmp(depositAmount) >= 0 {
+		return models.ResponseFundsOperation{}, ErrFeeExceedsAmount
+	}
+
+	key, err := idempotencyKey(depositFunds.TransactionKey, "deposit", id, depositFunds)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("idempotencyKey: %w", err)
 	}
 
 	started := time.Now()
@@ -219,47 +344,77 @@ func (s *Service) DepositFunds(ctx context.Context, id string, depositFunds mode
 		s.metrics.duration.WithLabelValues("deposit").Observe(time.Since(started).Seconds())
 	}()
 
-	updatedWallet, err := s.pg.ManageBalance(ctx, depositFunds.TransactionKey, id, balance)
+	response, err := s.pg.ManageBalance(ctx, key, id, txn)
 	if err != nil {
-		return models.ResponseWalletInstance{}, fmt.Errorf("pg.ManageFunds: %w", err)
+		return models.ResponseFundsOperation{}, fmt.Errorf("pg.ManageFunds: %w", err)
 	}
 
-	s.metrics.funds.WithLabelValues(depositFunds.Currency).Add(float64(depositFunds.Amount))
+	s.metrics.funds.WithLabelValues(depositFunds.Currency).Add(depositFunds.Amount.Float64())
 
-	return updatedWallet, nil
+	return response, nil
 }
 
 func (s *Service) WithdrawFunds(ctx context.Context, id string, withdrawFunds models.FundsOperations) (
-	models.ResponseWalletInstance, error,
+	models.ResponseFundsOperation, error,
 ) {
-	err := s.validateCurrency(withdrawFunds.Currency)
+	err := s.validateFunds(ctx, withdrawFunds)
 	if err != nil {
-		return models.ResponseWalletInstance{}, ErrCurrencyNotValid
+		return models.ResponseFundsOperation{}, fmt.Errorf("validateFunds: %w", err)
 	}
 
 	currentWallet, err := s.pg.GetWallet(ctx, id)
 	if err != nil {
-		return models.ResponseWalletInstance{}, fmt.Errorf("pg.GetWallet: %w", err)
+		return models.ResponseFundsOperation{}, fmt.Errorf("pg.GetWallet: %w", err)
 	}
 
-	balance := currentWallet.Balance - withdrawFunds.Amount
+	err = checkCanSend(currentWallet)
+	if err != nil {
+		return models.ResponseFundsOperation{}, err
+	}
 
-	if withdrawFunds.Currency != currentWallet.Currency {
-		convertedWithdrawAmount, err := s.ConvertCurrency(
-			ctx,
-			withdrawFunds.Currency,
-			currentWallet.Currency,
-			withdrawFunds.Amount,
-		)
-		if err != nil {
-			return models.ResponseWalletInstance{}, fmt.Errorf("ConvertCurrency: %w", err)
-		}
+	debitCurrency, err := debitedSubBalance(currentWallet, withdrawFunds.FromCurrency)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("debitedSubBalance: %w", err)
+	}
+
+	quote, err := s.requestedQuote(ctx, withdrawFunds.QuoteID)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("requestedQuote: %w", err)
+	}
+
+	if withdrawFunds.QuoteID.Valid &&
+		!quoteCovers(quote, models.SideAsk, withdrawFunds.Currency, debitCurrency, withdrawFunds.Amount) {
+		return models.ResponseFundsOperation{}, ErrQuoteMismatch
+	}
+
+	withdrawAmount, rate, err := s.convertAt(ctx, quote, models.SideAsk, withdrawFunds.Currency, debitCurrency,
+		withdrawFunds.Amount)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("convertAt: %w", err)
+	}
+
+	txn := newLedgerTransaction(operationWithdrawal, uuid.NullUUID{UUID: withdrawFunds.TransactionKey, Valid: true},
+		withdrawFunds.Amount, withdrawFunds.Currency)
+	txn.QuoteID = withdrawFunds.QuoteID
+
+	txn.Spending, err = s.spendingCheck(ctx, currentWallet, debitCurrency)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("spendingCheck: %w", err)
+	}
+
+	txn.Source = transactionLeg(currentWallet.WalletID, withdrawAmount, debitCurrency, rate)
+	addExchange(&txn, walletAccount(currentWallet.WalletID), withdrawAmount, debitCurrency,
+		systemAccount(externalAccount, withdrawFunds.Currency), withdrawFunds.Amount, withdrawFunds.Currency)
 
-		balance = currentWallet.Balance - convertedWithdrawAmount
+	err = s.chargeFee(ctx, &txn, currentWallet.WalletID, debitCurrency, withdrawAmount, debitCurrency,
+		withdrawFunds.Currency)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("chargeFee: %w", err)
 	}
 
-	if balance < 0 {
-		return models.ResponseWalletInstance{}, ErrOverdraft
+	key, err := idempotencyKey(withdrawFunds.TransactionKey, "withdraw", id, withdrawFunds)
+	if err != nil {
+		return models.ResponseFundsOperation{}, fmt.Errorf("idempotencyKey: %w", err)
 	}
 
 	started := time.Now()
@@ -267,62 +422,93 @@ func (s *Service) WithdrawFunds(ctx context.Context, id string, withdrawFunds mo
 		s.metrics.duration.WithLabelValues("withdraw").Observe(time.Since(started).Seconds())
 	}()
 
-	updatedWallet, err := s.pg.ManageBalance(ctx, withdrawFunds.TransactionKey, id, balance)
+	response, err := s.pg.ManageBalance(ctx, key, id, txn)
 	if err != nil {
-		return models.ResponseWalletInstance{}, fmt.Errorf("pg.ManageFunds: %w", err)
+		return models.ResponseFundsOperation{}, fmt.Errorf("pg.ManageFunds: %w", err)
 	}
 
-	s.metrics.funds.WithLabelValues(withdrawFunds.Currency).Sub(float64(withdrawFunds.Amount))
+	s.metrics.funds.WithLabelValues(withdrawFunds.Currency).Sub(withdrawFunds.Amount.Float64())
+	s.notifyNegativeBalance(ctx, currentWallet, response.ResponseWalletInstance)
 
-	return updatedWallet, nil
+	return response, nil
 }
 
 func (s *Service) TransferFunds(ctx context.Context, idSrc, idDst string, transferFunds models.FundsOperations) (
-	models.ResponseWalletInstance, error,
+	models.ResponseTransfer, error,
 ) {
-	err := s.validateCurrency(transferFunds.Currency)
+	err := s.validateFunds(ctx, transferFunds)
 	if err != nil {
-		return models.ResponseWalletInstance{}, ErrCurrencyNotValid
+		return models.ResponseTransfer{}, fmt.Errorf("validateFunds: %w", err)
 	}
 
 	currentSrcWallet, err := s.pg.GetWallet(ctx, idSrc)
 	if err != nil {
-		return models.ResponseWalletInstance{}, fmt.Errorf("pg.GetWallet: %w", err)
+		return models.ResponseTransfer{}, fmt.Errorf("pg.GetWallet: %w", err)
 	}
 
-	balanceSrc := currentSrcWallet.Balance - transferFunds.Amount
+	err = checkCanSend(currentSrcWallet)
+	if err != nil {
+		return models.ResponseTransfer{}, err
+	}
 
-	if transferFunds.Currency != currentSrcWallet.Currency {
-		convertedWithdrawAmount, err := s.ConvertCurrency(ctx, transferFunds.Currency, currentSrcWallet.Currency,
-			transferFunds.Amount,
-		)
-		if err != nil {
-			return models.ResponseWalletInstance{}, fmt.Errorf("ConvertCurrency: %w", err)
-		}
+	debitCurrency, err := debitedSubBalance(currentSrcWallet, transferFunds.FromCurrency)
+	if err != nil {
+		return models.ResponseTransfer{}, fmt.Errorf("debitedSubBalance: %w", err)
+	}
 
-		balanceSrc = currentSrcWallet.Balance - convertedWithdrawAmount
+	quote, err := s.requestedQuote(ctx, transferFunds.QuoteID)
+	if err != nil {
+		return models.ResponseTransfer{}, fmt.Errorf("requestedQuote: %w", err)
 	}
 
-	if balanceSrc < 0 {
-		return models.ResponseWalletInstance{}, ErrOverdraft
+	withdrawAmount, srcRate, err := s.convertAt(ctx, quote, models.SideAsk, transferFunds.Currency, debitCurrency,
+		transferFunds.Amount)
+	if err != nil {
+		return models.ResponseTransfer{}, fmt.Errorf("convertAt: %w", err)
 	}
 
 	currentDstWallet, err := s.pg.GetWallet(ctx, idDst)
 	if err != nil {
-		return models.ResponseWalletInstance{}, fmt.Errorf("pg.GetWallet: %w", err)
+		return models.ResponseTransfer{}, fmt.Errorf("pg.GetWallet: %w", err)
 	}
 
-	balanceDst := currentDstWallet.Balance + transferFunds.Amount
+	creditCurrency := creditedSubBalance(currentDstWallet, transferFunds.Currency)
 
-	if transferFunds.Currency != currentDstWallet.Currency {
-		convertedDepositAmount, err := s.ConvertCurrency(ctx, transferFunds.Currency, currentDstWallet.Currency,
-			transferFunds.Amount,
-		)
-		if err != nil {
-			return models.ResponseWalletInstance{}, fmt.Errorf("ConvertCurrency: %w", err)
-		}
+	if transferFunds.QuoteID.Valid &&
+		!quoteCovers(quote, models.SideAsk, transferFunds.Currency, debitCurrency, transferFunds.Amount) &&
+		!quoteCovers(quote, models.SideBid, transferFunds.Currency, creditCurrency, transferFunds.Amount) {
+		return models.ResponseTransfer{}, ErrQuoteMismatch
+	}
+
+	depositAmount, dstRate, err := s.convertAt(ctx, quote, models.SideBid, transferFunds.Currency, creditCurrency,
+		transferFunds.Amount)
+	if err != nil {
+		return models.ResponseTransfer{}, fmt.Errorf("convertAt: %w", err)
+	}
+
+	txn := newLedgerTransaction(operationTransfer, uuid.NullUUID{UUID: transferFunds.TransactionKey, Valid: true},
+		transferFunds.Amount, transferFunds.Currency)
+	txn.QuoteID = transferFunds.QuoteID
+
+	txn.Spending, err = s.spendingCheck(ctx, currentSrcWallet, debitCurrency)
+	if err != nil {
+		return models.ResponseTransfer{}, fmt.Errorf("spendingCheck: %w", err)
+	}
 
-		balanceDst = currentDstWallet.Balance + convertedDepositAmount
+	txn.Source = transactionLeg(currentSrcWallet.WalletID, withdrawAmount, debitCurrency, srcRate)
+	txn.Destination = transactionLeg(currentDstWallet.WalletID, depositAmount, creditCurrency, dstRate)
+	addExchange(&txn, walletAccount(currentSrcWallet.WalletID), withdrawAmount, debitCurrency,
+		walletAccount(currentDstWallet.WalletID), depositAmount, creditCurrency)
+
+	err = s.chargeFee(ctx, &txn, currentSrcWallet.WalletID, debitCurrency, withdrawAmount, debitCurrency,
+		creditCurrency)
+	if err != nil {
+		return models.ResponseTransfer{}, fmt.Errorf("chargeFee: %w", err)
+	}
+
+	key, err := idempotencyKey(transferFunds.TransactionKey, "transfer", idSrc, idDst, transferFunds)
+	if err != nil {
+		return models.ResponseTransfer{}, fmt.Errorf("idempotencyKey: %w", err)
 	}
 
 	started := time.Now()
@@ -330,37 +516,95 @@ func (s *Service) TransferFunds(ctx context.Context, idSrc, idDst string, transf
 		s.metrics.duration.WithLabelValues("transfer").Observe(time.Since(started).Seconds())
 	}()
 
-	updatedWallet, err := s.pg.TransferFunds(ctx, transferFunds.TransactionKey, idSrc, idDst, balanceSrc, balanceDst)
+	response, err := s.pg.TransferFunds(ctx, key, idSrc, idDst, txn)
 	if err != nil {
-		return models.ResponseWalletInstance{}, fmt.Errorf("pg.TransferFunds: %w", err)
+		return models.ResponseTransfer{}, fmt.Errorf("pg.TransferFunds: %w", err)
 	}
 
-	return updatedWallet, nil
+	s.notifyNegativeBalance(ctx, currentSrcWallet, response.SrcWallet)
+
+	return response, nil
 }
 
-func (s *Service) ConvertCurrency(ctx context.Context, currentCurrency, requestedCurrency string,
-	currentBalance float32,
-) (float32, error) {
-	rates, err := s.xr.GetRate(ctx, currentCurrency, requestedCurrency)
+func (s *Service) GetTransaction(ctx context.Context, id string) (models.Transaction, error) {
+	transaction, err := s.pg.GetTransaction(ctx, id)
 	if err != nil {
-		return 0, fmt.Errorf("xr.GetRate: %w", err)
+		return models.Transaction{}, fmt.Errorf("pg.GetTransaction: %w", err)
 	}
 
-	convertedBalance := float32(math.Round(float64(currentBalance*rates.Bid*100)) / 100)
+	return transaction, nil
+}
+
+func (s *Service) GetWalletTransactions(ctx context.Context, id string, params models.RequestTransactionsList) (
+	[]models.Transaction, error,
+) {
+	_, err := s.pg.GetWallet(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("pg.GetWallet: %w", err)
+	}
+
+	transactions, err := s.pg.GetWalletTransactions(ctx, id, params)
+	if err != nil {
+		return nil, fmt.Errorf("pg.GetWalletTransactions: %w", err)
+	}
 
-	return convertedBalance, nil
+	return transactions, nil
 }
 
-func (*Service) validateCurrency(verifiedCurrency string) error {
-	currenciesList := []string{eur, rub, usd}
+// ConvertCurrency converts the amount at the given side of the quote, including the house spread.
+// The bid side is used when the customer sells the current currency, the ask side when the customer
+// buys it to pay in the requested currency.
+func (s *Service) ConvertCurrency(ctx context.Context, side, currentCurrency, requestedCurrency string,
+	amount money.Amount,
+) (money.Amount, error) {
+	converted, _, err := s.convert(ctx, side, currentCurrency, requestedCurrency, amount)
+	if err != nil {
+		return money.Amount{}, err
+	}
 
-	for _, v := range currenciesList {
-		if verifiedCurrency == v {
-			return nil
-		}
+	return converted, nil
+}
+
+// convert returns the amount in the requested currency, rounded to its minor units, together with
+// the rate applied, or a nil rate when both currencies are the same.
+func (s *Service) convert(ctx context.Context, side, currentCurrency, requestedCurrency string, amount money.Amount) (
+	money.Amount, *models.AppliedRate, error,
+) {
+	if currentCurrency == requestedCurrency {
+		return amount, nil, nil
+	}
+
+	currency, err := s.pg.GetCurrency(ctx, requestedCurrency)
+	if err != nil {
+		return money.Amount{}, nil, fmt.Errorf("pg.GetCurrency: %w", err)
+	}
+
+	rates, err := s.xr.GetRate(ctx, currentCurrency, requestedCurrency)
+	if err != nil {
+		return money.Amount{}, nil, fmt.Errorf("xr.GetRate: %w", err)
+	}
+
+	applied := models.AppliedRate{Side: side, SpreadBps: s.config.SpreadBps}
+
+	switch side {
+	case models.SideBid:
+		applied.Rate, err = rates.Bid.WithSpread(-s.config.SpreadBps)
+	case models.SideAsk:
+		applied.Rate, err = rates.Ask.WithSpread(s.config.SpreadBps)
+	default:
+		err = money.ErrInvalidRate
+	}
+
+	if err != nil {
+		return money.Amount{}, nil, fmt.Errorf("WithSpread: %w", err)
+	}
+
+	converted, err := amount.Convert(applied.Rate, currency.MinorUnits)
+	if err != nil {
+		return money.Amount{}, nil, fmt.Errorf("Convert: %w", err)
 	}
 
-	return ErrCurrencyNotValid
+	return converted, &applied, nil
 }
 
 func (s *Service) TrackerRun(ctx context.Context) error {
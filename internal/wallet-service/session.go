@@ -0,0 +1,170 @@
+package walletservice
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultRefreshTTL  = 30 * 24 * time.Hour
+	refreshSecretBytes = 32
+)
+
+var ErrRefreshTokenNotValid = errors.New("refresh token is not valid")
+
+// CreateSession opens a session for the caller of info with access as its first access token. The
+// refresh token is returned once; only its hash is stored.
+func (s *Service) CreateSession(ctx context.Context, info models.SessionInfo, access models.AccessToken) (
+	models.Session, string, error,
+) {
+	sessionID := uuid.New()
+
+	refreshToken, err := newRefreshToken(sessionID)
+	if err != nil {
+		return models.Session{}, "", fmt.Errorf("newRefreshToken: %w", err)
+	}
+
+	session, err := s.pg.CreateSession(ctx, models.Session{
+		SessionID:       sessionID,
+		Subject:         info.UUID,
+		Email:           info.Email,
+		Roles:           info.Roles,
+		Permissions:     info.Permissions,
+		AccessTokenID:   access.TokenID,
+		AccessExpiresAt: access.ExpiresAt,
+		ExpiresAt:       time.Now().Add(s.refreshTTL()),
+	}, hashToken(refreshToken))
+	if err != nil {
+		return models.Session{}, "", fmt.Errorf("pg.CreateSession: %w", err)
+	}
+
+	s.log.Infof("session %s opened for %s", session.SessionID, session.Subject)
+
+	return session, refreshToken, nil
+}
+
+// RefreshSession trades refreshToken for a new one and makes access the current access token of the
+// session. Refreshing does not extend the session, so a session ends RefreshTTL after it was opened and
+// the roles it was opened with are checked again by the next one.
+func (s *Service) RefreshSession(ctx context.Context, refreshToken string, access models.AccessToken) (
+	models.Session, string, error,
+) {
+	sessionID, ok := refreshTokenSession(refreshToken)
+	if !ok {
+		return models.Session{}, "", ErrRefreshTokenNotValid
+	}
+
+	newToken, err := newRefreshToken(sessionID)
+	if err != nil {
+		return models.Session{}, "", fmt.Errorf("newRefreshToken: %w", err)
+	}
+
+	session, err := s.pg.RotateSession(ctx, sessionID, hashToken(refreshToken), hashToken(newToken), access)
+	if err != nil {
+		return models.Session{}, "", fmt.Errorf("pg.RotateSession: %w", err)
+	}
+
+	return session, newToken, nil
+}
+
+// RevokeSession ends the session the token of info belongs to and revokes the token itself, which
+// also covers tokens issued outside a session.
+func (s *Service) RevokeSession(ctx context.Context, info models.SessionInfo) error {
+	if info.SessionID != "" {
+		sessionID, err := uuid.Parse(info.SessionID)
+		if err != nil {
+			return fmt.Errorf("uuid.Parse: %w", err)
+		}
+
+		err = s.pg.RevokeSessions(ctx, info.UUID, uuid.NullUUID{UUID: sessionID, Valid: true})
+		if err != nil {
+			return fmt.Errorf("pg.RevokeSessions: %w", err)
+		}
+	}
+
+	return s.revokeToken(ctx, info)
+}
+
+// RevokeAllSessions ends every session of the subject of info.
+func (s *Service) RevokeAllSessions(ctx context.Context, info models.SessionInfo) error {
+	err := s.pg.RevokeSessions(ctx, info.UUID, uuid.NullUUID{})
+	if err != nil {
+		return fmt.Errorf("pg.RevokeSessions: %w", err)
+	}
+
+	s.log.Infof("all sessions of %s revoked", info.UUID)
+
+	return s.revokeToken(ctx, info)
+}
+
+func (s *Service) revokeToken(ctx context.Context, info models.SessionInfo) error {
+	if info.TokenID == "" {
+		return nil
+	}
+
+	err := s.pg.RevokeToken(ctx, info.TokenID, info.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("pg.RevokeToken: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	revoked, err := s.pg.IsTokenRevoked(ctx, tokenID)
+	if err != nil {
+		return false, fmt.Errorf("pg.IsTokenRevoked: %w", err)
+	}
+
+	return revoked, nil
+}
+
+func (s *Service) refreshTTL() time.Duration {
+	if s.config.RefreshTTL <= 0 {
+		return defaultRefreshTTL
+	}
+
+	return s.config.RefreshTTL
+}
+
+// newRefreshToken returns an opaque token naming the session it refreshes, followed by a random secret.
+func newRefreshToken(sessionID uuid.UUID) (string, error) {
+	secret := make([]byte, refreshSecretBytes)
+
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", fmt.Errorf("rand.Read: %w", err)
+	}
+
+	return sessionID.String() + "." + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func refreshTokenSession(refreshToken string) (uuid.UUID, bool) {
+	id, secret, found := strings.Cut(refreshToken, ".")
+	if !found || secret == "" {
+		return uuid.Nil, false
+	}
+
+	sessionID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, false
+	}
+
+	return sessionID, true
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,332 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/AlexZav1327/service/internal/postgres"
+	"github.com/google/uuid"
+)
+
+const (
+	standingOrdersInterval = time.Minute
+	standingOrdersBatch    = 100
+)
+
+var (
+	ErrStandingOrderNotValid = errors.New("standing order is not valid")
+	ErrTransferFailed        = errors.New("the transfer could not be made")
+	executionErrors          = []error{
+		postgres.ErrOverdraft, postgres.ErrSpendingLimitExceeded, postgres.ErrWalletNotFound, ErrWalletFrozen,
+		ErrCurrencyNotValid, ErrSubBalanceNotFound, ErrFeeExceedsAmount, money.ErrPrecision, money.ErrOverflow,
+	}
+)
+
+func (s *Service) CreateStandingOrder(ctx context.Context, id string, request models.RequestStandingOrder) (
+	models.StandingOrder, error,
+) {
+	srcWallet, err := s.pg.GetWallet(ctx, id)
+	if err != nil {
+		return models.StandingOrder{}, fmt.Errorf("pg.GetWallet: %w", err)
+	}
+
+	err = s.validateStandingOrder(ctx, srcWallet.WalletID, request)
+	if err != nil {
+		return models.StandingOrder{}, fmt.Errorf("validateStandingOrder: %w", err)
+	}
+
+	if request.StartAt.IsZero() {
+		request.StartAt = time.Now()
+	}
+
+	order, err := s.pg.CreateStandingOrder(ctx, models.StandingOrder{
+		OrderID:     uuid.New(),
+		SrcWalletID: srcWallet.WalletID,
+		DstWalletID: request.DstWalletID,
+		Amount:      request.Amount,
+		Currency:    request.Currency,
+		Frequency:   request.Frequency,
+		StartAt:     request.StartAt,
+	})
+	if err != nil {
+		return models.StandingOrder{}, fmt.Errorf("pg.CreateStandingOrder: %w", err)
+	}
+
+	return order, nil
+}
+
+func (s *Service) GetStandingOrder(ctx context.Context, id string) (models.StandingOrder, error) {
+	order, err := s.pg.GetStandingOrder(ctx, id)
+	if err != nil {
+		return models.StandingOrder{}, fmt.Errorf("pg.GetStandingOrder: %w", err)
+	}
+
+	return order, nil
+}
+
+func (s *Service) GetWalletStandingOrders(ctx context.Context, id string) ([]models.StandingOrder, error) {
+	_, err := s.pg.GetWallet(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("pg.GetWallet: %w", err)
+	}
+
+	orders, err := s.pg.GetWalletStandingOrders(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("pg.GetWalletStandingOrders: %w", err)
+	}
+
+	return orders, nil
+}
+
+// UpdateStandingOrder changes the terms of an active order. The schedule restarts from StartAt when it
+// is given; otherwise a new frequency takes effect from the next planned run.
+func (s *Service) UpdateStandingOrder(ctx context.Context, id string, request models.RequestStandingOrder) (
+	models.StandingOrder, error,
+) {
+	order, err := s.pg.GetStandingOrder(ctx, id)
+	if err != nil {
+		return models.StandingOrder{}, fmt.Errorf("pg.GetStandingOrder: %w", err)
+	}
+
+	if order.Status != models.StandingOrderActive {
+		return models.StandingOrder{}, postgres.ErrStandingOrderNotActive
+	}
+
+	err = s.validateStandingOrder(ctx, order.SrcWalletID, request)
+	if err != nil {
+		return models.StandingOrder{}, fmt.Errorf("validateStandingOrder: %w", err)
+	}
+
+	switch {
+	case !request.StartAt.IsZero():
+		order.StartAt, order.NextRunAt, order.Runs = request.StartAt, request.StartAt, 0
+	case request.Frequency != order.Frequency:
+		order.StartAt, order.Runs = order.NextRunAt, 0
+	}
+
+	order.DstWalletID = request.DstWalletID
+	order.Amount = request.Amount
+	order.Currency = request.Currency
+	order.Frequency = request.Frequency
+
+	updatedOrder, err := s.pg.UpdateStandingOrder(ctx, order)
+	if err != nil {
+		return models.StandingOrder{}, fmt.Errorf("pg.UpdateStandingOrder: %w", err)
+	}
+
+	return updatedOrder, nil
+}
+
+func (s *Service) CancelStandingOrder(ctx context.Context, id string) error {
+	_, err := s.pg.GetStandingOrder(ctx, id)
+	if err != nil {
+		return fmt.Errorf("pg.GetStandingOrder: %w", err)
+	}
+
+	err = s.pg.CancelStandingOrder(ctx, id)
+	if err != nil {
+		return fmt.Errorf("pg.CancelStandingOrder: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) GetStandingOrderExecutions(ctx context.Context, id string) (
+	[]models.StandingOrderExecution, error,
+) {
+	_, err := s.pg.GetStandingOrder(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("pg.GetStandingOrder: %w", err)
+	}
+
+	executions, err := s.pg.GetStandingOrderExecutions(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("pg.GetStandingOrderExecutions: %w", err)
+	}
+
+	return executions, nil
+}
+
+// ExecuteDueStandingOrders runs every active order whose next run has come and returns how many ran.
+// An order behind its schedule catches up one run at a time.
+func (s *Service) ExecuteDueStandingOrders(ctx context.Context) (int, error) {
+	orders, err := s.pg.GetDueStandingOrders(ctx, time.Now(), standingOrdersBatch)
+	if err != nil {
+		return 0, fmt.Errorf("pg.GetDueStandingOrders: %w", err)
+	}
+
+	executed := 0
+
+	for _, order := range orders {
+		err = s.executeStandingOrder(ctx, order)
+		if err != nil {
+			s.log.Warningf("executeStandingOrder: %s", err)
+
+			continue
+		}
+
+		executed++
+	}
+
+	return executed, nil
+}
+
+func (s *Service) StandingOrdersRun(ctx context.Context) error {
+	standingOrdersTicker := time.NewTicker(standingOrdersInterval)
+	defer standingOrdersTicker.Stop()
+
+	for {
+		select {
+		case <-standingOrdersTicker.C:
+			executed, err := s.ExecuteDueStandingOrders(ctx)
+			if err != nil {
+				s.log.Warningf("ExecuteDueStandingOrders: %s", err)
+
+				continue
+			}
+
+			if executed > 0 {
+				s.log.Infof("%d standing orders executed", executed)
+			}
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// executeStandingOrder makes the scheduled transfer and records its result. The transaction key is
+// derived from the order and the run, so a run retried after its result was lost replays the transfer
+// instead of making it twice. A run that fails for a transient reason is not recorded and stays due, so
+// it is retried on the next tick rather than skipped.
+func (s *Service) executeStandingOrder(ctx context.Context, order models.StandingOrder) error {
+	execution := models.StandingOrderExecution{
+		OrderID:        order.OrderID,
+		ScheduledAt:    order.NextRunAt,
+		TransactionKey: uuid.NewSHA1(order.OrderID, []byte(order.NextRunAt.UTC().Format(time.RFC3339Nano))),
+		Status:         models.ExecutionSucceeded,
+	}
+
+	started := time.Now()
+
+	transfer, err := s.TransferFunds(ctx, order.SrcWalletID.String(), order.DstWalletID.String(),
+		models.FundsOperations{
+			TransactionKey: execution.TransactionKey,
+			Currency:       order.Currency,
+			Amount:         order.Amount,
+		})
+	if err != nil {
+		if ctx.Err() != nil || postgres.IsTransient(err) {
+			return fmt.Errorf("TransferFunds: %w", err)
+		}
+
+		execution.Status = models.ExecutionFailed
+		execution.Error = executionError(err).Error()
+	} else {
+		execution.TransactionID = uuid.NullUUID{UUID: transfer.TransactionID, Valid: true}
+	}
+
+	s.metrics.duration.WithLabelValues("execute_standing_order").Observe(time.Since(started).Seconds())
+
+	nextRunAt, status := order.NextRunAt, models.StandingOrderCompleted
+	if order.Frequency != models.FrequencyOnce {
+		nextRunAt, status = scheduledRun(order, order.Runs+1), models.StandingOrderActive
+	}
+
+	err = s.pg.RecordStandingOrderExecution(ctx, execution, nextRunAt, status)
+	if err != nil {
+		return fmt.Errorf("pg.RecordStandingOrderExecution: %w", err)
+	}
+
+	if execution.Status == models.ExecutionFailed {
+		s.notifyStandingOrderFailure(ctx, order, execution)
+	}
+
+	return nil
+}
+
+func (s *Service) notifyStandingOrderFailure(ctx context.Context, order models.StandingOrder,
+	execution models.StandingOrderExecution,
+) {
+	wallet, err := s.pg.GetWallet(ctx, order.SrcWalletID.String())
+	if err != nil {
+		s.log.Warningf("pg.GetWallet: %s", err)
+
+		return
+	}
+
+	message, err := s.message.CreateStandingOrderMessage(wallet, order, execution)
+	if err != nil {
+		s.log.Warningf("message.CreateStandingOrderMessage: %s", err)
+
+		return
+	}
+
+	err = s.notification.Notify(ctx, message)
+	if err != nil {
+		s.log.Warningf("notification.Notify: %s", err)
+	}
+}
+
+// validateStandingOrder checks the terms of an order. A start given in the past is refused; an order
+// without a start runs right away.
+func (s *Service) validateStandingOrder(ctx context.Context, srcID uuid.UUID,
+	request models.RequestStandingOrder,
+) error {
+	switch request.Frequency {
+	case models.FrequencyOnce, models.FrequencyWeekly, models.FrequencyMonthly:
+	default:
+		return ErrStandingOrderNotValid
+	}
+
+	if request.Amount.Sign() <= 0 || request.DstWalletID == srcID ||
+		(!request.StartAt.IsZero() && request.StartAt.Before(time.Now())) {
+		return ErrStandingOrderNotValid
+	}
+
+	err := s.validateFunds(ctx, models.FundsOperations{Currency: request.Currency, Amount: request.Amount})
+	if err != nil {
+		return fmt.Errorf("validateFunds: %w", err)
+	}
+
+	_, err = s.pg.GetWallet(ctx, request.DstWalletID.String())
+	if err != nil {
+		return fmt.Errorf("pg.GetWallet: %w", err)
+	}
+
+	return nil
+}
+
+// scheduledRun returns when the order is due for the n-th time, counting from zero. Monthly runs keep
+// the day of StartAt, or fall on the last day of shorter months.
+func scheduledRun(order models.StandingOrder, n int) time.Time {
+	start := order.StartAt
+
+	switch order.Frequency {
+	case models.FrequencyWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case models.FrequencyMonthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(n), 1, start.Hour(), start.Minute(),
+			start.Second(), start.Nanosecond(), start.Location())
+
+		return first.AddDate(0, 0, min(start.Day(), first.AddDate(0, 1, -1).Day())-1)
+	default:
+		return start
+	}
+}
+
+// executionError returns the error recorded for a failed run and sent to the customer: the domain error
+// that failed the transfer, or ErrTransferFailed for anything else, so no internal detail leaks out.
+func executionError(err error) error {
+	for _, executionErr := range executionErrors {
+		if errors.Is(err, executionErr) {
+			return executionErr
+		}
+	}
+
+	return ErrTransferFailed
+}
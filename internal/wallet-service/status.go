@@ -0,0 +1,171 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/postgres"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrWalletFrozen           = errors.New("wallet is frozen")
+	ErrWalletStatusNotValid   = errors.New("wallet status or reason is not valid")
+	ErrStatusChangeNotAllowed = errors.New("wallet status change is not allowed")
+	ErrSweepTargetNotValid    = errors.New("sweep target wallet is not valid")
+	statusTransitions         = map[string][]string{
+		models.WalletActive: {models.WalletFrozen, models.WalletClosed},
+		models.WalletFrozen: {models.WalletActive},
+	}
+)
+
+// ChangeWalletStatus moves the wallet along active → frozen → active or active → closed. Every change
+// needs a reason, which is kept in the wallet history.
+func (s *Service) ChangeWalletStatus(ctx context.Context, id string, change models.RequestWalletStatus) (
+	models.ResponseWalletInstance, error,
+) {
+	switch change.Status {
+	case models.WalletActive, models.WalletFrozen, models.WalletClosed:
+	default:
+		return models.ResponseWalletInstance{}, ErrWalletStatusNotValid
+	}
+
+	if strings.TrimSpace(change.Reason) == "" {
+		return models.ResponseWalletInstance{}, ErrWalletStatusNotValid
+	}
+
+	currentWallet, err := s.pg.GetWallet(ctx, id)
+	if err != nil {
+		return models.ResponseWalletInstance{}, fmt.Errorf("pg.GetWallet: %w", err)
+	}
+
+	if !statusChangeAllowed(currentWallet.Status, change.Status) {
+		return models.ResponseWalletInstance{}, fmt.Errorf("%w: %s to %s", ErrStatusChangeNotAllowed,
+			currentWallet.Status, change.Status)
+	}
+
+	if change.Status == models.WalletClosed {
+		wallet, err := s.closeWallet(ctx, currentWallet, change.Reason, change.SweepTo)
+		if err != nil {
+			return models.ResponseWalletInstance{}, fmt.Errorf("closeWallet: %w", err)
+		}
+
+		return wallet, nil
+	}
+
+	started := time.Now()
+	defer func() {
+		s.metrics.duration.WithLabelValues("change_wallet_status").Observe(time.Since(started).Seconds())
+	}()
+
+	wallet, err := s.pg.SetWalletStatus(ctx, id, currentWallet.Status, change.Status, change.Reason)
+	if err != nil {
+		return models.ResponseWalletInstance{}, fmt.Errorf("pg.SetWalletStatus: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// closeWallet closes a wallet whose balances are zero, or first sweeps every non-zero balance to the
+// sweepTo wallet as a transfer, converted when that wallet cannot hold the currency.
+func (s *Service) closeWallet(ctx context.Context, wallet models.ResponseWalletInstance, reason string,
+	sweepTo uuid.NullUUID,
+) (models.ResponseWalletInstance, error) {
+	if !statusChangeAllowed(wallet.Status, models.WalletClosed) {
+		return models.ResponseWalletInstance{}, fmt.Errorf("%w: %s to %s", ErrStatusChangeNotAllowed, wallet.Status,
+			models.WalletClosed)
+	}
+
+	var sweeps []models.LedgerTransaction
+
+	if sweepTo.Valid {
+		var err error
+
+		sweeps, err = s.sweepTransfers(ctx, wallet, sweepTo.UUID)
+		if err != nil {
+			return models.ResponseWalletInstance{}, fmt.Errorf("sweepTransfers: %w", err)
+		}
+	}
+
+	started := time.Now()
+	defer func() {
+		s.metrics.duration.WithLabelValues("close_wallet").Observe(time.Since(started).Seconds())
+	}()
+
+	closedWallet, err := s.pg.CloseWallet(ctx, wallet.WalletID.String(), wallet.Status, reason, sweeps)
+	if err != nil {
+		return models.ResponseWalletInstance{}, fmt.Errorf("pg.CloseWallet: %w", err)
+	}
+
+	s.metrics.deletedWallets.Inc()
+
+	return closedWallet, nil
+}
+
+func (s *Service) sweepTransfers(ctx context.Context, wallet models.ResponseWalletInstance, targetID uuid.UUID) (
+	[]models.LedgerTransaction, error,
+) {
+	if targetID == wallet.WalletID {
+		return nil, ErrSweepTargetNotValid
+	}
+
+	target, err := s.pg.GetWallet(ctx, targetID.String())
+	if errors.Is(err, postgres.ErrWalletNotFound) {
+		return nil, ErrSweepTargetNotValid
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("pg.GetWallet: %w", err)
+	}
+
+	balances := append([]models.SubBalance{{Currency: wallet.Currency, Balance: wallet.Balance}},
+		wallet.SubBalances...)
+	sweeps := make([]models.LedgerTransaction, 0, len(balances))
+
+	for _, balance := range balances {
+		if balance.Balance.Sign() <= 0 {
+			continue
+		}
+
+		creditCurrency := creditedSubBalance(target, balance.Currency)
+
+		depositAmount, rate, err := s.convert(ctx, models.SideBid, balance.Currency, creditCurrency, balance.Balance)
+		if err != nil {
+			return nil, fmt.Errorf("convert: %w", err)
+		}
+
+		txn := newLedgerTransaction(operationTransfer, uuid.NullUUID{}, balance.Balance, balance.Currency)
+		txn.Source = transactionLeg(wallet.WalletID, balance.Balance, balance.Currency, nil)
+		txn.Destination = transactionLeg(target.WalletID, depositAmount, creditCurrency, rate)
+		addExchange(&txn, walletAccount(wallet.WalletID), balance.Balance, balance.Currency,
+			walletAccount(target.WalletID), depositAmount, creditCurrency)
+
+		sweeps = append(sweeps, txn)
+	}
+
+	return sweeps, nil
+}
+
+func statusChangeAllowed(from, to string) bool {
+	for _, status := range statusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkCanSend rejects debits from a wallet that compliance has frozen. Frozen wallets can still be
+// read and receive funds.
+func checkCanSend(wallet models.ResponseWalletInstance) error {
+	if wallet.Status == models.WalletFrozen {
+		return ErrWalletFrozen
+	}
+
+	return nil
+}
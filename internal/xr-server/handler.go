@@ -23,7 +23,7 @@ func (h *Handler) get(w http.ResponseWriter, r *http.Request) {
 	from := r.URL.Query().Get("from")
 	to := r.URL.Query().Get("to")
 
-	currentRate, err := h.service.GetCurrentRate(from, to)
+	currentRate, err := h.service.GetCurrentRate(r.Context(), from, to)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 
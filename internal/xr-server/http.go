@@ -21,7 +21,7 @@ type Server struct {
 }
 
 type RateService interface {
-	GetCurrentRate(to, from string) (models.ExchangeRate, error)
+	GetCurrentRate(ctx context.Context, from, to string) (models.ExchangeRate, error)
 }
 
 func New(host string, port int, service RateService, log *logrus.Logger) *Server {
@@ -1,71 +1,74 @@
 package xrservice
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
 	"github.com/sirupsen/logrus"
 )
 
 const (
-	eur                 = "EUR"
-	rub                 = "RUB"
-	usd                 = "USD"
-	ask                 = "ask"
-	bid                 = "bid"
-	eurToRubBid float32 = 99.35
-	eurToRubAsk float32 = 105.5
-	eurToUsdBid float32 = 1.07
-	eurToUsdAsk float32 = 1.13
-	rubToEurBid float32 = 0.0095
-	rubToEurAsk float32 = 0.0101
-	rubToUsdBid float32 = 0.0102
-	rubToUsdAsk float32 = 0.0108
-	usdToEurBid float32 = 0.92
-	usdToEurAsk float32 = 0.97
-	usdToRubBid float32 = 92.22
-	usdToRubAsk float32 = 98.19
+	bidFactor = "0.97"
+	askFactor = "1.03"
 )
 
 var ErrWrongCurrency = errors.New("currency is not valid")
 
 type Rate struct {
-	log *logrus.Entry
+	store currencyStore
+	log   *logrus.Entry
 }
 
-func New(log *logrus.Logger) *Rate {
+type currencyStore interface {
+	GetCurrency(ctx context.Context, code string) (models.Currency, error)
+}
+
+func New(store currencyStore, log *logrus.Logger) *Rate {
 	return &Rate{
-		log: log.WithField("module", "xr_service"),
+		store: store,
+		log:   log.WithField("module", "xr_service"),
 	}
 }
 
-func (r *Rate) GetCurrentRate(from, to string) (models.ExchangeRate, error) {
-	var currentRate models.ExchangeRate
+// GetCurrentRate quotes a cross rate derived from the reference rates of the currency registry,
+// with a fixed spread on both sides.
+func (r *Rate) GetCurrentRate(ctx context.Context, from, to string) (models.ExchangeRate, error) {
+	if from == to {
+		return models.ExchangeRate{}, ErrWrongCurrency
+	}
 
-	var currencyRate map[string]float32
+	fromCurrency, err := r.store.GetCurrency(ctx, from)
+	if err != nil {
+		return models.ExchangeRate{}, fmt.Errorf("%w: %w", ErrWrongCurrency, err)
+	}
 
-	var ok bool
+	toCurrency, err := r.store.GetCurrency(ctx, to)
+	if err != nil {
+		return models.ExchangeRate{}, fmt.Errorf("%w: %w", ErrWrongCurrency, err)
+	}
 
-	currentRate.Timestamp = time.Now()
+	mid, err := toCurrency.ReferenceRate.Quo(fromCurrency.ReferenceRate)
+	if err != nil {
+		return models.ExchangeRate{}, fmt.Errorf("ReferenceRate.Quo: %w", err)
+	}
+
+	var currentRate models.ExchangeRate
 
-	rates := map[string]map[string]map[string]float32{
-		eur: {rub: {bid: eurToRubBid, ask: eurToRubAsk}, usd: {bid: eurToUsdBid, ask: eurToUsdAsk}},
-		rub: {eur: {bid: rubToEurBid, ask: rubToEurAsk}, usd: {bid: rubToUsdBid, ask: rubToUsdAsk}},
-		usd: {eur: {bid: usdToEurBid, ask: usdToEurAsk}, rub: {bid: usdToRubBid, ask: usdToRubAsk}},
+	currentRate.Bid, err = mid.Mul(money.MustParseRate(bidFactor))
+	if err != nil {
+		return models.ExchangeRate{}, fmt.Errorf("mid.Mul: %w", err)
 	}
 
-	if rates[from] != nil {
-		currencyRate, ok = rates[from][to]
-		if !ok {
-			return models.ExchangeRate{}, ErrWrongCurrency
-		}
-	} else {
-		return models.ExchangeRate{}, ErrWrongCurrency
+	currentRate.Ask, err = mid.Mul(money.MustParseRate(askFactor))
+	if err != nil {
+		return models.ExchangeRate{}, fmt.Errorf("mid.Mul: %w", err)
 	}
 
-	currentRate.Bid = currencyRate[bid]
-	currentRate.Ask = currencyRate[ask]
+	currentRate.Timestamp = time.Now()
 	currentRate.Currencies = from + to
 
 	return currentRate, nil
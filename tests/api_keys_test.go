@@ -0,0 +1,205 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+func (s *IntegrationTestSuite) TestAPIKeys() {
+	newWallet := func(ctx context.Context, amount string) models.ResponseWalletInstance {
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = uuid.New().String()
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = "USD"
+
+		var wallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqWallet, &wallet)
+
+		if amount == "" {
+			return wallet
+		}
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "USD"
+		reqDeposit.Amount = money.MustParse(amount)
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+deposit, reqDeposit,
+			&wallet)
+
+		return wallet
+	}
+
+	createKey := func(ctx context.Context, request models.RequestAPIKey) models.ResponseAPIKey {
+		var apiKey models.ResponseAPIKey
+
+		resp := s.sendAdminRequest(ctx, http.MethodPost, url+apiKeysEndpoint, request, &apiKey)
+
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+		s.Require().NotEmpty(apiKey.Key)
+
+		return apiKey
+	}
+
+	funds := func(amount string) models.FundsOperations {
+		return models.FundsOperations{TransactionKey: uuid.New(), Currency: "USD", Amount: money.MustParse(amount)}
+	}
+
+	s.Run("key is limited to its scopes and wallets", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx, "100")
+		other := newWallet(ctx, "100")
+
+		apiKey := createKey(ctx, models.RequestAPIKey{
+			Name:      "billing",
+			Scopes:    []string{models.ScopeRead, models.ScopeDeposit},
+			WalletIDs: []uuid.UUID{wallet.WalletID},
+		})
+
+		endpoint := url + walletEndpoint + wallet.WalletID.String()
+
+		resp := s.sendRequestWithAPIKey(ctx, http.MethodGet, endpoint, apiKey.Key, nil, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		var respWallet models.ResponseWalletInstance
+
+		resp = s.sendRequestWithAPIKey(ctx, http.MethodPut, endpoint+deposit, apiKey.Key, funds("20"), &respWallet)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(money.MustParse("120"), respWallet.Balance)
+
+		resp = s.sendRequestWithAPIKey(ctx, http.MethodPut, endpoint+withdraw, apiKey.Key, funds("20"), nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+
+		resp = s.sendRequestWithAPIKey(ctx, http.MethodPut, endpoint+transfer+other.WalletID.String(), apiKey.Key,
+			funds("20"), nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+
+		resp = s.sendRequestWithAPIKey(ctx, http.MethodGet, url+walletEndpoint+other.WalletID.String(), apiKey.Key,
+			nil, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+
+		resp = s.sendRequestWithAPIKey(ctx, http.MethodGet, url+adminWalletsEndpoint, apiKey.Key, nil, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+
+		resp = s.sendRequestWithAPIKey(ctx, http.MethodGet, url+walletsEndpoint, apiKey.Key, nil, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+	})
+
+	s.Run("transfer key without wallets", func() {
+		ctx := context.Background()
+
+		src := newWallet(ctx, "100")
+		dst := newWallet(ctx, "")
+
+		apiKey := createKey(ctx, models.RequestAPIKey{Name: "payouts", Scopes: []string{models.ScopeTransfer}})
+
+		resp := s.sendRequestWithAPIKey(ctx, http.MethodPut,
+			url+walletEndpoint+src.WalletID.String()+transfer+dst.WalletID.String(), apiKey.Key, funds("30"), nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		resp = s.sendRequestWithAPIKey(ctx, http.MethodGet, url+walletEndpoint+src.WalletID.String(), apiKey.Key,
+			nil, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+
+		var respKey models.APIKey
+
+		resp = s.sendAdminRequest(ctx, http.MethodGet, url+apiKeysEndpoint+apiKey.KeyID.String(), nil, &respKey)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().NotNil(respKey.LastUsedAt)
+		s.Require().Empty(respKey.WalletIDs)
+
+		var respKeys []models.APIKey
+
+		resp = s.sendAdminRequest(ctx, http.MethodGet, url+apiKeysEndpoint, nil, &respKeys)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Contains(respKeys, respKey)
+	})
+
+	s.Run("read key without wallets cannot use admin routes", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx, "")
+
+		apiKey := createKey(ctx, models.RequestAPIKey{Name: "reports", Scopes: []string{models.ScopeRead}})
+
+		resp := s.sendRequestWithAPIKey(ctx, http.MethodGet, url+walletEndpoint+wallet.WalletID.String(), apiKey.Key,
+			nil, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		for _, endpoint := range []string{
+			adminWalletsEndpoint,
+			adminWalletsEndpoint + wallet.WalletID.String() + limits,
+			currenciesEndpoint,
+		} {
+			resp = s.sendRequestWithAPIKey(ctx, http.MethodGet, url+endpoint, apiKey.Key, nil, nil)
+			s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+		}
+	})
+
+	s.Run("revoked, expired and unknown keys are rejected", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx, "100")
+		endpoint := url + walletEndpoint + wallet.WalletID.String()
+
+		apiKey := createKey(ctx, models.RequestAPIKey{Name: "reports", Scopes: []string{models.ScopeRead}})
+
+		resp := s.sendAdminRequest(ctx, http.MethodDelete, url+apiKeysEndpoint+apiKey.KeyID.String(), nil, nil)
+		s.Require().Equal(http.StatusNoContent, resp.StatusCode)
+
+		resp = s.sendAdminRequest(ctx, http.MethodDelete, url+apiKeysEndpoint+apiKey.KeyID.String(), nil, nil)
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+
+		resp = s.sendRequestWithAPIKey(ctx, http.MethodGet, endpoint, apiKey.Key, nil, nil)
+		s.Require().Equal(http.StatusUnauthorized, resp.StatusCode)
+
+		expiresAt := time.Now().Add(time.Second)
+
+		apiKey = createKey(ctx, models.RequestAPIKey{
+			Name:      "reports",
+			Scopes:    []string{models.ScopeRead},
+			ExpiresAt: &expiresAt,
+		})
+
+		resp = s.sendRequestWithAPIKey(ctx, http.MethodGet, endpoint, apiKey.Key, nil, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		s.Require().Eventually(func() bool {
+			resp = s.sendRequestWithAPIKey(ctx, http.MethodGet, endpoint, apiKey.Key, nil, nil)
+
+			return resp.StatusCode == http.StatusUnauthorized
+		}, 3*time.Second, 100*time.Millisecond)
+
+		resp = s.sendRequestWithAPIKey(ctx, http.MethodGet, endpoint, "wk_unknown", nil, nil)
+		s.Require().Equal(http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	s.Run("create key not valid", func() {
+		ctx := context.Background()
+
+		resp := s.sendAdminRequest(ctx, http.MethodPost, url+apiKeysEndpoint,
+			models.RequestAPIKey{Name: "billing", Scopes: []string{"withdraw"}}, nil)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		resp = s.sendAdminRequest(ctx, http.MethodPost, url+apiKeysEndpoint, models.RequestAPIKey{
+			Name:      "billing",
+			Scopes:    []string{models.ScopeRead},
+			WalletIDs: []uuid.UUID{uuid.New()},
+		}, nil)
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodPost, url+apiKeysEndpoint,
+			models.RequestAPIKey{Name: "billing", Scopes: []string{models.ScopeRead}}, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+	})
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+)
+
+func (s *IntegrationTestSuite) TestAuth() {
+	issueTokens := func(ctx context.Context) models.ResponseTokens {
+		var tokens models.ResponseTokens
+
+		resp := s.send(ctx, http.MethodPost, url+authEndpoint+"token", s.identityToken(), nil, &tokens)
+
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+		s.Require().NotEmpty(tokens.AccessToken)
+		s.Require().NotEmpty(tokens.RefreshToken)
+
+		return tokens
+	}
+
+	refreshTokens := func(ctx context.Context, refreshToken string, dest interface{}) *http.Response {
+		return s.send(ctx, http.MethodPost, url+authEndpoint+"refresh", "",
+			models.RequestRefresh{RefreshToken: refreshToken}, dest)
+	}
+
+	listWallets := func(ctx context.Context, accessToken string) int {
+		return s.send(ctx, http.MethodGet, url+walletsEndpoint, accessToken, nil, nil).StatusCode
+	}
+
+	s.Run("refresh rotates tokens", func() {
+		ctx := context.Background()
+
+		tokens := issueTokens(ctx)
+		s.Require().Equal(http.StatusOK, listWallets(ctx, tokens.AccessToken))
+
+		var refreshed models.ResponseTokens
+
+		resp := refreshTokens(ctx, tokens.RefreshToken, &refreshed)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().NotEqual(tokens.RefreshToken, refreshed.RefreshToken)
+		s.Require().True(tokens.RefreshExpiresAt.Equal(refreshed.RefreshExpiresAt))
+		s.Require().Equal(http.StatusOK, listWallets(ctx, refreshed.AccessToken))
+		s.Require().Equal(http.StatusUnauthorized, listWallets(ctx, tokens.AccessToken))
+	})
+
+	s.Run("reused refresh token revokes the session", func() {
+		ctx := context.Background()
+
+		tokens := issueTokens(ctx)
+
+		var refreshed models.ResponseTokens
+
+		_ = refreshTokens(ctx, tokens.RefreshToken, &refreshed)
+
+		resp := refreshTokens(ctx, tokens.RefreshToken, nil)
+		s.Require().Equal(http.StatusUnauthorized, resp.StatusCode)
+
+		resp = refreshTokens(ctx, refreshed.RefreshToken, nil)
+		s.Require().Equal(http.StatusUnauthorized, resp.StatusCode)
+		s.Require().Equal(http.StatusUnauthorized, listWallets(ctx, refreshed.AccessToken))
+	})
+
+	s.Run("logout revokes one session", func() {
+		ctx := context.Background()
+
+		tokens := issueTokens(ctx)
+		other := issueTokens(ctx)
+
+		resp := s.send(ctx, http.MethodPost, url+authEndpoint+"logout", tokens.AccessToken, nil, nil)
+		s.Require().Equal(http.StatusNoContent, resp.StatusCode)
+
+		s.Require().Equal(http.StatusUnauthorized, listWallets(ctx, tokens.AccessToken))
+
+		resp = refreshTokens(ctx, tokens.RefreshToken, nil)
+		s.Require().Equal(http.StatusUnauthorized, resp.StatusCode)
+
+		s.Require().Equal(http.StatusOK, listWallets(ctx, other.AccessToken))
+	})
+
+	s.Run("logout all revokes every session of the subject", func() {
+		ctx := context.Background()
+
+		first := issueTokens(ctx)
+		second := issueTokens(ctx)
+
+		resp := s.sendRequest(ctx, http.MethodPost, url+authEndpoint+"logout-all", nil, nil)
+		s.Require().Equal(http.StatusNoContent, resp.StatusCode)
+
+		for _, tokens := range []models.ResponseTokens{first, second} {
+			s.Require().Equal(http.StatusUnauthorized, listWallets(ctx, tokens.AccessToken))
+
+			resp = refreshTokens(ctx, tokens.RefreshToken, nil)
+			s.Require().Equal(http.StatusUnauthorized, resp.StatusCode)
+		}
+	})
+
+	s.Run("only identity provider tokens open sessions", func() {
+		ctx := context.Background()
+
+		tokens := issueTokens(ctx)
+
+		resp := s.send(ctx, http.MethodPost, url+authEndpoint+"token", tokens.AccessToken, nil, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodPost, url+authEndpoint+"token", nil, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+	})
+
+	s.Run("refresh token not valid", func() {
+		ctx := context.Background()
+
+		resp := refreshTokens(ctx, "not-a-refresh-token", nil)
+		s.Require().Equal(http.StatusUnauthorized, resp.StatusCode)
+	})
+}
@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+func (s *IntegrationTestSuite) TestSubBalances() {
+	newWallet := func(ctx context.Context, currency string) models.ResponseWalletInstance {
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = uuid.New().String()
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = currency
+
+		var wallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqWallet, &wallet)
+
+		return wallet
+	}
+
+	depositFunds := func(ctx context.Context, walletID uuid.UUID, currency, amount string) models.ResponseFundsOperation {
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = currency
+		reqDeposit.Amount = money.MustParse(amount)
+
+		var respDeposit models.ResponseFundsOperation
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletID.String()+deposit, reqDeposit, &respDeposit)
+
+		return respDeposit
+	}
+
+	s.Run("deposit lands in the open sub-balance", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx, "USD")
+
+		var respWallet models.ResponseWalletInstance
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+subBalances+"EUR", nil,
+			&respWallet)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal([]models.SubBalance{{Currency: "EUR", Balance: money.Amount{}}}, respWallet.SubBalances)
+
+		respDeposit := depositFunds(ctx, wallet.WalletID, "EUR", "50")
+
+		s.Require().True(respDeposit.Balance.IsZero())
+		s.Require().Equal([]models.SubBalance{{Currency: "EUR", Balance: money.MustParse("50")}},
+			respDeposit.SubBalances)
+
+		var respTransaction models.Transaction
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+transactionsEndpoint+respDeposit.TransactionID.String(), nil,
+			&respTransaction)
+
+		s.Require().Equal(money.MustParse("50"), respTransaction.Destination.Amount)
+		s.Require().Equal("EUR", respTransaction.Destination.Currency)
+		s.Require().Nil(respTransaction.Destination.AppliedRate)
+	})
+
+	s.Run("exchange between sub-balances", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx, "USD")
+
+		_ = depositFunds(ctx, wallet.WalletID, "USD", "100")
+
+		reqExchange := models.RequestExchange{}
+		reqExchange.TransactionKey = uuid.New()
+		reqExchange.FromCurrency = "USD"
+		reqExchange.ToCurrency = "EUR"
+		reqExchange.Amount = money.MustParse("40")
+
+		var respExchange models.ResponseFundsOperation
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+exchange, reqExchange,
+			&respExchange)
+
+		convertedFunds, _ := s.walletService.ConvertCurrency(ctx, models.SideBid, "USD", "EUR", reqExchange.Amount)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(money.MustParse("60"), respExchange.Balance)
+		s.Require().Equal([]models.SubBalance{{Currency: "EUR", Balance: convertedFunds}}, respExchange.SubBalances)
+
+		ledgerBalance, err := s.pg.GetLedgerBalance(ctx, wallet.WalletID.String())
+		s.Require().NoError(err)
+		s.Require().Equal(respExchange.Balance, ledgerBalance)
+
+		reqExchange.TransactionKey = uuid.New()
+		reqExchange.FromCurrency = "EUR"
+		reqExchange.ToCurrency = "USD"
+		reqExchange.Amount, _ = convertedFunds.Add(money.MustParse("1"))
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+exchange, reqExchange,
+			nil)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+	})
+
+	s.Run("transfer from the chosen sub-balance", func() {
+		ctx := context.Background()
+
+		srcWallet := newWallet(ctx, "USD")
+		dstWallet := newWallet(ctx, "EUR")
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+srcWallet.WalletID.String()+subBalances+"EUR", nil,
+			nil)
+		_ = depositFunds(ctx, srcWallet.WalletID, "EUR", "30")
+
+		reqTransfer := models.FundsOperations{}
+		reqTransfer.TransactionKey = uuid.New()
+		reqTransfer.Currency = "EUR"
+		reqTransfer.Amount = money.MustParse("20")
+		reqTransfer.FromCurrency = "EUR"
+
+		var respTransfer models.ResponseTransfer
+
+		resp := s.sendRequest(ctx, http.MethodPut,
+			url+walletEndpoint+srcWallet.WalletID.String()+transfer+dstWallet.WalletID.String(), reqTransfer,
+			&respTransfer)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(money.MustParse("20"), respTransfer.DebitedAmount)
+		s.Require().Nil(respTransfer.SrcRate)
+		s.Require().True(respTransfer.SrcWallet.Balance.IsZero())
+		s.Require().Equal([]models.SubBalance{{Currency: "EUR", Balance: money.MustParse("10")}},
+			respTransfer.SrcWallet.SubBalances)
+		s.Require().Equal(money.MustParse("20"), respTransfer.DstWallet.Balance)
+	})
+
+	s.Run("sub-balance not open", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx, "USD")
+
+		reqWithdraw := models.FundsOperations{}
+		reqWithdraw.TransactionKey = uuid.New()
+		reqWithdraw.Currency = "EUR"
+		reqWithdraw.Amount = money.MustParse("1")
+		reqWithdraw.FromCurrency = "EUR"
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+withdraw, reqWithdraw,
+			nil)
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+subBalances+"USD", nil,
+			nil)
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+	})
+}
@@ -0,0 +1,210 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+func (s *IntegrationTestSuite) TestBatchTransfer() {
+	newWallet := func(ctx context.Context, amount string) models.ResponseWalletInstance {
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = uuid.New().String()
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = "USD"
+
+		var wallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqWallet, &wallet)
+
+		if amount == "" {
+			return wallet
+		}
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "USD"
+		reqDeposit.Amount = money.MustParse(amount)
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+deposit, reqDeposit,
+			&wallet)
+
+		return wallet
+	}
+
+	item := func(dstID uuid.UUID, amount string) models.BatchTransferItem {
+		return models.BatchTransferItem{DstWalletID: dstID, Amount: money.MustParse(amount), Currency: "USD"}
+	}
+
+	balance := func(ctx context.Context, id uuid.UUID) money.Amount {
+		var wallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+id.String(), nil, &wallet)
+
+		return wallet.Balance
+	}
+
+	s.Run("batch transfer completed", func() {
+		ctx := context.Background()
+
+		src := newWallet(ctx, "100")
+		dst1 := newWallet(ctx, "")
+		dst2 := newWallet(ctx, "")
+
+		reqBatch := models.RequestBatchTransfer{
+			TransactionKey: uuid.New(),
+			Items:          []models.BatchTransferItem{item(dst1.WalletID, "30"), item(dst2.WalletID, "45")},
+		}
+
+		var respBatch models.ResponseBatchTransfer
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+src.WalletID.String()+batchTransfer, reqBatch,
+			&respBatch)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(models.BatchCompleted, respBatch.Status)
+		s.Require().Equal(money.MustParse("75"), respBatch.Total)
+		s.Require().Equal(money.MustParse("25"), respBatch.SrcWallet.Balance)
+		s.Require().Len(respBatch.Items, 2)
+
+		for _, result := range respBatch.Items {
+			s.Require().Equal(models.BatchItemSucceeded, result.Status)
+			s.Require().True(result.TransactionID.Valid)
+		}
+
+		s.Require().Equal(money.MustParse("30"), balance(ctx, dst1.WalletID))
+		s.Require().Equal(money.MustParse("45"), balance(ctx, dst2.WalletID))
+
+		var respReplay models.ResponseBatchTransfer
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+src.WalletID.String()+batchTransfer, reqBatch,
+			&respReplay)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(respBatch.Items[0].TransactionID, respReplay.Items[0].TransactionID)
+		s.Require().Equal(money.MustParse("25"), balance(ctx, src.WalletID))
+
+		var respTransaction models.Transaction
+
+		_ = s.sendRequest(ctx, http.MethodGet,
+			url+transactionsEndpoint+respBatch.Items[1].TransactionID.UUID.String(), nil, &respTransaction)
+
+		s.Require().Equal("TRANSFER", respTransaction.OperationType)
+		s.Require().Equal(reqBatch.TransactionKey, respTransaction.TransactionKey.UUID)
+	})
+
+	s.Run("batch transfer all or nothing", func() {
+		ctx := context.Background()
+
+		src := newWallet(ctx, "100")
+		dst := newWallet(ctx, "")
+
+		reqBatch := models.RequestBatchTransfer{
+			TransactionKey: uuid.New(),
+			Items:          []models.BatchTransferItem{item(dst.WalletID, "30"), item(uuid.New(), "20")},
+		}
+
+		var respBatch models.ResponseBatchTransfer
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+src.WalletID.String()+batchTransfer, reqBatch,
+			&respBatch)
+
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+		s.Require().Equal(models.BatchFailed, respBatch.Status)
+		s.Require().Equal(models.BatchItemSkipped, respBatch.Items[0].Status)
+		s.Require().Equal(models.BatchItemFailed, respBatch.Items[1].Status)
+		s.Require().NotEmpty(respBatch.Items[1].Error)
+
+		s.Require().Equal(money.MustParse("100"), balance(ctx, src.WalletID))
+		s.Require().True(balance(ctx, dst.WalletID).IsZero())
+	})
+
+	s.Run("batch transfer best effort", func() {
+		ctx := context.Background()
+
+		src := newWallet(ctx, "100")
+		dst := newWallet(ctx, "")
+
+		reqBatch := models.RequestBatchTransfer{
+			TransactionKey: uuid.New(),
+			BestEffort:     true,
+			Items: []models.BatchTransferItem{
+				item(dst.WalletID, "30"), item(uuid.New(), "20"), item(src.WalletID, "10"),
+			},
+		}
+
+		var respBatch models.ResponseBatchTransfer
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+src.WalletID.String()+batchTransfer, reqBatch,
+			&respBatch)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(models.BatchPartial, respBatch.Status)
+		s.Require().Equal(money.MustParse("30"), respBatch.Total)
+		s.Require().Equal(models.BatchItemSucceeded, respBatch.Items[0].Status)
+		s.Require().Equal(models.BatchItemFailed, respBatch.Items[1].Status)
+		s.Require().Equal(models.BatchItemFailed, respBatch.Items[2].Status)
+
+		s.Require().Equal(money.MustParse("70"), balance(ctx, src.WalletID))
+	})
+
+	s.Run("batch transfer best effort over the available funds", func() {
+		ctx := context.Background()
+
+		src := newWallet(ctx, "100")
+		dst := newWallet(ctx, "")
+
+		reqBatch := models.RequestBatchTransfer{
+			TransactionKey: uuid.New(),
+			BestEffort:     true,
+			Items:          []models.BatchTransferItem{item(dst.WalletID, "60"), item(dst.WalletID, "60")},
+		}
+
+		var respBatch models.ResponseBatchTransfer
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+src.WalletID.String()+batchTransfer, reqBatch,
+			&respBatch)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(models.BatchPartial, respBatch.Status)
+		s.Require().Equal(models.BatchItemSucceeded, respBatch.Items[0].Status)
+		s.Require().Equal(models.BatchItemFailed, respBatch.Items[1].Status)
+
+		s.Require().Equal(money.MustParse("40"), balance(ctx, src.WalletID))
+		s.Require().Equal(money.MustParse("60"), balance(ctx, dst.WalletID))
+	})
+
+	s.Run("batch transfer not valid", func() {
+		ctx := context.Background()
+
+		src := newWallet(ctx, "100")
+		dst := newWallet(ctx, "")
+
+		reqBatch := models.RequestBatchTransfer{
+			TransactionKey: uuid.New(),
+			Items:          []models.BatchTransferItem{item(dst.WalletID, "60"), item(dst.WalletID, "60")},
+		}
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+src.WalletID.String()+batchTransfer, reqBatch,
+			nil)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		reqBatch.Items = nil
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+src.WalletID.String()+batchTransfer, reqBatch,
+			nil)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		reqBatch.Items = []models.BatchTransferItem{item(dst.WalletID, "10")}
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+uuid.New().String()+batchTransfer, reqBatch,
+			nil)
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+
+		s.Require().Equal(money.MustParse("100"), balance(ctx, src.WalletID))
+	})
+}
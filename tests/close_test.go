@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+func (s *IntegrationTestSuite) TestCloseWallet() {
+	newWallet := func(ctx context.Context, currency, amount string) models.ResponseWalletInstance {
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = uuid.New().String()
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = currency
+
+		var wallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqWallet, &wallet)
+
+		if amount == "" {
+			return wallet
+		}
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = currency
+		reqDeposit.Amount = money.MustParse(amount)
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+deposit, reqDeposit,
+			&wallet)
+
+		return wallet
+	}
+
+	s.Run("close wallet with funds", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx, "USD", "100")
+
+		resp := s.sendRequest(ctx, http.MethodDelete, url+deleteWalletEndpoint+wallet.WalletID.String(), nil, nil)
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+wallet.WalletID.String(), nil, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+	})
+
+	s.Run("close wallet sweeps funds to target", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx, "USD", "100")
+		target := newWallet(ctx, "USD", "")
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+subBalances+"EUR", nil,
+			nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "EUR"
+		reqDeposit.Amount = money.MustParse("20")
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+deposit, reqDeposit, nil)
+
+		resp = s.sendRequest(ctx, http.MethodDelete,
+			url+deleteWalletEndpoint+wallet.WalletID.String()+"?sweepTo="+target.WalletID.String(), nil, nil)
+		s.Require().Equal(http.StatusNoContent, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+wallet.WalletID.String(), nil, nil)
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+
+		var respTarget models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+target.WalletID.String(), nil, &respTarget)
+
+		s.Require().Equal(1, respTarget.Balance.Cmp(money.MustParse("100")))
+
+		ledgerBalance, err := s.pg.GetLedgerBalance(ctx, target.WalletID.String())
+		s.Require().NoError(err)
+		s.Require().Equal(respTarget.Balance, ledgerBalance)
+
+		var respTransactions []models.Transaction
+
+		resp = s.sendRequest(ctx, http.MethodGet,
+			url+walletEndpoint+target.WalletID.String()+walletTransactions+"?type=TRANSFER", nil, &respTransactions)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Len(respTransactions, 2)
+
+		for _, transaction := range respTransactions {
+			s.Require().Equal(wallet.WalletID, transaction.Source.WalletID)
+			s.Require().Equal(target.WalletID, transaction.Destination.WalletID)
+		}
+	})
+
+	s.Run("close wallet sweep target not valid", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx, "USD", "100")
+
+		resp := s.sendRequest(ctx, http.MethodDelete,
+			url+deleteWalletEndpoint+wallet.WalletID.String()+"?sweepTo="+wallet.WalletID.String(), nil, nil)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodDelete,
+			url+deleteWalletEndpoint+wallet.WalletID.String()+"?sweepTo="+uuid.New().String(), nil, nil)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodDelete,
+			url+deleteWalletEndpoint+wallet.WalletID.String()+"?sweepTo=wallet", nil, nil)
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+	})
+
+	s.Run("close wallet through status change", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx, "USD", "100")
+		target := newWallet(ctx, "USD", "")
+
+		reqStatus := models.RequestWalletStatus{
+			Status:  models.WalletClosed,
+			Reason:  "Customer request",
+			SweepTo: uuid.NullUUID{UUID: target.WalletID, Valid: true},
+		}
+
+		var respWallet models.ResponseWalletInstance
+
+		resp := s.sendAdminRequest(ctx, http.MethodPut, url+adminWalletsEndpoint+wallet.WalletID.String()+walletStatus,
+			reqStatus, &respWallet)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(models.WalletClosed, respWallet.Status)
+		s.Require().True(respWallet.Balance.IsZero())
+	})
+}
@@ -0,0 +1,257 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+const concurrentRequests = 20
+
+func (s *IntegrationTestSuite) TestConcurrency() {
+	s.Run("concurrent withdrawals never overdraw wallet", func() {
+		ctx := context.Background()
+
+		req := models.RequestWalletInstance{}
+		req.TransactionKey = uuid.New()
+		req.Email = uuid.New().String()
+		req.Owner = "Kate"
+		req.Currency = "RUB"
+
+		var respData models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, req, &respData)
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "RUB"
+		reqDeposit.Amount = money.MustParse("1000")
+
+		walletIdEndpoint := respData.WalletID.String()
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, nil)
+
+		requests := make([]concurrentRequest, 0, concurrentRequests)
+
+		for i := 0; i < concurrentRequests; i++ {
+			reqWithdraw := models.FundsOperations{}
+			reqWithdraw.TransactionKey = uuid.New()
+			reqWithdraw.Currency = "RUB"
+			reqWithdraw.Amount = money.MustParse("100")
+
+			requests = append(requests, concurrentRequest{
+				endpoint: url + walletEndpoint + walletIdEndpoint + withdraw,
+				body:     reqWithdraw,
+			})
+		}
+
+		statuses := s.sendConcurrentRequests(ctx, http.MethodPut, requests)
+
+		s.Require().Equal(10, statuses[http.StatusOK])
+		s.Require().Equal(10, statuses[http.StatusUnprocessableEntity])
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+walletIdEndpoint, nil, &respData)
+
+		s.Require().Equal(money.Amount{}, respData.Balance)
+
+		ledgerBalance, err := s.pg.GetLedgerBalance(ctx, walletIdEndpoint)
+		s.Require().NoError(err)
+		s.Require().Equal(respData.Balance, ledgerBalance)
+	})
+
+	s.Run("concurrent opposite transfers conserve money", func() {
+		ctx := context.Background()
+
+		walletIDs := make([]string, 0, 2)
+
+		for _, owner := range []string{"Alex", "Kate"} {
+			req := models.RequestWalletInstance{}
+			req.TransactionKey = uuid.New()
+			req.Email = uuid.New().String()
+			req.Owner = owner
+			req.Currency = "EUR"
+
+			var respData models.ResponseWalletInstance
+
+			_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, req, &respData)
+
+			reqDeposit := models.FundsOperations{}
+			reqDeposit.TransactionKey = uuid.New()
+			reqDeposit.Currency = "EUR"
+			reqDeposit.Amount = money.MustParse("1000")
+
+			_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+respData.WalletID.String()+deposit, reqDeposit, nil)
+
+			walletIDs = append(walletIDs, respData.WalletID.String())
+		}
+
+		requests := make([]concurrentRequest, 0, concurrentRequests)
+
+		for i := 0; i < concurrentRequests; i++ {
+			src, dst := walletIDs[i%2], walletIDs[(i+1)%2]
+
+			reqTransfer := models.FundsOperations{}
+			reqTransfer.TransactionKey = uuid.New()
+			reqTransfer.Currency = "EUR"
+			reqTransfer.Amount = money.MustParse(fmt.Sprintf("%d.25", 10+i))
+
+			requests = append(requests, concurrentRequest{
+				endpoint: url + walletEndpoint + src + transfer + dst,
+				body:     reqTransfer,
+			})
+		}
+
+		statuses := s.sendConcurrentRequests(ctx, http.MethodPut, requests)
+
+		s.Require().Equal(concurrentRequests, statuses[http.StatusOK])
+
+		var total money.Amount
+
+		for _, id := range walletIDs {
+			var respData models.ResponseWalletInstance
+
+			_ = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+id, nil, &respData)
+
+			ledgerBalance, err := s.pg.GetLedgerBalance(ctx, id)
+			s.Require().NoError(err)
+			s.Require().Equal(respData.Balance, ledgerBalance)
+
+			total, err = total.Add(respData.Balance)
+			s.Require().NoError(err)
+		}
+
+		s.Require().Equal(money.MustParse("2000"), total)
+	})
+
+	s.Run("concurrent withdrawals never exceed the daily limit", func() {
+		ctx := context.Background()
+
+		limit := money.MustParse("500")
+
+		resp := s.sendAdminRequest(ctx, http.MethodPut, url+currenciesEndpoint+"RUB"+limits,
+			models.SpendingLimits{Daily: &limit}, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		req := models.RequestWalletInstance{}
+		req.TransactionKey = uuid.New()
+		req.Email = uuid.New().String()
+		req.Owner = "Kate"
+		req.Currency = "RUB"
+
+		var respData models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, req, &respData)
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "RUB"
+		reqDeposit.Amount = money.MustParse("5000")
+
+		walletIdEndpoint := respData.WalletID.String()
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, nil)
+
+		requests := make([]concurrentRequest, 0, concurrentRequests)
+
+		for i := 0; i < concurrentRequests; i++ {
+			reqWithdraw := models.FundsOperations{}
+			reqWithdraw.TransactionKey = uuid.New()
+			reqWithdraw.Currency = "RUB"
+			reqWithdraw.Amount = money.MustParse("100")
+
+			requests = append(requests, concurrentRequest{
+				endpoint: url + walletEndpoint + walletIdEndpoint + withdraw,
+				body:     reqWithdraw,
+			})
+		}
+
+		statuses := s.sendConcurrentRequests(ctx, http.MethodPut, requests)
+
+		s.Require().Equal(5, statuses[http.StatusOK])
+		s.Require().Equal(15, statuses[http.StatusPaymentRequired])
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+walletIdEndpoint, nil, &respData)
+
+		s.Require().Equal(money.MustParse("4500"), respData.Balance)
+	})
+}
+
+type concurrentRequest struct {
+	endpoint string
+	body     interface{}
+}
+
+func (s *IntegrationTestSuite) sendConcurrentRequests(ctx context.Context, method string,
+	requests []concurrentRequest,
+) map[int]int {
+	s.T().Helper()
+
+	token, err := s.server.GenerateToken(s.subject, "")
+	s.Require().NoError(err)
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		statuses = make(map[int]int)
+		errs     = make([]error, 0)
+	)
+
+	for _, request := range requests {
+		wg.Add(1)
+
+		go func(request concurrentRequest) {
+			defer wg.Done()
+
+			statusCode, err := doRequest(ctx, method, request.endpoint, token, request.body)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				errs = append(errs, err)
+
+				return
+			}
+
+			statuses[statusCode]++
+		}(request)
+	}
+
+	wg.Wait()
+
+	s.Require().Empty(errs)
+
+	return statuses
+}
+
+func doRequest(ctx context.Context, method, endpoint, token string, body interface{}) (int, error) {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return 0, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return 0, fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("http.DefaultClient.Do: %w", err)
+	}
+
+	err = resp.Body.Close()
+	if err != nil {
+		return 0, fmt.Errorf("resp.Body.Close: %w", err)
+	}
+
+	return resp.StatusCode, nil
+}
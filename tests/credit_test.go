@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+func (s *IntegrationTestSuite) TestCreditLimit() {
+	newWallet := func(ctx context.Context) models.ResponseWalletInstance {
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = uuid.New().String()
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = "USD"
+
+		var wallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqWallet, &wallet)
+
+		return wallet
+	}
+
+	setCreditLimit := func(ctx context.Context, walletID uuid.UUID, limit string) (
+		*http.Response, models.ResponseWalletInstance,
+	) {
+		var wallet models.ResponseWalletInstance
+
+		resp := s.sendAdminRequest(ctx, http.MethodPut, url+adminWalletsEndpoint+walletID.String()+creditLimit,
+			models.RequestCreditLimit{CreditLimit: money.MustParse(limit)}, &wallet)
+
+		return resp, wallet
+	}
+
+	s.Run("withdraw down to the credit limit", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx)
+
+		resp, respWallet := setCreditLimit(ctx, wallet.WalletID, "100")
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(money.MustParse("100"), respWallet.CreditLimit)
+		s.Require().Equal(money.MustParse("100"), respWallet.AvailableCredit)
+
+		reqWithdraw := models.FundsOperations{}
+		reqWithdraw.TransactionKey = uuid.New()
+		reqWithdraw.Currency = "USD"
+		reqWithdraw.Amount = money.MustParse("70")
+
+		var respWithdraw models.ResponseFundsOperation
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+withdraw, reqWithdraw,
+			&respWithdraw)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(money.MustParse("-70"), respWithdraw.Balance)
+		s.Require().Equal(money.MustParse("70"), respWithdraw.UsedCredit)
+		s.Require().Equal(money.MustParse("30"), respWithdraw.AvailableCredit)
+
+		ledgerBalance, err := s.pg.GetLedgerBalance(ctx, wallet.WalletID.String())
+		s.Require().NoError(err)
+		s.Require().Equal(respWithdraw.Balance, ledgerBalance)
+
+		reqWithdraw.TransactionKey = uuid.New()
+		reqWithdraw.Amount = money.MustParse("31")
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+withdraw, reqWithdraw,
+			nil)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+	})
+
+	s.Run("lower the limit below the used credit", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx)
+
+		_, _ = setCreditLimit(ctx, wallet.WalletID, "50")
+
+		reqWithdraw := models.FundsOperations{}
+		reqWithdraw.TransactionKey = uuid.New()
+		reqWithdraw.Currency = "USD"
+		reqWithdraw.Amount = money.MustParse("40")
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+withdraw, reqWithdraw, nil)
+
+		resp, _ := setCreditLimit(ctx, wallet.WalletID, "30")
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.Currency = "EUR"
+
+		resp = s.sendRequest(ctx, http.MethodPatch, url+updateWalletEndpoint+wallet.WalletID.String(), reqWallet, nil)
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+	})
+
+	s.Run("set credit limit not valid", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx)
+
+		resp, _ := setCreditLimit(ctx, wallet.WalletID, "-1")
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		resp, _ = setCreditLimit(ctx, wallet.WalletID, "0.001")
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		resp, _ = setCreditLimit(ctx, uuid.New(), "10")
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+func (s *IntegrationTestSuite) TestCurrencies() {
+	s.Run("get currencies normal case", func() {
+		ctx := context.Background()
+
+		var respData []models.Currency
+
+		resp := s.sendAdminRequest(ctx, http.MethodGet, url+currenciesEndpoint, nil, &respData)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		codes := make([]string, 0, len(respData))
+		for _, currency := range respData {
+			codes = append(codes, currency.Code)
+		}
+
+		s.Require().Subset(codes, []string{"EUR", "RUB", "USD"})
+	})
+
+	s.Run("create currency invalid settings", func() {
+		ctx := context.Background()
+
+		minorUnits := 2
+		req := models.RequestCurrency{}
+		req.Code = "usd"
+		req.MinorUnits = &minorUnits
+		req.ReferenceRate = money.MustParseRate("1")
+
+		resp := s.sendAdminRequest(ctx, http.MethodPost, url+currenciesEndpoint, req, nil)
+
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		req.Code = "XTS"
+		minorUnits = 4
+
+		resp = s.sendAdminRequest(ctx, http.MethodPost, url+currenciesEndpoint, req, nil)
+
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		req.MinorUnits = nil
+
+		resp = s.sendAdminRequest(ctx, http.MethodPost, url+currenciesEndpoint, req, nil)
+
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+	})
+
+	s.Run("create currency already exists", func() {
+		ctx := context.Background()
+
+		minorUnits := 2
+		req := models.RequestCurrency{}
+		req.Code = "USD"
+		req.MinorUnits = &minorUnits
+		req.ReferenceRate = money.MustParseRate("1")
+
+		resp := s.sendAdminRequest(ctx, http.MethodPost, url+currenciesEndpoint, req, nil)
+
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+	})
+
+	s.Run("deposit funds currency with zero minor units", func() {
+		ctx := context.Background()
+
+		s.ensureCurrency(ctx, "JPY", 0, "148.5")
+
+		req := models.RequestWalletInstance{}
+		req.TransactionKey = uuid.New()
+		req.Email = uuid.New().String()
+		req.Owner = "Kate"
+		req.Currency = "JPY"
+
+		var respData models.ResponseWalletInstance
+
+		resp := s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, req, &respData)
+
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+		reqDeposit := map[string]any{
+			"transactionKey": uuid.New(),
+			"currency":       "JPY",
+			"amount":         json.Number("100.5"),
+		}
+
+		walletIdEndpoint := respData.WalletID.String()
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, nil)
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+
+		reqDepositUSD := models.FundsOperations{}
+		reqDepositUSD.TransactionKey = uuid.New()
+		reqDepositUSD.Currency = "USD"
+		reqDepositUSD.Amount = money.MustParse("10.01")
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDepositUSD,
+			&respData)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().True(respData.Balance.HasMinorUnits(0))
+	})
+
+	s.Run("deposit funds currency with three minor units", func() {
+		ctx := context.Background()
+
+		s.ensureCurrency(ctx, "KWD", 3, "0.308")
+
+		req := models.RequestWalletInstance{}
+		req.TransactionKey = uuid.New()
+		req.Email = uuid.New().String()
+		req.Owner = "Alex"
+		req.Currency = "KWD"
+
+		var respData models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, req, &respData)
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "KWD"
+		reqDeposit.Amount = money.MustParse("10.005")
+
+		walletIdEndpoint := respData.WalletID.String()
+		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, &respData)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(reqDeposit.Amount, respData.Balance)
+	})
+
+	s.Run("disabled currency is not valid", func() {
+		ctx := context.Background()
+
+		s.ensureCurrency(ctx, "CHF", 2, "0.88")
+
+		enabled := false
+		reqUpdate := models.RequestCurrency{}
+		reqUpdate.Enabled = &enabled
+
+		var respCurrency models.Currency
+
+		resp := s.sendAdminRequest(ctx, http.MethodPatch, url+currenciesEndpoint+"CHF", reqUpdate, &respCurrency)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().False(respCurrency.Enabled)
+
+		req := models.RequestWalletInstance{}
+		req.TransactionKey = uuid.New()
+		req.Email = uuid.New().String()
+		req.Owner = "Alex"
+		req.Currency = "CHF"
+
+		resp = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, req, nil)
+
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+	})
+
+	s.Run("update currency minor units", func() {
+		ctx := context.Background()
+
+		minorUnits := 3
+		reqUpdate := models.RequestCurrency{}
+		reqUpdate.MinorUnits = &minorUnits
+
+		resp := s.sendAdminRequest(ctx, http.MethodPatch, url+currenciesEndpoint+"USD", reqUpdate, nil)
+
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		minorUnits = 0
+
+		resp = s.sendAdminRequest(ctx, http.MethodPatch, url+currenciesEndpoint+"USD", reqUpdate, nil)
+
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		minorUnits = 2
+
+		resp = s.sendAdminRequest(ctx, http.MethodPatch, url+currenciesEndpoint+"USD", reqUpdate, nil)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+	})
+}
+
+// ensureCurrency registers and enables a currency; the registry is not truncated between tests.
+func (s *IntegrationTestSuite) ensureCurrency(ctx context.Context, code string, minorUnits int, referenceRate string) {
+	s.T().Helper()
+
+	enabled := true
+	req := models.RequestCurrency{}
+	req.Code = code
+	req.MinorUnits = &minorUnits
+	req.Enabled = &enabled
+	req.ReferenceRate = money.MustParseRate(referenceRate)
+
+	resp := s.sendAdminRequest(ctx, http.MethodPost, url+currenciesEndpoint, req, nil)
+	if resp.StatusCode == http.StatusConflict {
+		resp = s.sendAdminRequest(ctx, http.MethodPatch, url+currenciesEndpoint+code, req, nil)
+	}
+
+	s.Require().Contains([]int{http.StatusCreated, http.StatusOK}, resp.StatusCode)
+}
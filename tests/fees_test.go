@@ -0,0 +1,270 @@
+package tests
+
+import (
+	"context"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/AlexZav1327/service/internal/postgres"
+	walletservice "github.com/AlexZav1327/service/internal/wallet-service"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+func (s *IntegrationTestSuite) TestFees() {
+	newWallet := func(ctx context.Context, currency string) models.ResponseWalletInstance {
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.WalletID = uuid.New()
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = uuid.New().String()
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = currency
+
+		wallet, err := s.walletService.CreateWallet(ctx, reqWallet)
+		s.Require().NoError(err)
+
+		return wallet
+	}
+
+	newFeeService := func(feeWalletID uuid.UUID) *walletservice.Service {
+		return walletservice.New(s.pg, s.xr, s.message, s.notifications, logrus.StandardLogger(),
+			walletservice.Config{
+				SpreadBps:   spreadBps,
+				FeeWalletID: feeWalletID,
+				FeeRules: []walletservice.FeeRule{
+					{Flat: money.MustParse("5")},
+					{Operation: "DEPOSIT", Flat: money.MustParse("2")},
+					{
+						Operation: "WITHDRAWAL",
+						Percent:   money.MustParseRate("1"),
+						Min:       money.MustParse("3"),
+						Max:       money.MustParse("20"),
+					},
+					{
+						Operation: "TRANSFER",
+						Tiers: []walletservice.FeeTier{
+							{UpTo: money.MustParse("1000"), Flat: money.MustParse("10")},
+							{Percent: money.MustParseRate("0.5")},
+						},
+					},
+				},
+			})
+	}
+
+	s.Run("deposit fee is deducted from the credited amount", func() {
+		ctx := context.Background()
+
+		feeWallet := newWallet(ctx, "RUB")
+		wallet := newWallet(ctx, "RUB")
+		service := newFeeService(feeWallet.WalletID)
+
+		response, err := service.DepositFunds(ctx, wallet.WalletID.String(), models.FundsOperations{
+			TransactionKey: uuid.New(),
+			Amount:         money.MustParse("100"),
+			Currency:       "RUB",
+		})
+		s.Require().NoError(err)
+		s.Require().NotNil(response.Fee)
+		s.Require().Equal(money.MustParse("2"), response.Fee.Amount)
+		s.Require().Equal("RUB", response.Fee.Currency)
+		s.Require().Equal(money.MustParse("98"), response.Balance)
+
+		updatedFeeWallet, err := s.walletService.GetWallet(ctx, feeWallet.WalletID.String())
+		s.Require().NoError(err)
+		s.Require().Equal(money.MustParse("2"), updatedFeeWallet.Balance)
+
+		transaction, err := s.walletService.GetTransaction(ctx, response.TransactionID.String())
+		s.Require().NoError(err)
+		s.Require().NotNil(transaction.Fee)
+		s.Require().Equal(money.MustParse("2"), transaction.Fee.Amount)
+		s.Require().Equal(feeWallet.WalletID, transaction.Fee.FeeWalletID)
+		s.Require().Equal(money.MustParse("100"), transaction.Destination.Amount)
+	})
+
+	s.Run("deposit not covering the fee", func() {
+		ctx := context.Background()
+
+		feeWallet := newWallet(ctx, "RUB")
+		wallet := newWallet(ctx, "RUB")
+		service := newFeeService(feeWallet.WalletID)
+
+		_, err := service.DepositFunds(ctx, wallet.WalletID.String(), models.FundsOperations{
+			TransactionKey: uuid.New(),
+			Amount:         money.MustParse("2"),
+			Currency:       "RUB",
+		})
+		s.Require().ErrorIs(err, walletservice.ErrFeeExceedsAmount)
+	})
+
+	s.Run("withdrawal fee is capped by min and max", func() {
+		ctx := context.Background()
+
+		feeWallet := newWallet(ctx, "RUB")
+		wallet := newWallet(ctx, "RUB")
+		service := newFeeService(feeWallet.WalletID)
+
+		_, err := s.walletService.DepositFunds(ctx, wallet.WalletID.String(), models.FundsOperations{
+			TransactionKey: uuid.New(),
+			Amount:         money.MustParse("10000"),
+			Currency:       "RUB",
+		})
+		s.Require().NoError(err)
+
+		response, err := service.WithdrawFunds(ctx, wallet.WalletID.String(), models.FundsOperations{
+			TransactionKey: uuid.New(),
+			Amount:         money.MustParse("100"),
+			Currency:       "RUB",
+		})
+		s.Require().NoError(err)
+		s.Require().Equal(money.MustParse("3"), response.Fee.Amount)
+		s.Require().Equal(money.MustParse("9897"), response.Balance)
+
+		response, err = service.WithdrawFunds(ctx, wallet.WalletID.String(), models.FundsOperations{
+			TransactionKey: uuid.New(),
+			Amount:         money.MustParse("5000"),
+			Currency:       "RUB",
+		})
+		s.Require().NoError(err)
+		s.Require().Equal(money.MustParse("20"), response.Fee.Amount)
+		s.Require().Equal(money.MustParse("4877"), response.Balance)
+	})
+
+	s.Run("withdrawal fee counts towards overdraft", func() {
+		ctx := context.Background()
+
+		feeWallet := newWallet(ctx, "RUB")
+		wallet := newWallet(ctx, "RUB")
+		service := newFeeService(feeWallet.WalletID)
+
+		_, err := s.walletService.DepositFunds(ctx, wallet.WalletID.String(), models.FundsOperations{
+			TransactionKey: uuid.New(),
+			Amount:         money.MustParse("100"),
+			Currency:       "RUB",
+		})
+		s.Require().NoError(err)
+
+		_, err = service.WithdrawFunds(ctx, wallet.WalletID.String(), models.FundsOperations{
+			TransactionKey: uuid.New(),
+			Amount:         money.MustParse("100"),
+			Currency:       "RUB",
+		})
+		s.Require().Error(err)
+
+		updatedWallet, err := s.walletService.GetWallet(ctx, wallet.WalletID.String())
+		s.Require().NoError(err)
+		s.Require().Equal(money.MustParse("100"), updatedWallet.Balance)
+	})
+
+	s.Run("transfer fee is tiered and converted for the fee wallet", func() {
+		ctx := context.Background()
+
+		feeWallet := newWallet(ctx, "USD")
+		srcWallet := newWallet(ctx, "RUB")
+		dstWallet := newWallet(ctx, "RUB")
+		service := newFeeService(feeWallet.WalletID)
+
+		_, err := s.walletService.DepositFunds(ctx, srcWallet.WalletID.String(), models.FundsOperations{
+			TransactionKey: uuid.New(),
+			Amount:         money.MustParse("100000"),
+			Currency:       "RUB",
+		})
+		s.Require().NoError(err)
+
+		response, err := service.TransferFunds(ctx, srcWallet.WalletID.String(), dstWallet.WalletID.String(),
+			models.FundsOperations{
+				TransactionKey: uuid.New(),
+				Amount:         money.MustParse("500"),
+				Currency:       "RUB",
+			})
+		s.Require().NoError(err)
+		s.Require().Equal(money.MustParse("10"), response.Fee.Amount)
+		s.Require().Equal(money.MustParse("99490"), response.SrcWallet.Balance)
+		s.Require().Equal(money.MustParse("500"), response.DstWallet.Balance)
+
+		response, err = service.TransferFunds(ctx, srcWallet.WalletID.String(), dstWallet.WalletID.String(),
+			models.FundsOperations{
+				TransactionKey: uuid.New(),
+				Amount:         money.MustParse("10000"),
+				Currency:       "RUB",
+			})
+		s.Require().NoError(err)
+		s.Require().Equal(money.MustParse("50"), response.Fee.Amount)
+
+		firstFee, err := s.walletService.ConvertCurrency(ctx, models.SideBid, "RUB", "USD", money.MustParse("10"))
+		s.Require().NoError(err)
+
+		secondFee, err := s.walletService.ConvertCurrency(ctx, models.SideBid, "RUB", "USD", money.MustParse("50"))
+		s.Require().NoError(err)
+
+		expectedBalance, err := firstFee.Add(secondFee)
+		s.Require().NoError(err)
+
+		updatedFeeWallet, err := s.walletService.GetWallet(ctx, feeWallet.WalletID.String())
+		s.Require().NoError(err)
+		s.Require().Equal(expectedBalance, updatedFeeWallet.Balance)
+
+		ledgerBalance, err := s.pg.GetLedgerBalance(ctx, feeWallet.WalletID.String())
+		s.Require().NoError(err)
+		s.Require().Equal(updatedFeeWallet.Balance, ledgerBalance)
+	})
+
+	s.Run("capture fee is held by the authorization", func() {
+		ctx := context.Background()
+
+		feeWallet := newWallet(ctx, "RUB")
+		wallet := newWallet(ctx, "RUB")
+		service := newFeeService(feeWallet.WalletID)
+
+		_, err := s.walletService.DepositFunds(ctx, wallet.WalletID.String(), models.FundsOperations{
+			TransactionKey: uuid.New(),
+			Amount:         money.MustParse("100"),
+			Currency:       "RUB",
+		})
+		s.Require().NoError(err)
+
+		_, err = service.AuthorizeHold(ctx, wallet.WalletID.String(), models.RequestAuthorize{
+			TransactionKey: uuid.New(),
+			Amount:         money.MustParse("100"),
+			Currency:       "RUB",
+		})
+		s.Require().ErrorIs(err, postgres.ErrOverdraft)
+
+		hold, err := service.AuthorizeHold(ctx, wallet.WalletID.String(), models.RequestAuthorize{
+			TransactionKey: uuid.New(),
+			Amount:         money.MustParse("95"),
+			Currency:       "RUB",
+		})
+		s.Require().NoError(err)
+		s.Require().Equal(money.MustParse("100"), hold.HeldAmount)
+		s.Require().True(hold.Wallet.AvailableBalance.IsZero())
+
+		captured, err := service.CaptureHold(ctx, hold.HoldID.String(), models.RequestCapture{
+			TransactionKey: uuid.New(),
+		})
+		s.Require().NoError(err)
+		s.Require().NotNil(captured.Fee)
+		s.Require().Equal(money.MustParse("5"), captured.Fee.Amount)
+		s.Require().Equal(money.MustParse("95"), captured.CapturedAmount)
+		s.Require().True(captured.Wallet.Balance.IsZero())
+
+		updatedFeeWallet, err := s.walletService.GetWallet(ctx, feeWallet.WalletID.String())
+		s.Require().NoError(err)
+		s.Require().Equal(money.MustParse("5"), updatedFeeWallet.Balance)
+	})
+
+	s.Run("no fee without a fee wallet", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx, "RUB")
+		service := newFeeService(uuid.Nil)
+
+		response, err := service.DepositFunds(ctx, wallet.WalletID.String(), models.FundsOperations{
+			TransactionKey: uuid.New(),
+			Amount:         money.MustParse("100"),
+			Currency:       "RUB",
+		})
+		s.Require().NoError(err)
+		s.Require().Nil(response.Fee)
+		s.Require().Equal(money.MustParse("100"), response.Balance)
+	})
+}
@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+func (s *IntegrationTestSuite) TestHolds() {
+	newFundedWallet := func(ctx context.Context, amount string) models.ResponseWalletInstance {
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = uuid.New().String()
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = "USD"
+
+		var wallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqWallet, &wallet)
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "USD"
+		reqDeposit.Amount = money.MustParse(amount)
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+deposit, reqDeposit,
+			&wallet)
+
+		return wallet
+	}
+
+	authorize := func(ctx context.Context, walletID uuid.UUID, amount string, ttlSeconds int64) (
+		*http.Response, models.ResponseHold,
+	) {
+		reqAuthorize := models.RequestAuthorize{}
+		reqAuthorize.TransactionKey = uuid.New()
+		reqAuthorize.Currency = "USD"
+		reqAuthorize.Amount = money.MustParse(amount)
+		reqAuthorize.TTLSeconds = ttlSeconds
+
+		var respHold models.ResponseHold
+
+		resp := s.sendRequest(ctx, http.MethodPost, url+walletEndpoint+walletID.String()+walletHolds, reqAuthorize,
+			&respHold)
+
+		return resp, respHold
+	}
+
+	s.Run("authorize reduces available balance only", func() {
+		ctx := context.Background()
+
+		wallet := newFundedWallet(ctx, "100")
+
+		resp, respHold := authorize(ctx, wallet.WalletID, "30", 0)
+
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+		s.Require().Equal(models.HoldAuthorized, respHold.Status)
+		s.Require().Equal(money.MustParse("30"), respHold.HeldAmount)
+		s.Require().Equal(money.MustParse("100"), respHold.Wallet.Balance)
+		s.Require().Equal(money.MustParse("70"), respHold.Wallet.AvailableBalance)
+
+		var respWallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+wallet.WalletID.String(), nil, &respWallet)
+
+		s.Require().Equal(money.MustParse("100"), respWallet.Balance)
+		s.Require().Equal(money.MustParse("70"), respWallet.AvailableBalance)
+	})
+
+	s.Run("authorize and withdraw beyond available balance", func() {
+		ctx := context.Background()
+
+		wallet := newFundedWallet(ctx, "100")
+
+		resp, _ := authorize(ctx, wallet.WalletID, "60", 0)
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+		resp, _ = authorize(ctx, wallet.WalletID, "50", 0)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		reqWithdraw := models.FundsOperations{}
+		reqWithdraw.TransactionKey = uuid.New()
+		reqWithdraw.Currency = "USD"
+		reqWithdraw.Amount = money.MustParse("50")
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+withdraw, reqWithdraw,
+			nil)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+	})
+
+	s.Run("partial capture releases the rest", func() {
+		ctx := context.Background()
+
+		wallet := newFundedWallet(ctx, "100")
+
+		_, respHold := authorize(ctx, wallet.WalletID, "40", 0)
+
+		reqCapture := models.RequestCapture{}
+		reqCapture.TransactionKey = uuid.New()
+		reqCapture.Amount = money.MustParse("25")
+
+		var respCapture models.ResponseHold
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+holdsEndpoint+respHold.HoldID.String()+capture, reqCapture,
+			&respCapture)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(models.HoldCaptured, respCapture.Status)
+		s.Require().Equal(money.MustParse("25"), respCapture.CapturedAmount)
+		s.Require().True(respCapture.TransactionID.Valid)
+		s.Require().Equal(money.MustParse("75"), respCapture.Wallet.Balance)
+		s.Require().Equal(money.MustParse("75"), respCapture.Wallet.AvailableBalance)
+
+		var respTransaction models.Transaction
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+transactionsEndpoint+respCapture.TransactionID.UUID.String(), nil,
+			&respTransaction)
+
+		s.Require().Equal("CAPTURE", respTransaction.OperationType)
+		s.Require().Equal(money.MustParse("25"), respTransaction.Amount)
+
+		ledgerBalance, err := s.pg.GetLedgerBalance(ctx, wallet.WalletID.String())
+		s.Require().NoError(err)
+		s.Require().Equal(respCapture.Wallet.Balance, ledgerBalance)
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+holdsEndpoint+respHold.HoldID.String()+capture,
+			models.RequestCapture{TransactionKey: uuid.New()}, nil)
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+	})
+
+	s.Run("capture more than held", func() {
+		ctx := context.Background()
+
+		wallet := newFundedWallet(ctx, "100")
+
+		_, respHold := authorize(ctx, wallet.WalletID, "40", 0)
+
+		reqCapture := models.RequestCapture{}
+		reqCapture.TransactionKey = uuid.New()
+		reqCapture.Amount = money.MustParse("41")
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+holdsEndpoint+respHold.HoldID.String()+capture, reqCapture,
+			nil)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+	})
+
+	s.Run("void releases the hold", func() {
+		ctx := context.Background()
+
+		wallet := newFundedWallet(ctx, "100")
+
+		_, respHold := authorize(ctx, wallet.WalletID, "40", 0)
+
+		var respVoid models.ResponseHold
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+holdsEndpoint+respHold.HoldID.String()+void, nil, &respVoid)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(models.HoldVoided, respVoid.Status)
+		s.Require().Equal(money.MustParse("100"), respVoid.Wallet.Balance)
+		s.Require().Equal(money.MustParse("100"), respVoid.Wallet.AvailableBalance)
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+holdsEndpoint+respHold.HoldID.String()+capture,
+			models.RequestCapture{TransactionKey: uuid.New()}, nil)
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+	})
+
+	s.Run("expired hold is released", func() {
+		ctx := context.Background()
+
+		wallet := newFundedWallet(ctx, "100")
+
+		_, respHold := authorize(ctx, wallet.WalletID, "40", 1)
+
+		time.Sleep(1100 * time.Millisecond)
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+holdsEndpoint+respHold.HoldID.String()+capture,
+			models.RequestCapture{TransactionKey: uuid.New()}, nil)
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+
+		_, err := s.pg.ExpireHolds(ctx)
+		s.Require().NoError(err)
+
+		var hold models.Hold
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+holdsEndpoint+respHold.HoldID.String(), nil, &hold)
+
+		s.Require().Equal(models.HoldExpired, hold.Status)
+
+		var respWallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+wallet.WalletID.String(), nil, &respWallet)
+
+		s.Require().Equal(money.MustParse("100"), respWallet.AvailableBalance)
+	})
+
+	s.Run("get hold not found", func() {
+		ctx := context.Background()
+
+		resp := s.sendRequest(ctx, http.MethodGet, url+holdsEndpoint+uuid.New().String(), nil, nil)
+
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+	})
+}
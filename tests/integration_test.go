@@ -3,6 +3,7 @@ package tests
 import (
 	"bytes"
 	"context"
+	"crypto/rsa"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -10,13 +11,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/AlexZav1327/service/internal/jwks"
 	"github.com/AlexZav1327/service/internal/messages"
+	"github.com/AlexZav1327/service/internal/models"
 	"github.com/AlexZav1327/service/internal/notifications"
 	"github.com/AlexZav1327/service/internal/postgres"
 	"github.com/AlexZav1327/service/internal/rates"
 	walletserver "github.com/AlexZav1327/service/internal/wallet-server"
 	walletservice "github.com/AlexZav1327/service/internal/wallet-service"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	migrate "github.com/rubenv/sql-migrate"
 	"github.com/sirupsen/logrus"
@@ -24,18 +28,45 @@ import (
 )
 
 const (
-	port                  = 5005
-	host                  = ""
-	dsn                   = "user=user password=secret host=localhost port=5432 dbname=postgres sslmode=disable"
-	createWalletEndpoint  = "/api/v1/wallet/create"
-	walletHistoryEndpoint = "/api/v1/wallet/history"
-	walletEndpoint        = "/api/v1/wallet/"
-	walletsEndpoint       = "/api/v1/wallets"
-	updateWalletEndpoint  = "/api/v1/wallet/update/"
-	deleteWalletEndpoint  = "/api/v1/wallet/delete/"
-	deposit               = "/deposit"
-	withdraw              = "/withdraw"
-	transfer              = "/transfer/"
+	port                      = 5005
+	spreadBps                 = 50
+	issuer                    = "https://auth.example.com"
+	audience                  = "wallets-service"
+	host                      = ""
+	dsn                       = "user=user password=secret host=localhost port=5432 dbname=postgres sslmode=disable"
+	createWalletEndpoint      = "/api/v1/wallet/create"
+	walletHistoryEndpoint     = "/api/v1/wallet/history"
+	walletEndpoint            = "/api/v1/wallet/"
+	walletsEndpoint           = "/api/v1/wallets"
+	updateWalletEndpoint      = "/api/v1/wallet/update/"
+	deleteWalletEndpoint      = "/api/v1/wallet/delete/"
+	deposit                   = "/deposit"
+	withdraw                  = "/withdraw"
+	transfer                  = "/transfer/"
+	transactionsEndpoint      = "/api/v1/transactions/"
+	walletTransactions        = "/transactions"
+	currenciesEndpoint        = "/api/v1/admin/currencies/"
+	holdsEndpoint             = "/api/v1/holds/"
+	adminWalletsEndpoint      = "/api/v1/admin/wallets/"
+	limits                    = "/limits"
+	walletHolds               = "/holds"
+	capture                   = "/capture"
+	void                      = "/void"
+	creditLimit               = "/credit-limit"
+	subBalances               = "/balances/"
+	exchange                  = "/exchange"
+	walletStatus              = "/status"
+	walletRestore             = "/restore"
+	standingOrdersEndpoint    = "/api/v1/standing-orders/"
+	walletStandingOrders      = "/standing-orders"
+	executions                = "/executions"
+	batchTransfer             = "/batch-transfer"
+	adminTransactionsEndpoint = "/api/v1/admin/transactions/"
+	reverse                   = "/reverse"
+	quotesEndpoint            = "/api/v1/quotes"
+	adjustments               = "/adjustments"
+	authEndpoint              = "/api/v1/auth/"
+	apiKeysEndpoint           = "/api/v1/admin/api-keys/"
 )
 
 var url = fmt.Sprintf("http://localhost:%d", port)
@@ -48,6 +79,8 @@ type IntegrationTestSuite struct {
 	xr            *rates.Rates
 	message       *messages.Message
 	notifications *notifications.Notifications
+	signingKey    *rsa.PrivateKey
+	subject       string
 }
 
 func (s *IntegrationTestSuite) SetupSuite() {
@@ -71,15 +104,22 @@ func (s *IntegrationTestSuite) SetupSuite() {
 	s.message = messages.New(logger)
 	s.notifications = notifications.New(logger)
 
-	s.walletService = walletservice.New(s.pg, s.xr, s.message, s.notifications, logger)
+	s.walletService = walletservice.New(s.pg, s.xr, s.message, s.notifications, logger, walletservice.Config{
+		SpreadBps: spreadBps,
+	})
 
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(signingKey))
+	s.signingKey, err = jwt.ParseRSAPrivateKeyFromPEM([]byte(signingKey))
 	s.Require().NoError(err)
 
 	publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(verificationKey))
 	s.Require().NoError(err)
 
-	s.server = walletserver.New(host, port, s.walletService, logger, privateKey, publicKey)
+	keys := jwks.New(logger, jwks.Config{StaticKey: publicKey})
+
+	s.server = walletserver.New(host, port, s.walletService, logger, s.signingKey, keys, walletserver.Config{
+		Issuer:   issuer,
+		Audience: audience,
+	})
 
 	go func() {
 		_ = s.server.Run(ctx)
@@ -88,6 +128,14 @@ func (s *IntegrationTestSuite) SetupSuite() {
 	time.Sleep(250 * time.Millisecond)
 }
 
+func (s *IntegrationTestSuite) SetupTest() {
+	s.subject = uuid.New().String()
+}
+
+func (s *IntegrationTestSuite) SetupSubTest() {
+	s.subject = uuid.New().String()
+}
+
 func (s *IntegrationTestSuite) TearDownTest() {
 	ctx := context.Background()
 
@@ -99,6 +147,45 @@ func (s *IntegrationTestSuite) TearDownTest() {
 
 	err = s.pg.TruncateTable(ctx, "history")
 	s.Require().NoError(err)
+
+	err = s.pg.TruncateTable(ctx, "ledger_entries")
+	s.Require().NoError(err)
+
+	err = s.pg.TruncateTable(ctx, "ledger_balances")
+	s.Require().NoError(err)
+
+	err = s.pg.TruncateTable(ctx, "transactions")
+	s.Require().NoError(err)
+
+	err = s.pg.TruncateTable(ctx, "holds")
+	s.Require().NoError(err)
+
+	err = s.pg.TruncateTable(ctx, "currency_spending_limits")
+	s.Require().NoError(err)
+
+	err = s.pg.TruncateTable(ctx, "wallet_spending_limits")
+	s.Require().NoError(err)
+
+	err = s.pg.TruncateTable(ctx, "wallet_balances")
+	s.Require().NoError(err)
+
+	err = s.pg.TruncateTable(ctx, "standing_orders")
+	s.Require().NoError(err)
+
+	err = s.pg.TruncateTable(ctx, "standing_order_executions")
+	s.Require().NoError(err)
+
+	err = s.pg.TruncateTable(ctx, "quotes")
+	s.Require().NoError(err)
+
+	err = s.pg.TruncateTable(ctx, "sessions")
+	s.Require().NoError(err)
+
+	err = s.pg.TruncateTable(ctx, "revoked_tokens")
+	s.Require().NoError(err)
+
+	err = s.pg.TruncateTable(ctx, "api_keys")
+	s.Require().NoError(err)
 }
 
 func TestIntegrationTestSuite(t *testing.T) {
@@ -110,72 +197,86 @@ func (s *IntegrationTestSuite) sendRequest(ctx context.Context, method, endpoint
 ) *http.Response {
 	s.T().Helper()
 
-	reqBody, err := json.Marshal(body)
-	s.Require().NoError(err)
-
-	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(reqBody))
+	token, err := s.server.GenerateToken(s.subject, "")
 	s.Require().NoError(err)
 
-	token, err := s.server.GenerateToken("", "")
-	s.Require().NoError(err)
+	return s.send(ctx, method, endpoint, token, body, dest)
+}
 
-	bearer := fmt.Sprintf("Bearer %s", token)
+// identityToken returns a token for the current subject as the identity provider would issue it.
+func (s *IntegrationTestSuite) identityToken() string {
+	s.T().Helper()
 
-	req.Header.Set("Authorization", bearer)
-	req.Header.Set("Content-Type", "application/json")
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, &walletserver.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		UUID: s.subject,
+	})
 
-	resp, err := http.DefaultClient.Do(req)
+	tokenString, err := token.SignedString(s.signingKey)
 	s.Require().NoError(err)
 
-	defer func() {
-		err = resp.Body.Close()
-		s.Require().NoError(err)
-	}()
+	return tokenString
+}
 
-	if dest != nil {
-		err = json.NewDecoder(resp.Body).Decode(&dest)
-		s.Require().NoError(err)
-	}
+func (s *IntegrationTestSuite) sendAdminRequest(ctx context.Context, method, endpoint string, body,
+	dest interface{},
+) *http.Response {
+	s.T().Helper()
 
-	return resp
+	return s.sendRequestWithRoles(ctx, method, endpoint, []string{models.RoleAdmin}, body, dest)
 }
 
-func (s *IntegrationTestSuite) sendRequestWithCustomClaims(ctx context.Context, method, endpoint, claimUUID,
-	claimEmail string, body, dest interface{},
+func (s *IntegrationTestSuite) sendRequestWithRoles(ctx context.Context, method, endpoint string, roles []string,
+	body, dest interface{},
 ) *http.Response {
 	s.T().Helper()
 
-	reqBody, err := json.Marshal(body)
+	token, err := s.server.GenerateToken(s.subject, "", roles...)
 	s.Require().NoError(err)
 
-	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(reqBody))
-	s.Require().NoError(err)
+	return s.send(ctx, method, endpoint, token, body, dest)
+}
+
+func (s *IntegrationTestSuite) sendRequestWithCustomClaims(ctx context.Context, method, endpoint, claimUUID,
+	claimEmail string, body, dest interface{},
+) *http.Response {
+	s.T().Helper()
 
 	token, err := s.server.GenerateToken(claimUUID, claimEmail)
 	s.Require().NoError(err)
 
-	bearer := fmt.Sprintf("Bearer %s", token)
+	return s.send(ctx, method, endpoint, token, body, dest)
+}
 
-	req.Header.Set("Authorization", bearer)
-	req.Header.Set("Content-Type", "application/json")
+func (s *IntegrationTestSuite) sendRequestWithInvalidToken(ctx context.Context, method, endpoint string, body,
+	dest interface{},
+) *http.Response {
+	s.T().Helper()
 
-	resp, err := http.DefaultClient.Do(req)
-	s.Require().NoError(err)
+	return s.send(ctx, method, endpoint, "", body, dest)
+}
 
-	defer func() {
-		err = resp.Body.Close()
-		s.Require().NoError(err)
-	}()
+func (s *IntegrationTestSuite) sendRequestWithAPIKey(ctx context.Context, method, endpoint, key string, body,
+	dest interface{},
+) *http.Response {
+	s.T().Helper()
 
-	if dest != nil {
-		err = json.NewDecoder(resp.Body).Decode(&dest)
-		s.Require().NoError(err)
-	}
+	return s.sendWithHeader(ctx, method, endpoint, "X-API-Key", key, body, dest)
+}
 
-	return resp
+func (s *IntegrationTestSuite) send(ctx context.Context, method, endpoint, token string, body,
+	dest interface{},
+) *http.Response {
+	s.T().Helper()
+
+	return s.sendWithHeader(ctx, method, endpoint, "Authorization", fmt.Sprintf("Bearer %s", token), body, dest)
 }
 
-func (s *IntegrationTestSuite) sendRequestWithInvalidToken(ctx context.Context, method, endpoint string, body,
+func (s *IntegrationTestSuite) sendWithHeader(ctx context.Context, method, endpoint, header, value string, body,
 	dest interface{},
 ) *http.Response {
 	s.T().Helper()
@@ -186,10 +287,7 @@ func (s *IntegrationTestSuite) sendRequestWithInvalidToken(ctx context.Context,
 	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(reqBody))
 	s.Require().NoError(err)
 
-	token := ""
-	bearer := fmt.Sprintf("Bearer %s", token)
-
-	req.Header.Set("Authorization", bearer)
+	req.Header.Set(header, value)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := http.DefaultClient.Do(req)
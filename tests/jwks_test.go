@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/jwks"
+	walletserver "github.com/AlexZav1327/service/internal/wallet-server"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+func (s *IntegrationTestSuite) TestJWKS() {
+	newKey := func() *rsa.PrivateKey {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		s.Require().NoError(err)
+
+		return key
+	}
+
+	document := func(keys map[string]*rsa.PrivateKey) []byte {
+		var doc struct {
+			Keys []map[string]string `json:"keys"`
+		}
+
+		for kid, key := range keys {
+			doc.Keys = append(doc.Keys, map[string]string{
+				"kty": "RSA",
+				"kid": kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+
+		data, err := json.Marshal(doc)
+		s.Require().NoError(err)
+
+		return data
+	}
+
+	signToken := func(claims jwt.RegisteredClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, &walletserver.Claims{
+			RegisteredClaims: claims,
+			UUID:             s.subject,
+		})
+
+		tokenString, err := token.SignedString(s.signingKey)
+		s.Require().NoError(err)
+
+		return tokenString
+	}
+
+	s.Run("issuer and audience are checked", func() {
+		ctx := context.Background()
+		expiresAt := jwt.NewNumericDate(time.Now().Add(time.Hour))
+
+		token := signToken(jwt.RegisteredClaims{
+			Issuer:    "https://other.example.com",
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: expiresAt,
+		})
+
+		resp := s.send(ctx, http.MethodGet, url+walletsEndpoint, token, nil, nil)
+		s.Require().Equal(http.StatusUnauthorized, resp.StatusCode)
+
+		token = signToken(jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{"other-service"},
+			ExpiresAt: expiresAt,
+		})
+
+		resp = s.send(ctx, http.MethodGet, url+walletsEndpoint, token, nil, nil)
+		s.Require().Equal(http.StatusUnauthorized, resp.StatusCode)
+
+		token = signToken(jwt.RegisteredClaims{
+			Issuer:    "wallets-service",
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: expiresAt,
+		})
+
+		resp = s.send(ctx, http.MethodGet, url+walletsEndpoint, token, nil, nil)
+		s.Require().Equal(http.StatusUnauthorized, resp.StatusCode)
+
+		token = signToken(jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: expiresAt,
+		})
+
+		resp = s.send(ctx, http.MethodGet, url+walletsEndpoint, token, nil, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+	})
+
+	s.Run("keys are selected by kid and refreshed after rotation", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		oldKey := newKey()
+		newerKey := newKey()
+
+		var mu sync.Mutex
+
+		current := document(map[string]*rsa.PrivateKey{"old": oldKey})
+
+		mux := http.NewServeMux()
+		srv := httptest.NewServer(mux)
+
+		defer srv.Close()
+
+		mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]string{"jwks_uri": srv.URL + "/keys"})
+		})
+		mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			_, _ = w.Write(current)
+		})
+
+		keys := jwks.New(logrus.StandardLogger(), jwks.Config{Issuer: srv.URL, Refresh: 50 * time.Millisecond})
+
+		err := keys.Load(ctx)
+		s.Require().NoError(err)
+
+		key, err := keys.Key(ctx, "old")
+		s.Require().NoError(err)
+		s.Require().True(oldKey.PublicKey.Equal(key))
+
+		_, err = keys.Key(ctx, "new")
+		s.Require().ErrorIs(err, jwks.ErrKeyNotFound)
+
+		mu.Lock()
+		current = document(map[string]*rsa.PrivateKey{"new": newerKey})
+		mu.Unlock()
+
+		go func() {
+			_ = keys.RefreshRun(ctx)
+		}()
+
+		s.Require().Eventually(func() bool {
+			key, err = keys.Key(ctx, "new")
+
+			return err == nil && newerKey.PublicKey.Equal(key)
+		}, time.Second, 25*time.Millisecond)
+
+		_, err = keys.Key(ctx, "old")
+		s.Require().ErrorIs(err, jwks.ErrKeyNotFound)
+	})
+
+	s.Run("keys are loaded from a file and the static key is an opt-in fallback", func() {
+		ctx := context.Background()
+
+		fileKey := newKey()
+		staticKey := newKey()
+
+		path := filepath.Join(s.T().TempDir(), "jwks.json")
+
+		err := os.WriteFile(path, document(map[string]*rsa.PrivateKey{"file": fileKey}), 0o600)
+		s.Require().NoError(err)
+
+		keys := jwks.New(logrus.StandardLogger(), jwks.Config{File: path, StaticKey: &staticKey.PublicKey})
+
+		err = keys.Load(ctx)
+		s.Require().NoError(err)
+
+		key, err := keys.Key(ctx, "file")
+		s.Require().NoError(err)
+		s.Require().True(fileKey.PublicKey.Equal(key))
+
+		_, err = keys.Key(ctx, "")
+		s.Require().ErrorIs(err, jwks.ErrKeyNotFound)
+
+		keys = jwks.New(logrus.StandardLogger(), jwks.Config{
+			File:           path,
+			StaticKey:      &staticKey.PublicKey,
+			StaticFallback: true,
+		})
+
+		key, err = keys.Key(ctx, "")
+		s.Require().NoError(err)
+		s.Require().True(staticKey.PublicKey.Equal(key))
+
+		keys = jwks.New(logrus.StandardLogger(), jwks.Config{StaticKey: &staticKey.PublicKey})
+
+		key, err = keys.Key(ctx, "file")
+		s.Require().NoError(err)
+		s.Require().True(staticKey.PublicKey.Equal(key))
+	})
+}
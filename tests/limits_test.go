@@ -0,0 +1,224 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+func (s *IntegrationTestSuite) TestSpendingLimits() {
+	newFundedWallet := func(ctx context.Context, currency, amount string) models.ResponseWalletInstance {
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = uuid.New().String()
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = currency
+
+		var wallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqWallet, &wallet)
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = currency
+		reqDeposit.Amount = money.MustParse(amount)
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+deposit, reqDeposit, nil)
+
+		return wallet
+	}
+
+	amount := func(value string) *money.Amount {
+		a := money.MustParse(value)
+
+		return &a
+	}
+
+	s.Run("withdraw over currency default daily limit", func() {
+		ctx := context.Background()
+
+		resp := s.sendAdminRequest(ctx, http.MethodPut, url+currenciesEndpoint+"RUB"+limits,
+			models.SpendingLimits{Daily: amount("1000")}, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		wallet := newFundedWallet(ctx, "RUB", "5000")
+
+		reqWithdraw := models.FundsOperations{}
+		reqWithdraw.TransactionKey = uuid.New()
+		reqWithdraw.Currency = "RUB"
+		reqWithdraw.Amount = money.MustParse("600")
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+withdraw, reqWithdraw,
+			nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+withdraw, reqWithdraw,
+			nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		reqWithdraw.TransactionKey = uuid.New()
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+withdraw, reqWithdraw,
+			nil)
+		s.Require().Equal(http.StatusPaymentRequired, resp.StatusCode)
+
+		var respLimits models.ResponseRemainingLimits
+
+		resp = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+wallet.WalletID.String()+limits, nil,
+			&respLimits)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal("RUB", respLimits.Currency)
+		s.Require().Len(respLimits.Limits, 3)
+		s.Require().Equal(models.PeriodDaily, respLimits.Limits[0].Period)
+		s.Require().Equal(money.MustParse("600"), respLimits.Limits[0].Spent)
+		s.Require().Equal(amount("400"), respLimits.Limits[0].Remaining)
+		s.Require().Nil(respLimits.Limits[1].Limit)
+		s.Require().Nil(respLimits.Limits[1].Remaining)
+	})
+
+	s.Run("wallet override applies to transfers", func() {
+		ctx := context.Background()
+
+		resp := s.sendAdminRequest(ctx, http.MethodPut, url+currenciesEndpoint+"USD"+limits,
+			models.SpendingLimits{Daily: amount("1000"), Monthly: amount("5000")}, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		srcWallet := newFundedWallet(ctx, "USD", "3000")
+		dstWallet := newFundedWallet(ctx, "USD", "0.01")
+
+		var respOverride models.SpendingLimits
+
+		resp = s.sendAdminRequest(ctx, http.MethodPut, url+adminWalletsEndpoint+srcWallet.WalletID.String()+limits,
+			models.SpendingLimits{Daily: amount("100")}, &respOverride)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(amount("100"), respOverride.Daily)
+
+		reqTransfer := models.FundsOperations{}
+		reqTransfer.TransactionKey = uuid.New()
+		reqTransfer.Currency = "USD"
+		reqTransfer.Amount = money.MustParse("150")
+
+		resp = s.sendRequest(ctx, http.MethodPut,
+			url+walletEndpoint+srcWallet.WalletID.String()+transfer+dstWallet.WalletID.String(), reqTransfer, nil)
+		s.Require().Equal(http.StatusPaymentRequired, resp.StatusCode)
+
+		var respLimits models.ResponseRemainingLimits
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+srcWallet.WalletID.String()+limits, nil,
+			&respLimits)
+
+		s.Require().Equal(amount("100"), respLimits.Limits[0].Limit)
+		s.Require().Equal(amount("5000"), respLimits.Limits[2].Limit)
+	})
+
+	s.Run("captured holds count against the limits", func() {
+		ctx := context.Background()
+
+		resp := s.sendAdminRequest(ctx, http.MethodPut, url+currenciesEndpoint+"RUB"+limits,
+			models.SpendingLimits{Daily: amount("1000")}, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		wallet := newFundedWallet(ctx, "RUB", "5000")
+
+		reqAuthorize := models.RequestAuthorize{}
+		reqAuthorize.TransactionKey = uuid.New()
+		reqAuthorize.Currency = "RUB"
+		reqAuthorize.Amount = money.MustParse("700")
+
+		var respHold models.ResponseHold
+
+		resp = s.sendRequest(ctx, http.MethodPost, url+walletEndpoint+wallet.WalletID.String()+walletHolds,
+			reqAuthorize, &respHold)
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+holdsEndpoint+respHold.HoldID.String()+capture,
+			models.RequestCapture{TransactionKey: uuid.New()}, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		reqWithdraw := models.FundsOperations{}
+		reqWithdraw.TransactionKey = uuid.New()
+		reqWithdraw.Currency = "RUB"
+		reqWithdraw.Amount = money.MustParse("400")
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+withdraw, reqWithdraw,
+			nil)
+		s.Require().Equal(http.StatusPaymentRequired, resp.StatusCode)
+	})
+
+	s.Run("reversed withdrawals do not count against the limits", func() {
+		ctx := context.Background()
+
+		resp := s.sendAdminRequest(ctx, http.MethodPut, url+currenciesEndpoint+"RUB"+limits,
+			models.SpendingLimits{Daily: amount("500")}, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		wallet := newFundedWallet(ctx, "RUB", "5000")
+
+		reqWithdraw := models.FundsOperations{}
+		reqWithdraw.TransactionKey = uuid.New()
+		reqWithdraw.Currency = "RUB"
+		reqWithdraw.Amount = money.MustParse("400")
+
+		var respWithdraw models.ResponseFundsOperation
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+withdraw, reqWithdraw,
+			&respWithdraw)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		resp = s.sendAdminRequest(ctx, http.MethodPut,
+			url+adminTransactionsEndpoint+respWithdraw.TransactionID.String()+reverse,
+			models.RequestReversal{TransactionKey: uuid.New()}, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		reqWithdraw.TransactionKey = uuid.New()
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+withdraw, reqWithdraw,
+			nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+	})
+
+	s.Run("capture over the limits", func() {
+		ctx := context.Background()
+
+		resp := s.sendAdminRequest(ctx, http.MethodPut, url+currenciesEndpoint+"RUB"+limits,
+			models.SpendingLimits{Daily: amount("500")}, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		wallet := newFundedWallet(ctx, "RUB", "5000")
+
+		reqAuthorize := models.RequestAuthorize{}
+		reqAuthorize.TransactionKey = uuid.New()
+		reqAuthorize.Currency = "RUB"
+		reqAuthorize.Amount = money.MustParse("700")
+
+		var respHold models.ResponseHold
+
+		resp = s.sendRequest(ctx, http.MethodPost, url+walletEndpoint+wallet.WalletID.String()+walletHolds,
+			reqAuthorize, &respHold)
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+holdsEndpoint+respHold.HoldID.String()+capture,
+			models.RequestCapture{TransactionKey: uuid.New()}, nil)
+		s.Require().Equal(http.StatusPaymentRequired, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+holdsEndpoint+respHold.HoldID.String()+capture,
+			models.RequestCapture{TransactionKey: uuid.New(), Amount: money.MustParse("500")}, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+	})
+
+	s.Run("set limits not valid", func() {
+		ctx := context.Background()
+
+		resp := s.sendAdminRequest(ctx, http.MethodPut, url+currenciesEndpoint+"USD"+limits,
+			models.SpendingLimits{Daily: amount("-1")}, nil)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		resp = s.sendAdminRequest(ctx, http.MethodPut, url+adminWalletsEndpoint+uuid.New().String()+limits,
+			models.SpendingLimits{Daily: amount("1")}, nil)
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+	})
+}
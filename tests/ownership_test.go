@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+func (s *IntegrationTestSuite) TestWalletOwnership() {
+	newWallet := func(ctx context.Context, subject string) models.ResponseWalletInstance {
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = uuid.New().String()
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = "USD"
+
+		var wallet models.ResponseWalletInstance
+
+		_ = s.sendRequestWithCustomClaims(ctx, http.MethodPost, url+createWalletEndpoint, subject, "", reqWallet,
+			&wallet)
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "USD"
+		reqDeposit.Amount = money.MustParse("100")
+
+		_ = s.sendRequestWithCustomClaims(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+deposit,
+			subject, "", reqDeposit, &wallet)
+
+		return wallet
+	}
+
+	s.Run("wallet of another subject is not accessible", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx, uuid.New().String())
+		walletID := wallet.WalletID.String()
+
+		resp := s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+walletID, nil, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+
+		reqFunds := models.FundsOperations{}
+		reqFunds.TransactionKey = uuid.New()
+		reqFunds.Currency = "USD"
+		reqFunds.Amount = money.MustParse("10")
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletID+withdraw, reqFunds, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+
+		own := newWallet(ctx, s.subject)
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletID+transfer+own.WalletID.String(),
+			reqFunds, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodDelete, url+deleteWalletEndpoint+walletID, nil, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+
+		resp = s.sendAdminRequest(ctx, http.MethodGet, url+walletEndpoint+walletID, nil, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodGet, url+adminWalletsEndpoint+walletID+limits, nil, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+	})
+
+	s.Run("transaction is visible to both wallets", func() {
+		ctx := context.Background()
+
+		receiver := uuid.New().String()
+
+		src := newWallet(ctx, s.subject)
+		dst := newWallet(ctx, receiver)
+
+		reqTransfer := models.FundsOperations{}
+		reqTransfer.TransactionKey = uuid.New()
+		reqTransfer.Currency = "USD"
+		reqTransfer.Amount = money.MustParse("10")
+
+		var respTransfer models.ResponseTransfer
+
+		resp := s.sendRequest(ctx, http.MethodPut,
+			url+walletEndpoint+src.WalletID.String()+transfer+dst.WalletID.String(), reqTransfer, &respTransfer)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		endpoint := url + transactionsEndpoint + respTransfer.TransactionID.String()
+
+		resp = s.sendRequestWithCustomClaims(ctx, http.MethodGet, endpoint, receiver, "", nil, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		resp = s.sendRequestWithCustomClaims(ctx, http.MethodGet, endpoint, uuid.New().String(), "", nil, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+	})
+
+	s.Run("wallets list is scoped to the caller", func() {
+		ctx := context.Background()
+
+		otherSubject := uuid.New().String()
+
+		own := newWallet(ctx, s.subject)
+		other := newWallet(ctx, otherSubject)
+
+		var respList []models.ResponseWalletInstance
+
+		resp := s.sendRequest(ctx, http.MethodGet, url+walletsEndpoint, nil, &respList)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Len(respList, 1)
+		s.Require().Equal(own.WalletID, respList[0].WalletID)
+
+		resp = s.sendAdminRequest(ctx, http.MethodGet, url+adminWalletsEndpoint+"?ownerId="+otherSubject, nil,
+			&respList)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Len(respList, 1)
+		s.Require().Equal(other.WalletID, respList[0].WalletID)
+	})
+
+	s.Run("unowned wallet is claimed by the subject with its email", func() {
+		ctx := context.Background()
+
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = uuid.New().String()
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = "USD"
+
+		wallet, err := s.walletService.CreateWallet(ctx, reqWallet)
+		s.Require().NoError(err)
+
+		endpoint := url + walletEndpoint + wallet.WalletID.String()
+		claimant := uuid.New().String()
+
+		resp := s.sendRequestWithCustomClaims(ctx, http.MethodGet, endpoint, claimant, uuid.New().String(), nil, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+
+		var respList []models.ResponseWalletInstance
+
+		resp = s.sendRequestWithCustomClaims(ctx, http.MethodGet, url+walletsEndpoint, claimant, reqWallet.Email,
+			nil, &respList)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Len(respList, 1)
+		s.Require().Equal(wallet.WalletID, respList[0].WalletID)
+
+		resp = s.sendRequestWithCustomClaims(ctx, http.MethodGet, endpoint, claimant, reqWallet.Email, nil, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		resp = s.sendRequestWithCustomClaims(ctx, http.MethodGet, endpoint, uuid.New().String(), reqWallet.Email,
+			nil, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+
+		resp = s.sendRequestWithCustomClaims(ctx, http.MethodGet, endpoint, claimant, "", nil, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+	})
+}
@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+func (s *IntegrationTestSuite) TestQuotes() {
+	newWallet := func(ctx context.Context) models.ResponseWalletInstance {
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = uuid.New().String()
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = "USD"
+
+		var wallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqWallet, &wallet)
+
+		return wallet
+	}
+
+	createQuote := func(ctx context.Context, request models.RequestQuote) (*http.Response, models.Quote) {
+		var quote models.Quote
+
+		resp := s.sendRequest(ctx, http.MethodPost, url+quotesEndpoint, request, &quote)
+
+		return resp, quote
+	}
+
+	depositFunds := func(ctx context.Context, id uuid.UUID, amount string, quoteID uuid.UUID) (
+		*http.Response, models.ResponseFundsOperation,
+	) {
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "EUR"
+		reqDeposit.Amount = money.MustParse(amount)
+		reqDeposit.QuoteID = uuid.NullUUID{UUID: quoteID, Valid: true}
+
+		var respDeposit models.ResponseFundsOperation
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+id.String()+deposit, reqDeposit, &respDeposit)
+
+		return resp, respDeposit
+	}
+
+	s.Run("deposit at quoted rate", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx)
+
+		resp, quote := createQuote(ctx, models.RequestQuote{
+			Currency:       "EUR",
+			Amount:         money.MustParse("100"),
+			TargetCurrency: "USD",
+			Side:           models.SideBid,
+		})
+
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+		s.Require().False(quote.ConvertedAmount.IsZero())
+		s.Require().True(quote.ExpiresAt.After(time.Now()))
+
+		resp, respDeposit := depositFunds(ctx, wallet.WalletID, "100", quote.QuoteID)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(quote.ConvertedAmount, respDeposit.Balance)
+
+		var respTransaction models.Transaction
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+transactionsEndpoint+respDeposit.TransactionID.String(), nil,
+			&respTransaction)
+
+		s.Require().Equal(quote.Rate, respTransaction.Destination.AppliedRate.Rate)
+
+		var respQuote models.Quote
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+quotesEndpoint+"/"+quote.QuoteID.String(), nil, &respQuote)
+
+		s.Require().Equal(respDeposit.TransactionID, respQuote.TransactionID.UUID)
+
+		resp, _ = depositFunds(ctx, wallet.WalletID, "100", quote.QuoteID)
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+	})
+
+	s.Run("repeated quoted deposit replays its response", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx)
+
+		_, quote := createQuote(ctx, models.RequestQuote{
+			Currency:       "EUR",
+			Amount:         money.MustParse("100"),
+			TargetCurrency: "USD",
+			Side:           models.SideBid,
+		})
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "EUR"
+		reqDeposit.Amount = money.MustParse("100")
+		reqDeposit.QuoteID = uuid.NullUUID{UUID: quote.QuoteID, Valid: true}
+
+		var first, second models.ResponseFundsOperation
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+deposit, reqDeposit,
+			&first)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+deposit, reqDeposit,
+			&second)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(first, second)
+	})
+
+	s.Run("quote not matching or expired", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx)
+
+		_, quote := createQuote(ctx, models.RequestQuote{
+			Currency:       "EUR",
+			Amount:         money.MustParse("100"),
+			TargetCurrency: "USD",
+			Side:           models.SideBid,
+		})
+
+		resp, _ := depositFunds(ctx, wallet.WalletID, "50", quote.QuoteID)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		quote.QuoteID = uuid.New()
+		quote.ExpiresAt = time.Now().Add(-time.Second)
+
+		_, err := s.pg.CreateQuote(ctx, quote)
+		s.Require().NoError(err)
+
+		resp, _ = depositFunds(ctx, wallet.WalletID, "100", quote.QuoteID)
+		s.Require().Equal(http.StatusGone, resp.StatusCode)
+
+		resp, _ = depositFunds(ctx, wallet.WalletID, "100", uuid.New())
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+	})
+
+	s.Run("create quote not valid", func() {
+		ctx := context.Background()
+
+		request := models.RequestQuote{
+			Currency:       "USD",
+			Amount:         money.MustParse("100"),
+			TargetCurrency: "USD",
+			Side:           models.SideBid,
+		}
+
+		resp, _ := createQuote(ctx, request)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		request.Currency = "EUR"
+		request.Side = "MID"
+
+		resp, _ = createQuote(ctx, request)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		request.Side = models.SideAsk
+		request.TargetCurrency = "XXX"
+
+		resp, _ = createQuote(ctx, request)
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/google/uuid"
+)
+
+func (s *IntegrationTestSuite) TestRestoreWallet() {
+	newWallet := func(ctx context.Context, email string) (*http.Response, models.ResponseWalletInstance) {
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = email
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = "USD"
+
+		var wallet models.ResponseWalletInstance
+
+		resp := s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqWallet, &wallet)
+
+		return resp, wallet
+	}
+
+	restore := func(ctx context.Context, walletID uuid.UUID, reason string) (
+		*http.Response, models.ResponseWalletInstance,
+	) {
+		var wallet models.ResponseWalletInstance
+
+		resp := s.sendAdminRequest(ctx, http.MethodPut, url+adminWalletsEndpoint+walletID.String()+walletRestore,
+			models.RequestWalletRestore{Reason: reason}, &wallet)
+
+		return resp, wallet
+	}
+
+	s.Run("restore deleted wallet", func() {
+		ctx := context.Background()
+
+		_, wallet := newWallet(ctx, uuid.New().String())
+
+		resp := s.sendRequest(ctx, http.MethodDelete, url+deleteWalletEndpoint+wallet.WalletID.String(), nil, nil)
+		s.Require().Equal(http.StatusNoContent, resp.StatusCode)
+
+		resp, _ = restore(ctx, wallet.WalletID, "")
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		resp, respWallet := restore(ctx, wallet.WalletID, "Closed by mistake")
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(models.WalletActive, respWallet.Status)
+
+		resp = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+wallet.WalletID.String(), nil, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		resp, _ = restore(ctx, wallet.WalletID, "Closed by mistake")
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+	})
+
+	s.Run("email is reused after delete", func() {
+		ctx := context.Background()
+
+		email := uuid.New().String()
+
+		_, wallet := newWallet(ctx, email)
+
+		resp, _ := newWallet(ctx, email)
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+
+		_ = s.sendRequest(ctx, http.MethodDelete, url+deleteWalletEndpoint+wallet.WalletID.String(), nil, nil)
+
+		resp, newerWallet := newWallet(ctx, email)
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+		resp, _ = restore(ctx, wallet.WalletID, "Closed by mistake")
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+
+		_ = s.sendRequest(ctx, http.MethodDelete, url+deleteWalletEndpoint+newerWallet.WalletID.String(), nil, nil)
+
+		resp, _ = restore(ctx, wallet.WalletID, "Closed by mistake")
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+	})
+
+	s.Run("purged wallet cannot be restored", func() {
+		ctx := context.Background()
+
+		_, wallet := newWallet(ctx, uuid.New().String())
+
+		_ = s.sendRequest(ctx, http.MethodDelete, url+deleteWalletEndpoint+wallet.WalletID.String(), nil, nil)
+
+		purged, err := s.pg.PurgeDeletedWallets(ctx, 0)
+		s.Require().NoError(err)
+		s.Require().Positive(purged)
+
+		resp, _ := restore(ctx, wallet.WalletID, "Closed by mistake")
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+
+		var respHistory []models.ResponseWalletHistory
+
+		resp = s.sendRequestWithCustomClaims(ctx, http.MethodGet, url+walletHistoryEndpoint, s.subject,
+			"go-dev@mail.go", nil, &respHistory)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().NotEmpty(respHistory)
+
+		for _, entry := range respHistory {
+			s.Require().Empty(entry.Email)
+			s.Require().Empty(entry.Owner)
+		}
+	})
+}
@@ -0,0 +1,252 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+func (s *IntegrationTestSuite) TestReverseTransaction() {
+	newWallet := func(ctx context.Context, amount string) models.ResponseWalletInstance {
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = uuid.New().String()
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = "USD"
+
+		var wallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqWallet, &wallet)
+
+		if amount == "" {
+			return wallet
+		}
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "USD"
+		reqDeposit.Amount = money.MustParse(amount)
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+deposit, reqDeposit,
+			&wallet)
+
+		return wallet
+	}
+
+	transferFunds := func(ctx context.Context, src, dst uuid.UUID, amount string) uuid.UUID {
+		reqTransfer := models.FundsOperations{}
+		reqTransfer.TransactionKey = uuid.New()
+		reqTransfer.Currency = "USD"
+		reqTransfer.Amount = money.MustParse(amount)
+
+		var respTransfer models.ResponseTransfer
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+src.String()+transfer+dst.String(), reqTransfer,
+			&respTransfer)
+
+		return respTransfer.TransactionID
+	}
+
+	reverseTransaction := func(ctx context.Context, id uuid.UUID, amount string) (
+		*http.Response, models.ResponseReversal,
+	) {
+		reqReversal := models.RequestReversal{TransactionKey: uuid.New()}
+
+		if amount != "" {
+			reqReversal.Amount = money.MustParse(amount)
+		}
+
+		var respReversal models.ResponseReversal
+
+		resp := s.sendAdminRequest(ctx, http.MethodPut, url+adminTransactionsEndpoint+id.String()+reverse, reqReversal,
+			&respReversal)
+
+		return resp, respReversal
+	}
+
+	balance := func(ctx context.Context, id uuid.UUID) money.Amount {
+		var wallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+id.String(), nil, &wallet)
+
+		return wallet.Balance
+	}
+
+	s.Run("full reversal of transfer", func() {
+		ctx := context.Background()
+
+		src := newWallet(ctx, "100")
+		dst := newWallet(ctx, "")
+
+		transactionID := transferFunds(ctx, src.WalletID, dst.WalletID, "40")
+
+		resp, respReversal := reverseTransaction(ctx, transactionID, "")
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal("REVERSAL", respReversal.Reversal.OperationType)
+		s.Require().Equal(transactionID, respReversal.Reversal.ReversalOf.UUID)
+		s.Require().Equal(dst.WalletID, respReversal.Reversal.Source.WalletID)
+		s.Require().Equal(src.WalletID, respReversal.Reversal.Destination.WalletID)
+		s.Require().Equal(models.TransactionReversed, respReversal.Original.Status)
+		s.Require().Equal(money.MustParse("40"), respReversal.Original.ReversedAmount)
+
+		s.Require().Equal(money.MustParse("100"), balance(ctx, src.WalletID))
+		s.Require().True(balance(ctx, dst.WalletID).IsZero())
+
+		ledgerBalance, err := s.pg.GetLedgerBalance(ctx, src.WalletID.String())
+		s.Require().NoError(err)
+		s.Require().Equal(money.MustParse("100"), ledgerBalance)
+
+		resp, _ = reverseTransaction(ctx, transactionID, "")
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+
+		resp, _ = reverseTransaction(ctx, respReversal.Reversal.TransactionID, "")
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		var respTransactions []models.Transaction
+
+		_ = s.sendRequest(ctx, http.MethodGet,
+			url+walletEndpoint+dst.WalletID.String()+walletTransactions+"?type=REVERSAL", nil, &respTransactions)
+
+		s.Require().Len(respTransactions, 1)
+		s.Require().Equal(transactionID, respTransactions[0].ReversalOf.UUID)
+	})
+
+	s.Run("partial reversals", func() {
+		ctx := context.Background()
+
+		src := newWallet(ctx, "100")
+		dst := newWallet(ctx, "")
+
+		transactionID := transferFunds(ctx, src.WalletID, dst.WalletID, "40")
+
+		resp, respReversal := reverseTransaction(ctx, transactionID, "15")
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(models.TransactionPartiallyReversed, respReversal.Original.Status)
+		s.Require().Equal(money.MustParse("25"), balance(ctx, dst.WalletID))
+
+		resp, _ = reverseTransaction(ctx, transactionID, "30")
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		resp, _ = reverseTransaction(ctx, transactionID, "0.001")
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+
+		resp, respReversal = reverseTransaction(ctx, transactionID, "")
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(money.MustParse("25"), respReversal.Reversal.Amount)
+		s.Require().Equal(models.TransactionReversed, respReversal.Original.Status)
+		s.Require().Equal(money.MustParse("100"), balance(ctx, src.WalletID))
+	})
+
+	s.Run("partial reversals of a converted transfer", func() {
+		ctx := context.Background()
+
+		src := newWallet(ctx, "100")
+
+		reqDstWallet := models.RequestWalletInstance{}
+		reqDstWallet.TransactionKey = uuid.New()
+		reqDstWallet.Email = uuid.New().String()
+		reqDstWallet.Owner = "Liza"
+		reqDstWallet.Currency = "EUR"
+
+		var dst models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqDstWallet, &dst)
+
+		transactionID := transferFunds(ctx, src.WalletID, dst.WalletID, "10")
+
+		for _, amount := range []string{"3.33", "3.33", "3.34"} {
+			resp, _ := reverseTransaction(ctx, transactionID, amount)
+			s.Require().Equal(http.StatusOK, resp.StatusCode)
+		}
+
+		s.Require().True(balance(ctx, dst.WalletID).IsZero())
+		s.Require().Equal(money.MustParse("100"), balance(ctx, src.WalletID))
+	})
+
+	s.Run("reversal after a currency change", func() {
+		ctx := context.Background()
+
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = uuid.New().String()
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = "USD"
+
+		var wallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqWallet, &wallet)
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "USD"
+		reqDeposit.Amount = money.MustParse("50")
+
+		var respDeposit models.ResponseFundsOperation
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+deposit, reqDeposit,
+			&respDeposit)
+
+		reqUpdate := reqWallet
+		reqUpdate.TransactionKey = uuid.New()
+		reqUpdate.Currency = "EUR"
+
+		resp := s.sendRequest(ctx, http.MethodPatch, url+updateWalletEndpoint+wallet.WalletID.String(), reqUpdate,
+			&wallet)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		resp, _ = reverseTransaction(ctx, respDeposit.TransactionID, "")
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+		s.Require().False(balance(ctx, wallet.WalletID).IsZero())
+	})
+
+	s.Run("reversal of spent funds", func() {
+		ctx := context.Background()
+
+		src := newWallet(ctx, "100")
+		dst := newWallet(ctx, "")
+		other := newWallet(ctx, "")
+
+		transactionID := transferFunds(ctx, src.WalletID, dst.WalletID, "40")
+		_ = transferFunds(ctx, dst.WalletID, other.WalletID, "30")
+
+		resp, _ := reverseTransaction(ctx, transactionID, "")
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		resp, _ = reverseTransaction(ctx, transactionID, "10")
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		s.Require().True(balance(ctx, dst.WalletID).IsZero())
+		s.Require().Equal(money.MustParse("70"), balance(ctx, src.WalletID))
+	})
+
+	s.Run("reversal of deposit", func() {
+		ctx := context.Background()
+
+		wallet := newWallet(ctx, "")
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "USD"
+		reqDeposit.Amount = money.MustParse("50")
+
+		var respDeposit models.ResponseFundsOperation
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+deposit, reqDeposit,
+			&respDeposit)
+
+		resp, respReversal := reverseTransaction(ctx, respDeposit.TransactionID, "")
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Nil(respReversal.Reversal.Destination)
+		s.Require().True(balance(ctx, wallet.WalletID).IsZero())
+
+		resp, _ = reverseTransaction(ctx, uuid.New(), "")
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+func (s *IntegrationTestSuite) TestRoles() {
+	newFundedWallet := func(ctx context.Context) models.ResponseWalletInstance {
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = uuid.New().String()
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = "USD"
+
+		var wallet models.ResponseWalletInstance
+
+		_ = s.sendRequestWithCustomClaims(ctx, http.MethodPost, url+createWalletEndpoint, uuid.New().String(), "",
+			reqWallet, &wallet)
+
+		reqAdjustment := models.RequestAdjustment{
+			TransactionKey: uuid.New(),
+			Direction:      models.Credit,
+			Amount:         money.MustParse("100"),
+			Currency:       "USD",
+			Reason:         "Opening balance",
+		}
+
+		_ = s.sendAdminRequest(ctx, http.MethodPost, url+adminWalletsEndpoint+wallet.WalletID.String()+adjustments,
+			reqAdjustment, &wallet)
+
+		return wallet
+	}
+
+	s.Run("auditor has read-only access", func() {
+		ctx := context.Background()
+
+		wallet := newFundedWallet(ctx)
+		auditor := []string{models.RoleAuditor}
+
+		resp := s.sendRequestWithRoles(ctx, http.MethodGet, url+walletEndpoint+wallet.WalletID.String(), auditor,
+			nil, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		resp = s.sendRequestWithRoles(ctx, http.MethodGet, url+adminWalletsEndpoint, auditor, nil, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		reqFunds := models.FundsOperations{}
+		reqFunds.TransactionKey = uuid.New()
+		reqFunds.Currency = "USD"
+		reqFunds.Amount = money.MustParse("10")
+
+		resp = s.sendRequestWithRoles(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+withdraw,
+			auditor, reqFunds, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+
+		resp = s.sendRequestWithRoles(ctx, http.MethodPut,
+			url+adminWalletsEndpoint+wallet.WalletID.String()+walletStatus, auditor,
+			models.RequestWalletStatus{Status: models.WalletFrozen, Reason: "Audit"}, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = uuid.New().String()
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = "USD"
+
+		resp = s.sendRequestWithRoles(ctx, http.MethodPost, url+createWalletEndpoint, auditor, reqWallet, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+	})
+
+	s.Run("support changes status but cannot adjust", func() {
+		ctx := context.Background()
+
+		wallet := newFundedWallet(ctx)
+		support := []string{models.RoleSupport}
+
+		var respWallet models.ResponseWalletInstance
+
+		resp := s.sendRequestWithRoles(ctx, http.MethodPut,
+			url+adminWalletsEndpoint+wallet.WalletID.String()+walletStatus, support,
+			models.RequestWalletStatus{Status: models.WalletFrozen, Reason: "Suspicious activity"}, &respWallet)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(models.WalletFrozen, respWallet.Status)
+
+		reqAdjustment := models.RequestAdjustment{
+			TransactionKey: uuid.New(),
+			Direction:      models.Credit,
+			Amount:         money.MustParse("10"),
+			Currency:       "USD",
+			Reason:         "Goodwill",
+		}
+
+		resp = s.sendRequestWithRoles(ctx, http.MethodPost,
+			url+adminWalletsEndpoint+wallet.WalletID.String()+adjustments, support, reqAdjustment, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+	})
+
+	s.Run("admin adjusts balance", func() {
+		ctx := context.Background()
+
+		wallet := newFundedWallet(ctx)
+		s.Require().Equal(money.MustParse("100"), wallet.Balance)
+
+		reqAdjustment := models.RequestAdjustment{
+			TransactionKey: uuid.New(),
+			Direction:      models.Debit,
+			Amount:         money.MustParse("30"),
+			Currency:       "USD",
+			Reason:         "Duplicate deposit",
+		}
+
+		var respAdjustment models.ResponseFundsOperation
+
+		resp := s.sendAdminRequest(ctx, http.MethodPost,
+			url+adminWalletsEndpoint+wallet.WalletID.String()+adjustments, reqAdjustment, &respAdjustment)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(money.MustParse("70"), respAdjustment.Balance)
+
+		var respTransaction models.Transaction
+
+		_ = s.sendAdminRequest(ctx, http.MethodGet,
+			url+transactionsEndpoint+respAdjustment.TransactionID.String(), nil, &respTransaction)
+
+		s.Require().Equal("ADJUSTMENT", respTransaction.OperationType)
+		s.Require().Equal("Duplicate deposit", *respTransaction.Reason)
+
+		ledgerBalance, err := s.pg.GetLedgerBalance(ctx, wallet.WalletID.String())
+		s.Require().NoError(err)
+		s.Require().Equal(respAdjustment.Balance, ledgerBalance)
+
+		reqAdjustment.TransactionKey = uuid.New()
+		reqAdjustment.Reason = ""
+
+		resp = s.sendAdminRequest(ctx, http.MethodPost,
+			url+adminWalletsEndpoint+wallet.WalletID.String()+adjustments, reqAdjustment, nil)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+	})
+}
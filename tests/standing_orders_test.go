@@ -0,0 +1,233 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/AlexZav1327/service/internal/postgres"
+	"github.com/google/uuid"
+)
+
+func (s *IntegrationTestSuite) TestStandingOrders() {
+	newWallet := func(ctx context.Context, amount string) models.ResponseWalletInstance {
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = uuid.New().String()
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = "USD"
+
+		var wallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqWallet, &wallet)
+
+		if amount == "" {
+			return wallet
+		}
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "USD"
+		reqDeposit.Amount = money.MustParse(amount)
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+deposit, reqDeposit,
+			&wallet)
+
+		return wallet
+	}
+
+	createOrder := func(ctx context.Context, srcID uuid.UUID, request models.RequestStandingOrder) (
+		*http.Response, models.StandingOrder,
+	) {
+		var order models.StandingOrder
+
+		resp := s.sendRequest(ctx, http.MethodPost, url+walletEndpoint+srcID.String()+walletStandingOrders, request,
+			&order)
+
+		return resp, order
+	}
+
+	s.Run("one-off order runs once", func() {
+		ctx := context.Background()
+
+		src := newWallet(ctx, "100")
+		dst := newWallet(ctx, "")
+
+		resp, order := createOrder(ctx, src.WalletID, models.RequestStandingOrder{
+			DstWalletID: dst.WalletID,
+			Amount:      money.MustParse("30"),
+			Currency:    "USD",
+			Frequency:   models.FrequencyOnce,
+		})
+
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+		s.Require().Equal(models.StandingOrderActive, order.Status)
+
+		_, err := s.walletService.ExecuteDueStandingOrders(ctx)
+		s.Require().NoError(err)
+
+		_, err = s.walletService.ExecuteDueStandingOrders(ctx)
+		s.Require().NoError(err)
+
+		var respOrder models.StandingOrder
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+standingOrdersEndpoint+order.OrderID.String(), nil, &respOrder)
+
+		s.Require().Equal(models.StandingOrderCompleted, respOrder.Status)
+		s.Require().Equal(1, respOrder.Runs)
+
+		var respExecutions []models.StandingOrderExecution
+
+		resp = s.sendRequest(ctx, http.MethodGet, url+standingOrdersEndpoint+order.OrderID.String()+executions, nil,
+			&respExecutions)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Len(respExecutions, 1)
+		s.Require().Equal(models.ExecutionSucceeded, respExecutions[0].Status)
+		s.Require().True(respExecutions[0].TransactionID.Valid)
+
+		var respTransaction models.Transaction
+
+		_ = s.sendRequest(ctx, http.MethodGet,
+			url+transactionsEndpoint+respExecutions[0].TransactionID.UUID.String(), nil, &respTransaction)
+
+		s.Require().Equal("TRANSFER", respTransaction.OperationType)
+		s.Require().Equal(respExecutions[0].TransactionKey, respTransaction.TransactionKey.UUID)
+
+		var respWallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+dst.WalletID.String(), nil, &respWallet)
+
+		s.Require().Equal(money.MustParse("30"), respWallet.Balance)
+	})
+
+	s.Run("failed run is recorded and rescheduled", func() {
+		ctx := context.Background()
+
+		src := newWallet(ctx, "")
+		dst := newWallet(ctx, "")
+
+		_, order := createOrder(ctx, src.WalletID, models.RequestStandingOrder{
+			DstWalletID: dst.WalletID,
+			Amount:      money.MustParse("30"),
+			Currency:    "USD",
+			Frequency:   models.FrequencyMonthly,
+		})
+
+		_, err := s.walletService.ExecuteDueStandingOrders(ctx)
+		s.Require().NoError(err)
+
+		var respOrder models.StandingOrder
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+standingOrdersEndpoint+order.OrderID.String(), nil, &respOrder)
+
+		s.Require().Equal(models.StandingOrderActive, respOrder.Status)
+		s.Require().Equal(1, respOrder.Runs)
+		s.Require().True(respOrder.NextRunAt.After(time.Now().AddDate(0, 0, 27)))
+
+		var respExecutions []models.StandingOrderExecution
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+standingOrdersEndpoint+order.OrderID.String()+executions, nil,
+			&respExecutions)
+
+		s.Require().Len(respExecutions, 1)
+		s.Require().Equal(models.ExecutionFailed, respExecutions[0].Status)
+		s.Require().False(respExecutions[0].TransactionID.Valid)
+		s.Require().Equal(postgres.ErrOverdraft.Error(), respExecutions[0].Error)
+	})
+
+	s.Run("update and cancel order", func() {
+		ctx := context.Background()
+
+		src := newWallet(ctx, "100")
+		dst := newWallet(ctx, "")
+
+		request := models.RequestStandingOrder{
+			DstWalletID: dst.WalletID,
+			Amount:      money.MustParse("30"),
+			Currency:    "USD",
+			Frequency:   models.FrequencyWeekly,
+			StartAt:     time.Now().Add(time.Hour),
+		}
+
+		_, order := createOrder(ctx, src.WalletID, request)
+
+		request.Amount = money.MustParse("40")
+		request.StartAt = time.Time{}
+
+		var respOrder models.StandingOrder
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+standingOrdersEndpoint+order.OrderID.String(), request,
+			&respOrder)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(money.MustParse("40"), respOrder.Amount)
+		s.Require().True(order.NextRunAt.Equal(respOrder.NextRunAt))
+
+		var respOrders []models.StandingOrder
+
+		resp = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+src.WalletID.String()+walletStandingOrders, nil,
+			&respOrders)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Len(respOrders, 1)
+
+		var respExecutions []models.StandingOrderExecution
+
+		resp = s.sendRequest(ctx, http.MethodGet, url+standingOrdersEndpoint+order.OrderID.String()+executions, nil,
+			&respExecutions)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().NotNil(respExecutions)
+		s.Require().Empty(respExecutions)
+
+		resp = s.sendRequest(ctx, http.MethodDelete, url+standingOrdersEndpoint+order.OrderID.String(), nil, nil)
+		s.Require().Equal(http.StatusNoContent, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodDelete, url+standingOrdersEndpoint+order.OrderID.String(), nil, nil)
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+standingOrdersEndpoint+order.OrderID.String(), request, nil)
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+	})
+
+	s.Run("create order not valid", func() {
+		ctx := context.Background()
+
+		src := newWallet(ctx, "100")
+		dst := newWallet(ctx, "")
+
+		request := models.RequestStandingOrder{
+			DstWalletID: src.WalletID,
+			Amount:      money.MustParse("30"),
+			Currency:    "USD",
+			Frequency:   models.FrequencyWeekly,
+		}
+
+		resp, _ := createOrder(ctx, src.WalletID, request)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		request.DstWalletID = dst.WalletID
+		request.StartAt = time.Now().Add(-time.Hour)
+
+		resp, _ = createOrder(ctx, src.WalletID, request)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		request.StartAt = time.Time{}
+		request.Frequency = "DAILY"
+
+		resp, _ = createOrder(ctx, src.WalletID, request)
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		request.Frequency = models.FrequencyWeekly
+		request.DstWalletID = uuid.New()
+
+		resp, _ = createOrder(ctx, src.WalletID, request)
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodGet, url+standingOrdersEndpoint+uuid.New().String(), nil, nil)
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
+	"github.com/google/uuid"
+)
+
+func (s *IntegrationTestSuite) TestWalletStatus() {
+	newFundedWallet := func(ctx context.Context) models.ResponseWalletInstance {
+		reqWallet := models.RequestWalletInstance{}
+		reqWallet.TransactionKey = uuid.New()
+		reqWallet.Email = uuid.New().String()
+		reqWallet.Owner = "Alex"
+		reqWallet.Currency = "USD"
+
+		var wallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqWallet, &wallet)
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "USD"
+		reqDeposit.Amount = money.MustParse("100")
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+deposit, reqDeposit,
+			&wallet)
+
+		return wallet
+	}
+
+	changeStatus := func(ctx context.Context, walletID uuid.UUID, status, reason string) (
+		*http.Response, models.ResponseWalletInstance,
+	) {
+		var wallet models.ResponseWalletInstance
+
+		resp := s.sendAdminRequest(ctx, http.MethodPut, url+adminWalletsEndpoint+walletID.String()+walletStatus,
+			models.RequestWalletStatus{Status: status, Reason: reason}, &wallet)
+
+		return resp, wallet
+	}
+
+	s.Run("frozen wallet receives but cannot send", func() {
+		ctx := context.Background()
+
+		wallet := newFundedWallet(ctx)
+		s.Require().Equal(models.WalletActive, wallet.Status)
+
+		resp, respWallet := changeStatus(ctx, wallet.WalletID, models.WalletFrozen, "Sanctions screening")
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(models.WalletFrozen, respWallet.Status)
+
+		reqFunds := models.FundsOperations{}
+		reqFunds.TransactionKey = uuid.New()
+		reqFunds.Currency = "USD"
+		reqFunds.Amount = money.MustParse("10")
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+deposit, reqFunds, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		reqFunds.TransactionKey = uuid.New()
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+withdraw, reqFunds, nil)
+		s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+wallet.WalletID.String(), nil, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		resp = s.sendRequest(ctx, http.MethodDelete, url+deleteWalletEndpoint+wallet.WalletID.String(), nil, nil)
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+
+		resp, _ = changeStatus(ctx, wallet.WalletID, models.WalletClosed, "Customer request")
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+
+		resp, respWallet = changeStatus(ctx, wallet.WalletID, models.WalletActive, "Screening cleared")
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(models.WalletActive, respWallet.Status)
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+wallet.WalletID.String()+withdraw, reqFunds, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+	})
+
+	s.Run("status changes are recorded in history", func() {
+		ctx := context.Background()
+
+		wallet := newFundedWallet(ctx)
+
+		_, _ = changeStatus(ctx, wallet.WalletID, models.WalletFrozen, "Sanctions screening")
+		_, _ = changeStatus(ctx, wallet.WalletID, models.WalletActive, "Screening cleared")
+
+		var respHistory []models.ResponseWalletHistory
+
+		resp := s.sendRequestWithCustomClaims(ctx, http.MethodGet, url+walletHistoryEndpoint, s.subject,
+			"go-dev@mail.go", nil, &respHistory)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Len(respHistory, 4)
+
+		reasons := make(map[string]string)
+
+		for _, entry := range respHistory {
+			if entry.Operation == "STATUS" {
+				s.Require().NotNil(entry.Reason)
+				reasons[entry.Status] = *entry.Reason
+			}
+		}
+
+		s.Require().Equal(map[string]string{
+			models.WalletFrozen: "Sanctions screening",
+			models.WalletActive: "Screening cleared",
+		}, reasons)
+	})
+
+	s.Run("change status not valid", func() {
+		ctx := context.Background()
+
+		wallet := newFundedWallet(ctx)
+
+		resp, _ := changeStatus(ctx, wallet.WalletID, "SUSPENDED", "Unknown")
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		resp, _ = changeStatus(ctx, wallet.WalletID, models.WalletFrozen, "")
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		resp, _ = changeStatus(ctx, uuid.New(), models.WalletFrozen, "Sanctions screening")
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -2,9 +2,11 @@ package tests
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 
 	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
 	"github.com/google/uuid"
 )
 
@@ -25,7 +27,7 @@ func (s *IntegrationTestSuite) TestDeposit() {
 		reqDeposit := models.FundsOperations{}
 		reqDeposit.TransactionKey = uuid.New()
 		reqDeposit.Currency = "RUB"
-		reqDeposit.Amount = 1000
+		reqDeposit.Amount = money.MustParse("1000")
 
 		walletIdEndpoint := respData.WalletID.String()
 		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, &respData)
@@ -50,7 +52,7 @@ func (s *IntegrationTestSuite) TestDeposit() {
 		reqDeposit := models.FundsOperations{}
 		reqDeposit.TransactionKey = uuid.New()
 		reqDeposit.Currency = "RUB"
-		reqDeposit.Amount = 1000
+		reqDeposit.Amount = money.MustParse("1000")
 
 		walletIdEndpoint := respData.WalletID.String()
 		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, &respData)
@@ -59,10 +61,13 @@ func (s *IntegrationTestSuite) TestDeposit() {
 		reqDeposit.Currency = "EUR"
 		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, &respData)
 
-		convertedFunds, _ := s.walletService.ConvertCurrency(ctx, reqDeposit.Currency, req.Currency, reqDeposit.Amount)
+		convertedFunds, _ := s.walletService.ConvertCurrency(ctx, models.SideBid, reqDeposit.Currency, req.Currency,
+			reqDeposit.Amount)
 
 		s.Require().Equal(http.StatusOK, resp.StatusCode)
-		s.Require().Equal(reqDeposit.Amount+convertedFunds, respData.Balance)
+		expectedBalance, err := reqDeposit.Amount.Add(convertedFunds)
+		s.Require().NoError(err)
+		s.Require().Equal(expectedBalance, respData.Balance)
 	})
 
 	s.Run("deposit funds non-idempotent request", func() {
@@ -81,7 +86,7 @@ func (s *IntegrationTestSuite) TestDeposit() {
 		reqDeposit := models.FundsOperations{}
 		reqDeposit.TransactionKey = req.TransactionKey
 		reqDeposit.Currency = "RUB"
-		reqDeposit.Amount = 1000
+		reqDeposit.Amount = money.MustParse("1000")
 
 		walletIdEndpoint := respData.WalletID.String()
 		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, nil)
@@ -105,7 +110,7 @@ func (s *IntegrationTestSuite) TestDeposit() {
 		reqDeposit := models.FundsOperations{}
 		reqDeposit.TransactionKey = uuid.New()
 		reqDeposit.Currency = "XYZ"
-		reqDeposit.Amount = 1000
+		reqDeposit.Amount = money.MustParse("1000")
 
 		walletIdEndpoint := respData.WalletID.String()
 		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, nil)
@@ -129,13 +134,73 @@ func (s *IntegrationTestSuite) TestDeposit() {
 		reqDeposit := models.FundsOperations{}
 		reqDeposit.TransactionKey = uuid.New()
 		reqDeposit.Currency = "EUR"
-		reqDeposit.Amount = 0
+		reqDeposit.Amount = money.MustParse("0")
 
 		walletIdEndpoint := respData.WalletID.String()
 		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, nil)
 
 		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
 	})
+
+	s.Run("deposit funds balance overflow", func() {
+		ctx := context.Background()
+
+		req := models.RequestWalletInstance{}
+		req.TransactionKey = uuid.New()
+		req.Email = uuid.New().String()
+		req.Owner = "Alex"
+		req.Currency = "USD"
+
+		var respData models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, req, &respData)
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "USD"
+		reqDeposit.Amount = money.MustParse("9999999999.99")
+
+		walletIdEndpoint := respData.WalletID.String()
+		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, nil)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Amount = money.MustParse("0.01")
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, nil)
+
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+		_ = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+walletIdEndpoint, nil, &respData)
+
+		s.Require().Equal(money.MustParse("9999999999.99"), respData.Balance)
+	})
+
+	s.Run("deposit funds too many decimal places", func() {
+		ctx := context.Background()
+
+		req := models.RequestWalletInstance{}
+		req.TransactionKey = uuid.New()
+		req.Email = uuid.New().String()
+		req.Owner = "Alex"
+		req.Currency = "USD"
+
+		var respData models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, req, &respData)
+
+		reqDeposit := map[string]any{
+			"transactionKey": uuid.New(),
+			"currency":       "USD",
+			"amount":         json.Number("10.005"),
+		}
+
+		walletIdEndpoint := respData.WalletID.String()
+		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, nil)
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+	})
 }
 
 func (s *IntegrationTestSuite) TestWithdraw() {
@@ -155,7 +220,7 @@ func (s *IntegrationTestSuite) TestWithdraw() {
 		reqDeposit := models.FundsOperations{}
 		reqDeposit.TransactionKey = uuid.New()
 		reqDeposit.Currency = "RUB"
-		reqDeposit.Amount = 1000
+		reqDeposit.Amount = money.MustParse("1000")
 
 		walletIdEndpoint := respData.WalletID.String()
 		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, nil)
@@ -163,12 +228,14 @@ func (s *IntegrationTestSuite) TestWithdraw() {
 		reqWithdraw := models.FundsOperations{}
 		reqWithdraw.TransactionKey = uuid.New()
 		reqWithdraw.Currency = "RUB"
-		reqWithdraw.Amount = 200
+		reqWithdraw.Amount = money.MustParse("200")
 
 		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+withdraw, reqWithdraw, &respData)
 
 		s.Require().Equal(http.StatusOK, resp.StatusCode)
-		s.Require().Equal(reqDeposit.Amount-reqWithdraw.Amount, respData.Balance)
+		expectedBalance, err := reqDeposit.Amount.Sub(reqWithdraw.Amount)
+		s.Require().NoError(err)
+		s.Require().Equal(expectedBalance, respData.Balance)
 	})
 
 	s.Run("withdraw funds different currency normal case", func() {
@@ -187,7 +254,7 @@ func (s *IntegrationTestSuite) TestWithdraw() {
 		reqDeposit := models.FundsOperations{}
 		reqDeposit.TransactionKey = uuid.New()
 		reqDeposit.Currency = "RUB"
-		reqDeposit.Amount = 1000
+		reqDeposit.Amount = money.MustParse("1000")
 
 		walletIdEndpoint := respData.WalletID.String()
 		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, nil)
@@ -195,14 +262,17 @@ func (s *IntegrationTestSuite) TestWithdraw() {
 		reqWithdraw := models.FundsOperations{}
 		reqWithdraw.TransactionKey = uuid.New()
 		reqWithdraw.Currency = "USD"
-		reqWithdraw.Amount = 1
+		reqWithdraw.Amount = money.MustParse("1")
 
 		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+withdraw, reqWithdraw, &respData)
 
-		convertedFunds, _ := s.walletService.ConvertCurrency(ctx, reqWithdraw.Currency, reqDeposit.Currency, reqWithdraw.Amount)
+		convertedFunds, _ := s.walletService.ConvertCurrency(ctx, models.SideAsk, reqWithdraw.Currency,
+			reqDeposit.Currency, reqWithdraw.Amount)
 
 		s.Require().Equal(http.StatusOK, resp.StatusCode)
-		s.Require().Equal(reqDeposit.Amount-convertedFunds, respData.Balance)
+		expectedBalance, err := reqDeposit.Amount.Sub(convertedFunds)
+		s.Require().NoError(err)
+		s.Require().Equal(expectedBalance, respData.Balance)
 	})
 
 	s.Run("withdraw funds non-idempotent request", func() {
@@ -221,7 +291,7 @@ func (s *IntegrationTestSuite) TestWithdraw() {
 		reqDeposit := models.FundsOperations{}
 		reqDeposit.TransactionKey = uuid.New()
 		reqDeposit.Currency = "RUB"
-		reqDeposit.Amount = 1000
+		reqDeposit.Amount = money.MustParse("1000")
 
 		walletIdEndpoint := respData.WalletID.String()
 		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, nil)
@@ -229,7 +299,7 @@ func (s *IntegrationTestSuite) TestWithdraw() {
 		reqWithdraw := models.FundsOperations{}
 		reqWithdraw.TransactionKey = reqDeposit.TransactionKey
 		reqWithdraw.Currency = "RUB"
-		reqWithdraw.Amount = 800
+		reqWithdraw.Amount = money.MustParse("800")
 
 		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+withdraw, reqWithdraw, nil)
 
@@ -252,7 +322,7 @@ func (s *IntegrationTestSuite) TestWithdraw() {
 		reqDeposit := models.FundsOperations{}
 		reqDeposit.TransactionKey = uuid.New()
 		reqDeposit.Currency = "RUB"
-		reqDeposit.Amount = 1000
+		reqDeposit.Amount = money.MustParse("1000")
 
 		walletIdEndpoint := respData.WalletID.String()
 		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, nil)
@@ -260,7 +330,7 @@ func (s *IntegrationTestSuite) TestWithdraw() {
 		reqWithdraw := models.FundsOperations{}
 		reqWithdraw.TransactionKey = uuid.New()
 		reqWithdraw.Currency = "RUB"
-		reqWithdraw.Amount = 1200
+		reqWithdraw.Amount = money.MustParse("1200")
 
 		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+withdraw, reqWithdraw, nil)
 
@@ -283,7 +353,7 @@ func (s *IntegrationTestSuite) TestWithdraw() {
 		reqDeposit := models.FundsOperations{}
 		reqDeposit.TransactionKey = uuid.New()
 		reqDeposit.Currency = "RUB"
-		reqDeposit.Amount = 1000
+		reqDeposit.Amount = money.MustParse("1000")
 
 		walletIdEndpoint := respData.WalletID.String()
 		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, &respData)
@@ -291,7 +361,7 @@ func (s *IntegrationTestSuite) TestWithdraw() {
 		reqWithdraw := models.FundsOperations{}
 		reqWithdraw.TransactionKey = uuid.New()
 		reqWithdraw.Currency = "XYZ"
-		reqWithdraw.Amount = 200
+		reqWithdraw.Amount = money.MustParse("200")
 
 		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+withdraw, reqWithdraw, nil)
 
@@ -316,7 +386,7 @@ func (s *IntegrationTestSuite) TestTransfer() {
 		reqDeposit := models.FundsOperations{}
 		reqDeposit.TransactionKey = uuid.New()
 		reqDeposit.Currency = "RUB"
-		reqDeposit.Amount = 10000
+		reqDeposit.Amount = money.MustParse("10000")
 
 		srcWalletIdEndpoint := respData.WalletID.String()
 		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+srcWalletIdEndpoint+deposit, reqDeposit, nil)
@@ -332,24 +402,34 @@ func (s *IntegrationTestSuite) TestTransfer() {
 		reqTransfer := models.FundsOperations{}
 		reqTransfer.TransactionKey = uuid.New()
 		reqTransfer.Currency = "RUB"
-		reqTransfer.Amount = 9999
+		reqTransfer.Amount = money.MustParse("9999")
+
+		var respTransfer models.ResponseTransfer
 
 		dstWalletEndpoint := respData.WalletID.String()
 		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+srcWalletIdEndpoint+transfer+dstWalletEndpoint,
-			reqTransfer, &respData)
+			reqTransfer, &respTransfer)
 
-		convertedFunds, _ := s.walletService.ConvertCurrency(ctx, reqSrcWallet.Currency, reqDstWallet.Currency,
-			reqTransfer.Amount)
+		convertedFunds, _ := s.walletService.ConvertCurrency(ctx, models.SideBid, reqSrcWallet.Currency,
+			reqDstWallet.Currency, reqTransfer.Amount)
+
+		expectedBalance, err := reqDeposit.Amount.Sub(reqTransfer.Amount)
+		s.Require().NoError(err)
 
 		s.Require().Equal(http.StatusOK, resp.StatusCode)
-		s.Require().Equal(convertedFunds, respData.Balance)
+		s.Require().Equal(reqTransfer.Amount, respTransfer.DebitedAmount)
+		s.Require().Equal(convertedFunds, respTransfer.CreditedAmount)
+		s.Require().Equal(expectedBalance, respTransfer.SrcWallet.Balance)
+		s.Require().Equal(convertedFunds, respTransfer.DstWallet.Balance)
+		s.Require().Nil(respTransfer.SrcRate)
+		s.Require().NotNil(respTransfer.DstRate)
 
 		_ = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+srcWalletIdEndpoint, nil, &respData)
 
-		s.Require().Equal(reqDeposit.Amount-reqTransfer.Amount, respData.Balance)
+		s.Require().Equal(expectedBalance, respData.Balance)
 	})
 
-	s.Run("transfer funds non-idempotent request", func() {
+	s.Run("transfer funds replayed request", func() {
 		ctx := context.Background()
 
 		reqSrcWallet := models.RequestWalletInstance{}
@@ -365,7 +445,7 @@ func (s *IntegrationTestSuite) TestTransfer() {
 		reqDeposit := models.FundsOperations{}
 		reqDeposit.TransactionKey = uuid.New()
 		reqDeposit.Currency = "EUR"
-		reqDeposit.Amount = 10000
+		reqDeposit.Amount = money.MustParse("10000")
 
 		srcWalletIdEndpoint := respData.WalletID.String()
 		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+srcWalletIdEndpoint+deposit, reqDeposit, nil)
@@ -381,20 +461,33 @@ func (s *IntegrationTestSuite) TestTransfer() {
 		reqTransfer := models.FundsOperations{}
 		reqTransfer.TransactionKey = uuid.New()
 		reqTransfer.Currency = "EUR"
-		reqTransfer.Amount = 3000
+		reqTransfer.Amount = money.MustParse("3000")
+
+		var firstResp, replayedResp models.ResponseTransfer
 
 		dstWalletEndpoint := respData.WalletID.String()
 		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+srcWalletIdEndpoint+transfer+dstWalletEndpoint,
-			reqTransfer, nil)
+			reqTransfer, &firstResp)
 
 		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+srcWalletIdEndpoint+transfer+dstWalletEndpoint,
-			reqTransfer, nil)
+			reqTransfer, &replayedResp)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(firstResp, replayedResp)
+
+		reqReusedKey := reqTransfer
+		reqReusedKey.Amount = money.MustParse("100")
+
+		resp = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+srcWalletIdEndpoint+transfer+dstWalletEndpoint,
+			reqReusedKey, nil)
 
 		s.Require().Equal(http.StatusConflict, resp.StatusCode)
 
 		_ = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+srcWalletIdEndpoint, nil, &respData)
 
-		s.Require().Equal(reqDeposit.Amount-reqTransfer.Amount, respData.Balance)
+		expectedBalance, err := reqDeposit.Amount.Sub(reqTransfer.Amount)
+		s.Require().NoError(err)
+		s.Require().Equal(expectedBalance, respData.Balance)
 
 		_ = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+dstWalletEndpoint, nil, &respData)
 
@@ -417,7 +510,7 @@ func (s *IntegrationTestSuite) TestTransfer() {
 		reqDeposit := models.FundsOperations{}
 		reqDeposit.TransactionKey = uuid.New()
 		reqDeposit.Currency = "RUB"
-		reqDeposit.Amount = 10000
+		reqDeposit.Amount = money.MustParse("10000")
 
 		srcWalletIdEndpoint := respData.WalletID.String()
 		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+srcWalletIdEndpoint+deposit, reqDeposit, nil)
@@ -425,7 +518,7 @@ func (s *IntegrationTestSuite) TestTransfer() {
 		reqTransfer := models.FundsOperations{}
 		reqTransfer.TransactionKey = uuid.New()
 		reqTransfer.Currency = "RUB"
-		reqTransfer.Amount = 9999
+		reqTransfer.Amount = money.MustParse("9999")
 
 		dstWalletEndpoint := uuid.New().String()
 		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+srcWalletIdEndpoint+transfer+dstWalletEndpoint,
@@ -437,3 +530,283 @@ func (s *IntegrationTestSuite) TestTransfer() {
 		s.Require().Equal(reqDeposit.Amount, respData.Balance)
 	})
 }
+
+func (s *IntegrationTestSuite) TestLedger() {
+	s.Run("ledger balance matches wallet balance", func() {
+		ctx := context.Background()
+
+		reqSrcWallet := models.RequestWalletInstance{}
+		reqSrcWallet.TransactionKey = uuid.New()
+		reqSrcWallet.Email = uuid.New().String()
+		reqSrcWallet.Owner = "Alex"
+		reqSrcWallet.Currency = "RUB"
+
+		var srcWallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqSrcWallet, &srcWallet)
+
+		reqDstWallet := models.RequestWalletInstance{}
+		reqDstWallet.TransactionKey = uuid.New()
+		reqDstWallet.Email = uuid.New().String()
+		reqDstWallet.Owner = "Kate"
+		reqDstWallet.Currency = "USD"
+
+		var dstWallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqDstWallet, &dstWallet)
+
+		srcWalletIdEndpoint := srcWallet.WalletID.String()
+		dstWalletIdEndpoint := dstWallet.WalletID.String()
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "EUR"
+		reqDeposit.Amount = money.MustParse("1000.55")
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+srcWalletIdEndpoint+deposit, reqDeposit, nil)
+
+		reqWithdraw := models.FundsOperations{}
+		reqWithdraw.TransactionKey = uuid.New()
+		reqWithdraw.Currency = "RUB"
+		reqWithdraw.Amount = money.MustParse("150.10")
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+srcWalletIdEndpoint+withdraw, reqWithdraw, nil)
+
+		reqTransfer := models.FundsOperations{}
+		reqTransfer.TransactionKey = uuid.New()
+		reqTransfer.Currency = "RUB"
+		reqTransfer.Amount = money.MustParse("5000")
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+srcWalletIdEndpoint+transfer+dstWalletIdEndpoint,
+			reqTransfer, nil)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		for _, id := range []string{srcWalletIdEndpoint, dstWalletIdEndpoint} {
+			var respData models.ResponseWalletInstance
+
+			_ = s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+id, nil, &respData)
+
+			ledgerBalance, err := s.pg.GetLedgerBalance(ctx, id)
+			s.Require().NoError(err)
+			s.Require().Equal(respData.Balance, ledgerBalance)
+		}
+	})
+}
+
+func (s *IntegrationTestSuite) TestTransactions() {
+	s.Run("get transaction normal case", func() {
+		ctx := context.Background()
+
+		reqSrcWallet := models.RequestWalletInstance{}
+		reqSrcWallet.TransactionKey = uuid.New()
+		reqSrcWallet.Email = uuid.New().String()
+		reqSrcWallet.Owner = "Alex"
+		reqSrcWallet.Currency = "RUB"
+
+		var srcWallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqSrcWallet, &srcWallet)
+
+		reqDstWallet := models.RequestWalletInstance{}
+		reqDstWallet.TransactionKey = uuid.New()
+		reqDstWallet.Email = uuid.New().String()
+		reqDstWallet.Owner = "Kate"
+		reqDstWallet.Currency = "USD"
+
+		var dstWallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqDstWallet, &dstWallet)
+
+		srcWalletIdEndpoint := srcWallet.WalletID.String()
+		dstWalletIdEndpoint := dstWallet.WalletID.String()
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "RUB"
+		reqDeposit.Amount = money.MustParse("10000")
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+srcWalletIdEndpoint+deposit, reqDeposit, nil)
+
+		reqTransfer := models.FundsOperations{}
+		reqTransfer.TransactionKey = uuid.New()
+		reqTransfer.Currency = "RUB"
+		reqTransfer.Amount = money.MustParse("5000")
+
+		var respTransfer models.ResponseTransfer
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+srcWalletIdEndpoint+transfer+dstWalletIdEndpoint,
+			reqTransfer, &respTransfer)
+
+		s.Require().NotEqual(uuid.Nil, respTransfer.TransactionID)
+
+		var respData models.Transaction
+
+		resp := s.sendRequest(ctx, http.MethodGet, url+transactionsEndpoint+respTransfer.TransactionID.String(), nil,
+			&respData)
+
+		convertedFunds, _ := s.walletService.ConvertCurrency(ctx, models.SideBid, reqTransfer.Currency, reqDstWallet.Currency,
+			reqTransfer.Amount)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(respTransfer.TransactionID, respData.TransactionID)
+		s.Require().Equal("TRANSFER", respData.OperationType)
+		s.Require().Equal(reqTransfer.Amount, respData.Amount)
+		s.Require().Equal(reqTransfer.TransactionKey, respData.TransactionKey.UUID)
+		s.Require().Equal(models.TransactionCompleted, respData.Status)
+		s.Require().NotNil(respData.Source)
+		s.Require().Equal(srcWallet.WalletID, respData.Source.WalletID)
+		s.Require().Equal(reqTransfer.Amount, respData.Source.Amount)
+		s.Require().Nil(respData.Source.AppliedRate)
+		s.Require().NotNil(respData.Destination)
+		s.Require().Equal(dstWallet.WalletID, respData.Destination.WalletID)
+		s.Require().Equal(convertedFunds, respData.Destination.Amount)
+		s.Require().NotNil(respData.Destination.AppliedRate)
+		s.Require().Equal(models.SideBid, respData.Destination.Side)
+		s.Require().Equal(int64(spreadBps), respData.Destination.SpreadBps)
+	})
+
+	s.Run("transfer funds records applied sides", func() {
+		ctx := context.Background()
+
+		reqSrcWallet := models.RequestWalletInstance{}
+		reqSrcWallet.TransactionKey = uuid.New()
+		reqSrcWallet.Email = uuid.New().String()
+		reqSrcWallet.Owner = "Alex"
+		reqSrcWallet.Currency = "RUB"
+
+		var srcWallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqSrcWallet, &srcWallet)
+
+		reqDstWallet := models.RequestWalletInstance{}
+		reqDstWallet.TransactionKey = uuid.New()
+		reqDstWallet.Email = uuid.New().String()
+		reqDstWallet.Owner = "Kate"
+		reqDstWallet.Currency = "EUR"
+
+		var dstWallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, reqDstWallet, &dstWallet)
+
+		srcWalletIdEndpoint := srcWallet.WalletID.String()
+		dstWalletIdEndpoint := dstWallet.WalletID.String()
+
+		reqDeposit := models.FundsOperations{}
+		reqDeposit.TransactionKey = uuid.New()
+		reqDeposit.Currency = "RUB"
+		reqDeposit.Amount = money.MustParse("100000")
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+srcWalletIdEndpoint+deposit, reqDeposit, nil)
+
+		reqTransfer := models.FundsOperations{}
+		reqTransfer.TransactionKey = uuid.New()
+		reqTransfer.Currency = "USD"
+		reqTransfer.Amount = money.MustParse("100")
+
+		var respTransfer models.ResponseTransfer
+
+		resp := s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+srcWalletIdEndpoint+transfer+dstWalletIdEndpoint,
+			reqTransfer, &respTransfer)
+
+		debitedFunds, _ := s.walletService.ConvertCurrency(ctx, models.SideAsk, reqTransfer.Currency,
+			reqSrcWallet.Currency, reqTransfer.Amount)
+		creditedFunds, _ := s.walletService.ConvertCurrency(ctx, models.SideBid, reqTransfer.Currency,
+			reqDstWallet.Currency, reqTransfer.Amount)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(debitedFunds, respTransfer.DebitedAmount)
+		s.Require().Equal(creditedFunds, respTransfer.CreditedAmount)
+		s.Require().NotNil(respTransfer.SrcRate)
+		s.Require().Equal(models.SideAsk, respTransfer.SrcRate.Side)
+		s.Require().NotNil(respTransfer.DstRate)
+		s.Require().Equal(models.SideBid, respTransfer.DstRate.Side)
+		s.Require().Equal(int64(spreadBps), respTransfer.DstRate.SpreadBps)
+	})
+
+	s.Run("get transaction not found", func() {
+		ctx := context.Background()
+
+		resp := s.sendRequest(ctx, http.MethodGet, url+transactionsEndpoint+uuid.New().String(), nil, nil)
+
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+	})
+
+	s.Run("get transaction invalid ID", func() {
+		ctx := context.Background()
+
+		resp := s.sendRequest(ctx, http.MethodGet, url+transactionsEndpoint+"invalid", nil, nil)
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+	})
+
+	s.Run("get wallet transactions with filter and pagination", func() {
+		ctx := context.Background()
+
+		req := models.RequestWalletInstance{}
+		req.TransactionKey = uuid.New()
+		req.Email = uuid.New().String()
+		req.Owner = "Kate"
+		req.Currency = "EUR"
+
+		var respWallet models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, req, &respWallet)
+
+		walletIdEndpoint := respWallet.WalletID.String()
+
+		for _, amount := range []string{"100", "200", "300"} {
+			reqDeposit := models.FundsOperations{}
+			reqDeposit.TransactionKey = uuid.New()
+			reqDeposit.Currency = "EUR"
+			reqDeposit.Amount = money.MustParse(amount)
+
+			_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, nil)
+		}
+
+		reqWithdraw := models.FundsOperations{}
+		reqWithdraw.TransactionKey = uuid.New()
+		reqWithdraw.Currency = "EUR"
+		reqWithdraw.Amount = money.MustParse("50")
+
+		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+withdraw, reqWithdraw, nil)
+
+		var respData []models.Transaction
+
+		resp := s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+walletIdEndpoint+walletTransactions, nil,
+			&respData)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Len(respData, 4)
+
+		resp = s.sendRequest(ctx, http.MethodGet,
+			url+walletEndpoint+walletIdEndpoint+walletTransactions+
+				"?type=DEPOSIT&itemsPerPage=2&offset=1&sorting=amount&descending=true", nil, &respData)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Len(respData, 2)
+		s.Require().Equal(money.MustParse("200"), respData[0].Amount)
+		s.Require().Equal(money.MustParse("100"), respData[1].Amount)
+
+		for _, transaction := range respData {
+			s.Require().Equal("DEPOSIT", transaction.OperationType)
+			s.Require().Nil(transaction.Source)
+			s.Require().Equal(respWallet.WalletID, transaction.Destination.WalletID)
+		}
+
+		resp = s.sendRequest(ctx, http.MethodGet,
+			url+walletEndpoint+walletIdEndpoint+walletTransactions+"?type=WITHDRAWAL", nil, &respData)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Len(respData, 1)
+		s.Require().Equal(reqWithdraw.Amount, respData[0].Amount)
+	})
+
+	s.Run("get wallet transactions wallet not found", func() {
+		ctx := context.Background()
+
+		resp := s.sendRequest(ctx, http.MethodGet, url+walletEndpoint+uuid.New().String()+walletTransactions, nil, nil)
+
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+	})
+}
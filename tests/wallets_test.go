@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/AlexZav1327/service/internal/models"
+	"github.com/AlexZav1327/service/internal/money"
 	"github.com/google/uuid"
 )
 
@@ -19,7 +20,7 @@ func (s *IntegrationTestSuite) TestWalletCRUD() {
 		req.Email = uuid.New().String()
 		req.Owner = "Kate"
 		req.Currency = "EUR"
-		req.Balance = 350
+		req.Balance = money.MustParse("350")
 
 		var respData models.ResponseWalletInstance
 
@@ -28,7 +29,7 @@ func (s *IntegrationTestSuite) TestWalletCRUD() {
 		s.Require().Equal(http.StatusCreated, resp.StatusCode)
 		s.Require().Equal(req.Owner, respData.Owner)
 		s.Require().Equal(req.Currency, respData.Currency)
-		s.Require().Equal(float32(0), respData.Balance)
+		s.Require().Equal(money.Amount{}, respData.Balance)
 	})
 
 	s.Run("create wallet not valid currency", func() {
@@ -45,6 +46,24 @@ func (s *IntegrationTestSuite) TestWalletCRUD() {
 		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
 	})
 
+	s.Run("create wallet replayed request", func() {
+		ctx := context.Background()
+
+		req := models.RequestWalletInstance{}
+		req.TransactionKey = uuid.New()
+		req.Email = uuid.New().String()
+		req.Owner = "Alex"
+		req.Currency = "USD"
+
+		var firstResp, replayedResp models.ResponseWalletInstance
+
+		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, req, &firstResp)
+		resp := s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, req, &replayedResp)
+
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+		s.Require().Equal(firstResp, replayedResp)
+	})
+
 	s.Run("create wallet non-idempotent request", func() {
 		ctx := context.Background()
 
@@ -55,6 +74,8 @@ func (s *IntegrationTestSuite) TestWalletCRUD() {
 		req.Currency = "USD"
 
 		_ = s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, req, nil)
+
+		req.Owner = "Kate"
 		resp := s.sendRequest(ctx, http.MethodPost, url+createWalletEndpoint, req, nil)
 
 		s.Require().Equal(http.StatusConflict, resp.StatusCode)
@@ -106,7 +127,7 @@ func (s *IntegrationTestSuite) TestWalletCRUD() {
 		reqDeposit := models.FundsOperations{}
 		reqDeposit.TransactionKey = uuid.New()
 		reqDeposit.Currency = "EUR"
-		reqDeposit.Amount = 100
+		reqDeposit.Amount = money.MustParse("100")
 
 		walletIdEndpoint := respData.WalletID.String()
 		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, &respData)
@@ -117,7 +138,8 @@ func (s *IntegrationTestSuite) TestWalletCRUD() {
 
 		resp := s.sendRequest(ctx, http.MethodPatch, url+updateWalletEndpoint+walletIdEndpoint, reqUpdate, &respData)
 
-		convertedFunds, _ := s.walletService.ConvertCurrency(ctx, req.Currency, reqUpdate.Currency, reqDeposit.Amount)
+		convertedFunds, _ := s.walletService.ConvertCurrency(ctx, models.SideBid, req.Currency, reqUpdate.Currency,
+			reqDeposit.Amount)
 
 		s.Require().Equal(http.StatusOK, resp.StatusCode)
 		s.Require().Equal(reqUpdate.Owner, respData.Owner)
@@ -223,7 +245,7 @@ func (s *IntegrationTestSuite) TestWalletsList() {
 		reqDeposit := models.FundsOperations{}
 		reqDeposit.TransactionKey = uuid.New()
 		reqDeposit.Currency = "USD"
-		reqDeposit.Amount = 100
+		reqDeposit.Amount = money.MustParse("100")
 
 		walletIdEndpoint := respData.WalletID.String()
 		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, nil)
@@ -253,7 +275,7 @@ func (s *IntegrationTestSuite) TestWalletsList() {
 		resp = s.sendRequest(ctx, http.MethodGet, url+walletsEndpoint+queryParams, nil, &respDataList)
 
 		s.Require().Equal(http.StatusOK, resp.StatusCode)
-		s.Require().Equal(float32(100), respDataList[0].Balance)
+		s.Require().Equal(money.MustParse("100"), respDataList[0].Balance)
 
 		queryParams = "?itemsPerPage=2"
 		resp = s.sendRequest(ctx, http.MethodGet, url+walletsEndpoint+queryParams, nil, &respDataList)
@@ -287,7 +309,7 @@ func (s *IntegrationTestSuite) TestWalletHistory() {
 		reqDeposit.TransactionKey = uuid.New()
 		req.Email = uuid.New().String()
 		reqDeposit.Currency = "USD"
-		reqDeposit.Amount = 1000
+		reqDeposit.Amount = money.MustParse("1000")
 
 		walletIdEndpoint := respData.WalletID.String()
 		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, nil)
@@ -296,7 +318,7 @@ func (s *IntegrationTestSuite) TestWalletHistory() {
 		reqWithdraw.TransactionKey = uuid.New()
 		req.Email = uuid.New().String()
 		reqWithdraw.Currency = "USD"
-		reqWithdraw.Amount = 150
+		reqWithdraw.Amount = money.MustParse("150")
 
 		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+withdraw, reqWithdraw, nil)
 
@@ -306,9 +328,21 @@ func (s *IntegrationTestSuite) TestWalletHistory() {
 
 		_ = s.sendRequest(ctx, http.MethodPatch, url+updateWalletEndpoint+walletIdEndpoint, reqUpdate, nil)
 
-		_ = s.sendRequest(ctx, http.MethodDelete, url+deleteWalletEndpoint+walletIdEndpoint, nil, nil)
+		reqTarget := models.RequestWalletInstance{}
+		reqTarget.TransactionKey = uuid.New()
+		reqTarget.Email = uuid.New().String()
+		reqTarget.Owner = "Alex"
+		reqTarget.Currency = "EUR"
 
-		claimUUID := respData.WalletID.String()
+		var respTarget models.ResponseWalletInstance
+
+		_ = s.sendRequestWithCustomClaims(ctx, http.MethodPost, url+createWalletEndpoint, uuid.New().String(), "",
+			reqTarget, &respTarget)
+
+		_ = s.sendRequest(ctx, http.MethodDelete,
+			url+deleteWalletEndpoint+walletIdEndpoint+"?sweepTo="+respTarget.WalletID.String(), nil, nil)
+
+		claimUUID := s.subject
 		claimEmail := "go-dev@mail.go"
 
 		var respDataHistory []models.ResponseWalletHistory
@@ -321,14 +355,14 @@ func (s *IntegrationTestSuite) TestWalletHistory() {
 			&respDataHistory)
 
 		s.Require().Equal(http.StatusOK, resp.StatusCode)
-		s.Require().Equal(5, len(respDataHistory))
+		s.Require().Equal(6, len(respDataHistory))
 
 		queryParams = "?textFilter=Noname"
 		resp = s.sendRequestWithCustomClaims(ctx, http.MethodGet, url+walletHistoryEndpoint+queryParams, claimUUID, claimEmail, nil,
 			&respDataHistory)
 
 		s.Require().Equal(http.StatusOK, resp.StatusCode)
-		s.Require().Equal(2, len(respDataHistory))
+		s.Require().Equal(3, len(respDataHistory))
 		s.Require().Equal("Noname", respDataHistory[0].Owner)
 
 		queryParams = "?sorting=balance&descending=true"
@@ -336,7 +370,7 @@ func (s *IntegrationTestSuite) TestWalletHistory() {
 			&respDataHistory)
 
 		s.Require().Equal(http.StatusOK, resp.StatusCode)
-		s.Require().Equal(float32(1000), respDataHistory[0].Balance)
+		s.Require().Equal(money.MustParse("1000"), respDataHistory[0].Balance)
 
 		queryParams = "?itemsPerPage=3"
 		resp = s.sendRequestWithCustomClaims(ctx, http.MethodGet, url+walletHistoryEndpoint+queryParams, claimUUID, claimEmail, nil,
@@ -350,7 +384,7 @@ func (s *IntegrationTestSuite) TestWalletHistory() {
 			&respDataHistory)
 
 		s.Require().Equal(http.StatusOK, resp.StatusCode)
-		s.Require().Equal(3, len(respDataHistory))
+		s.Require().Equal(4, len(respDataHistory))
 	})
 
 	s.Run("get wallet history non-active period", func() {
@@ -369,12 +403,12 @@ func (s *IntegrationTestSuite) TestWalletHistory() {
 		reqDeposit := models.FundsOperations{}
 		reqDeposit.TransactionKey = uuid.New()
 		reqDeposit.Currency = "USD"
-		reqDeposit.Amount = 1000
+		reqDeposit.Amount = money.MustParse("1000")
 
 		walletIdEndpoint := respData.WalletID.String()
 		_ = s.sendRequest(ctx, http.MethodPut, url+walletEndpoint+walletIdEndpoint+deposit, reqDeposit, nil)
 
-		claimUUID := respData.WalletID.String()
+		claimUUID := s.subject
 		claimEmail := "go-dev@email.go"
 
 		var respDataHistory []models.ResponseWalletHistory